@@ -1,6 +1,9 @@
 import { useState, useRef } from 'react';
 import { useWebSocket } from './hooks/useWebSocket';
-import { ConnectionState } from './types';
+import { ConnectionState, LOG_LEVELS, LogEntry } from './types';
+
+// Entries that reach the server later than this after they were written show their lag
+const LAG_THRESHOLD_MS = 2000;
 
 function App() {
   const {
@@ -13,6 +16,8 @@ function App() {
   
   const [isPaused, setIsPaused] = useState(false);
   const [selectedLogLevels, setSelectedLogLevels] = useState<Set<string>>(new Set(['all']));
+  const [selectedSource, setSelectedSource] = useState('all');
+  const [searchText, setSearchText] = useState('');
   const logContainerRef = useRef<HTMLDivElement>(null);
 
   const handlePauseResume = () => {
@@ -93,17 +98,47 @@ function App() {
     });
   };
 
+  // Sources seen so far, for the source filter
+  const sources = Array.from(new Set(logs.map(log => log.source).filter((source): source is string => !!source))).sort();
+
+  // Search matches the message, source, host and structured fields
+  const matchesSearch = (log: LogEntry, search: string) => {
+    const haystack = [
+      log.message,
+      log.source ?? '',
+      log.host ?? '',
+      log.fields ? JSON.stringify(log.fields) : ''
+    ].join('\n').toLowerCase();
+    return haystack.includes(search);
+  };
+
   const getFilteredLogs = () => {
-    if (selectedLogLevels.has('all')) {
-      return logs;
-    }
-    return logs.filter(log => selectedLogLevels.has(log.level));
+    const search = searchText.trim().toLowerCase();
+    return logs.filter(log =>
+      (selectedLogLevels.has('all') || selectedLogLevels.has(log.level)) &&
+      (selectedSource === 'all' || log.source === selectedSource) &&
+      (search === '' || matchesSearch(log, search))
+    );
+  };
+
+  const formatFields = (fields: Record<string, unknown>) => {
+    return Object.entries(fields)
+      .map(([key, value]) => `${key}=${typeof value === 'string' ? value : JSON.stringify(value)}`)
+      .join(' ');
   };
 
   const formatTimestamp = (timestamp: string) => {
     return new Date(timestamp).toLocaleTimeString();
   };
 
+  // Shipping lag: how much later the server received the entry than it was written
+  const formatLag = (log: LogEntry) => {
+    if (!log.receivedAt) return null;
+    const lag = new Date(log.receivedAt).getTime() - new Date(log.timestamp).getTime();
+    if (lag < LAG_THRESHOLD_MS) return null;
+    return lag < 60000 ? `+${(lag / 1000).toFixed(1)}s` : `+${Math.round(lag / 60000)}m`;
+  };
+
   const getLogLevelClass = (level: string) => {
     return level.toLowerCase();
   };
@@ -137,6 +172,11 @@ function App() {
             Retry {connectionStatus.reconnectAttempts}/{10}
           </span>
         )}
+        {(connectionStatus.missedLogs ?? 0) > 0 && (
+          <span style={{ marginLeft: '10px', color: '#ffaa00' }}>
+            Missed {connectionStatus.missedLogs} logs while reconnecting
+          </span>
+        )}
       </div>
 
       {/* Control Buttons */}
@@ -161,7 +201,7 @@ function App() {
       {/* Log Level Filter */}
       <div className="log-filter">
         <span className="filter-label">Filter Logs:</span>
-        {['all', 'INFO', 'WARN', 'ERROR'].map(level => (
+        {['all', ...LOG_LEVELS].map(level => (
           <button
             key={`${level}-${Array.from(selectedLogLevels).join('-')}`}
             className={`filter-btn ${selectedLogLevels.has(level) ? 'active' : ''}`}
@@ -176,6 +216,23 @@ function App() {
             {level === 'all' ? '📋 All' : level}
           </button>
         ))}
+        <select
+          className="filter-select"
+          value={selectedSource}
+          onChange={event => setSelectedSource(event.target.value)}
+        >
+          <option value="all">All sources</option>
+          {sources.map(source => (
+            <option key={source} value={source}>{source}</option>
+          ))}
+        </select>
+        <input
+          className="filter-search"
+          type="search"
+          placeholder="Search message, source, host, fields..."
+          value={searchText}
+          onChange={event => setSearchText(event.target.value)}
+        />
         <span className="filter-count">
           Showing {getFilteredLogs().length} of {logs.length} logs
         </span>
@@ -202,13 +259,26 @@ function App() {
           </div>
         ) : (
           <>
-            {getFilteredLogs().slice().reverse().map((log, index) => (
-              <div key={`${log.timestamp}-${index}`} className={`log-entry ${getLogLevelClass(log.level)}`}>
+            {getFilteredLogs().slice().reverse().map(log => (
+              <div key={log.id} className={`log-entry ${getLogLevelClass(log.level)}`}>
                 <span className="timestamp">{formatTimestamp(log.timestamp)}</span>
-                <span className={`level ${getLogLevelClass(log.level)}`}>
+                {formatLag(log) && (
+                  <span className="lag" title={`Received ${new Date(log.receivedAt!).toLocaleString()}`}>
+                    {formatLag(log)}
+                  </span>
+                )}
+                <span className={`level ${getLogLevelClass(log.level)}`} title={log.rawLevel}>
                   {log.level}
                 </span>
+                {(log.source || log.host) && (
+                  <span className="origin">
+                    {[log.host, log.source].filter(Boolean).join(' / ')}
+                  </span>
+                )}
                 <span className="message">{log.message}</span>
+                {log.fields && Object.keys(log.fields).length > 0 && (
+                  <span className="fields">{formatFields(log.fields)}</span>
+                )}
               </div>
             ))}
             <div style={{ textAlign: 'center', color: '#666', marginTop: '20px', fontSize: '12px' }}>
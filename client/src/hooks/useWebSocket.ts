@@ -6,10 +6,18 @@ import {
   WebSocketConfig,
   DEFAULT_WS_CONFIG,
   WebSocketMessage,
+  PROTOCOL_VERSIONS,
   isValidWebSocketMessage,
-  isValidLogEntry
+  isValidLogEntry,
+  isValidWelcome,
+  isValidLive,
+  isValidGap,
+  isValidError
 } from '../types';
 
+// Most logs kept in memory, and asked for as history after connecting
+const MAX_LOGS = 1000;
+
 interface UseWebSocketReturn {
   logs: LogEntry[];
   isConnected: boolean;
@@ -35,6 +43,10 @@ export function useWebSocket(config: Partial<WebSocketConfig> = {}): UseWebSocke
   const reconnectTimeoutRef = useRef<number | null>(null);
   const heartbeatIntervalRef = useRef<number | null>(null);
   const wsConfigRef = useRef(wsConfig); // Store config in ref to prevent recreation
+  const requestIdRef = useRef(0); // Last request ID, copied by the server into its answers
+  const lastIdRef = useRef(0); // ID of the newest log received, to resume from after reconnecting
+  const runRef = useRef<string | null>(null); // Server run the log IDs belong to
+  const historyRequestRef = useRef<string | null>(null); // ID of the pending history request
   
   // Update config ref when config changes
   useEffect(() => {
@@ -49,16 +61,31 @@ export function useWebSocket(config: Partial<WebSocketConfig> = {}): UseWebSocke
     setLogs([]);
   }, []);
   
-  // Add log function
+  // Add log function; logs are kept in ID order, so history sent after a
+  // reconnect is merged without duplicates
   const addLog = useCallback((log: LogEntry) => {
+    lastIdRef.current = Math.max(lastIdRef.current, log.id);
     setLogs(prev => {
-      const newLogs = [...prev, log];
-      // Keep only last 1000 logs to prevent memory issues
-      return newLogs.slice(-1000);
+      let newLogs: LogEntry[];
+      if (prev.length === 0 || log.id > prev[prev.length - 1].id) {
+        newLogs = [...prev, log];
+      } else if (prev.some(existing => existing.id === log.id)) {
+        return prev;
+      } else {
+        newLogs = [...prev, log].sort((a, b) => a.id - b.id);
+      }
+      // Keep only the last logs to prevent memory issues
+      return newLogs.slice(-MAX_LOGS);
     });
   }, []);
   
-  // Send message function
+  // Next request ID for an outgoing message
+  const nextRequestId = () => {
+    requestIdRef.current += 1;
+    return String(requestIdRef.current);
+  };
+
+  // Send message function; messages without a request ID get one
   const sendMessage = useCallback((message: any) => {
     if (!wsRef.current) {
       console.error(`WEBSOCKET: No WebSocket connection available`);
@@ -69,8 +96,9 @@ export function useWebSocket(config: Partial<WebSocketConfig> = {}): UseWebSocke
     
     if (state === WebSocket.OPEN) {
       try {
-        console.log(`WEBSOCKET: Sending message:`, message);
-        wsRef.current.send(JSON.stringify(message));
+        const request = { id: nextRequestId(), ...message };
+        console.log(`WEBSOCKET: Sending message:`, request);
+        wsRef.current.send(JSON.stringify(request));
         console.log(`WEBSOCKET: Message sent successfully`);
       } catch (error) {
         console.error(`WEBSOCKET: Failed to send message:`, error);
@@ -115,9 +143,20 @@ export function useWebSocket(config: Partial<WebSocketConfig> = {}): UseWebSocke
         wsRef.current.close();
       }
       
-      // Create new connection
-      const ws = new WebSocket(wsConfigRef.current.url);
+      // Create new connection, resuming after the newest log received
+      const url = new URL(wsConfigRef.current.url);
+      const resuming = runRef.current !== null && lastIdRef.current > 0;
+      if (resuming) {
+        url.searchParams.set('resume_from', `${runRef.current}:${lastIdRef.current}`);
+      }
+      const ws = new WebSocket(url.toString());
       wsRef.current = ws;
+
+      // Ask for the logs sent before this connection
+      const requestHistory = () => {
+        historyRequestRef.current = nextRequestId();
+        ws.send(JSON.stringify({ type: 'history', id: historyRequestRef.current, data: { limit: MAX_LOGS } }));
+      };
       
       // Connection opened
       ws.onopen = () => {
@@ -128,6 +167,13 @@ export function useWebSocket(config: Partial<WebSocketConfig> = {}): UseWebSocke
           lastConnected: new Date(),
           reconnectAttempts: 0
         }));
+
+        // Announce the protocol versions this client speaks
+        ws.send(JSON.stringify({
+          type: 'hello',
+          id: nextRequestId(),
+          data: { versions: PROTOCOL_VERSIONS, client: 'smart-log-viewer-client' }
+        }));
         
         // Start heartbeat
         heartbeatIntervalRef.current = setInterval(() => {
@@ -169,7 +215,63 @@ export function useWebSocket(config: Partial<WebSocketConfig> = {}): UseWebSocke
           
           const message: WebSocketMessage = rawMessage;
           
-          if (message.type === 'log') {
+          if (message.type === 'welcome') {
+            if (isValidWelcome(message.data)) {
+              const { version, run } = message.data;
+              console.log(`Server speaks protocol version ${version}`);
+              runRef.current = run;
+              setConnectionStatus(prev => ({ ...prev, protocolVersion: version }));
+
+              // Backfill the logs sent before this connection; a resuming
+              // connection is sent the missed ones without asking
+              if (!resuming) {
+                requestHistory();
+              }
+            } else {
+              console.error('Invalid welcome data:', message.data);
+            }
+          } else if (message.type === 'error') {
+            if (isValidError(message.data)) {
+              const { code, message: text } = message.data;
+              console.error(`Server error for request ${message.id ?? '-'}: ${code}: ${text}`);
+              setConnectionStatus(prev => ({ ...prev, lastError: `${code}: ${text}` }));
+
+              // The server could not queue the history, ask again shortly
+              if (code === 'busy' && message.id !== undefined && message.id === historyRequestRef.current) {
+                setTimeout(() => {
+                  if (ws.readyState === WebSocket.OPEN) {
+                    requestHistory();
+                  }
+                }, 1000);
+              }
+            } else {
+              console.error('Invalid error data:', message.data);
+            }
+          } else if (message.type === 'live') {
+            // End of the history, the logs that follow are live
+            if (isValidLive(message.data)) {
+              console.log(`Received ${message.data.count} logs of history for request ${message.id ?? '-'}`);
+            } else {
+              console.error('Invalid live data:', message.data);
+            }
+          } else if (message.type === 'gap') {
+            if (!isValidGap(message.data)) {
+              console.error('Invalid gap data:', message.data);
+            } else if (message.data.reset) {
+              // The server restarted, so the logs kept belong to its earlier run
+              console.warn('Server restarted, discarding logs of its earlier run');
+              lastIdRef.current = 0;
+              runRef.current = null; // Set again by the welcome of the new run
+              setLogs([]);
+            } else if (message.data.from !== undefined && message.data.to !== undefined) {
+              const missed = message.data.to - message.data.from + 1;
+              console.warn(`Missed logs ${message.data.from} to ${message.data.to}, no longer kept by the server`);
+              setConnectionStatus(prev => ({ ...prev, missedLogs: (prev.missedLogs ?? 0) + missed }));
+            }
+          } else if (message.type === 'status') {
+            // Answer to pause or resume
+            console.log(`Server status for request ${message.id ?? '-'}:`, message.data);
+          } else if (message.type === 'log') {
             // Validate log entry data
             if (isValidLogEntry(message.data)) {
               addLog(message.data);
@@ -187,9 +289,6 @@ export function useWebSocket(config: Partial<WebSocketConfig> = {}): UseWebSocke
           } else if (message.type === 'pong') {
             // Heartbeat response, connection is alive
             console.log('Heartbeat received');
-          } else if (message.type === 'pause' || message.type === 'resume') {
-            // Handle pause/resume confirmations from server
-            console.log(`Server confirmed: ${message.type}`);
           } else {
             console.log('Received message:', message.type, message.data);
           }
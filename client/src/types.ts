@@ -1,8 +1,19 @@
+// Canonical severity scale, least severe first, matching the Go server's level package
+export const LOG_LEVELS = ['TRACE', 'DEBUG', 'INFO', 'NOTICE', 'WARN', 'ERROR', 'CRITICAL', 'FATAL'] as const;
+
+export type LogLevel = typeof LOG_LEVELS[number];
+
 // Log entry structure matching the Go server's model.Log
 export interface LogEntry {
-  level: 'INFO' | 'WARN' | 'ERROR';
+  id: number; // Server-assigned sequence number, increasing in broadcast order
+  level: LogLevel;
+  rawLevel?: string; // The level as the producer wrote it, e.g. "warning" or "E"
   message: string;
   timestamp: string; // ISO 8601 timestamp string
+  receivedAt?: string; // When the server received the entry, ISO 8601
+  source?: string; // Source name or a more specific origin such as a file path
+  host?: string; // Machine that produced the entry, when known
+  fields?: Record<string, unknown>; // Structured context extracted by the source
 }
 
 // WebSocket connection states
@@ -13,10 +24,62 @@ export enum ConnectionState {
   ERROR = 'error'
 }
 
-// WebSocket message types with better validation
+// Protocol versions this client speaks, announced in the hello message
+export const PROTOCOL_VERSIONS = [1];
+
+export const MESSAGE_TYPES = ['hello', 'welcome', 'log', 'history', 'live', 'gap', 'status', 'error', 'ping', 'pong', 'pause', 'resume'] as const;
+
+export type MessageType = typeof MESSAGE_TYPES[number];
+
+// WebSocket message envelope, matching the Go server's model.WebSocketMessage
 export interface WebSocketMessage {
-  type: 'log' | 'status' | 'error' | 'ping' | 'pong' | 'pause' | 'resume';
-  data: LogEntry | string | any;
+  type: MessageType;
+  id?: string; // Request ID, copied by the server into its answer
+  data: LogEntry | HelloData | WelcomeData | HistoryData | LiveData | GapData | StatusData | ErrorData | string | null;
+}
+
+// Payload of a hello message, sent by the client after connecting
+export interface HelloData {
+  versions: number[];
+  client?: string;
+}
+
+// Payload of the server's welcome, answering hello
+export interface WelcomeData {
+  version: number; // Negotiated protocol version
+  versions: number[];
+  paused: boolean;
+  run: string; // Run of the server, passed back when resuming after a reconnect
+}
+
+// Payload of a status message, answering pause and resume
+export interface StatusData {
+  paused: boolean;
+}
+
+// Payload of a history message, asking for the logs the server kept
+export interface HistoryData {
+  limit?: number; // At most this many of the newest logs
+  since?: string; // Only logs not older than this RFC 3339 time
+}
+
+// Payload of a live message, sent after the logs of a history request
+export interface LiveData {
+  count: number;
+}
+
+// Payload of a gap message, reporting logs missed while disconnected
+export interface GapData {
+  from?: number; // First and last ID of the missed logs
+  to?: number;
+  reset?: boolean; // The server restarted and its IDs started over
+}
+
+// Payload of an error message
+export interface ErrorData {
+  code: 'invalid_message' | 'unknown_type' | 'invalid_data' | 'busy' | 'unsupported_version' | string;
+  message: string;
+  versions?: number[];
 }
 
 // Validate WebSocket message
@@ -25,20 +88,53 @@ export function isValidWebSocketMessage(message: any): message is WebSocketMessa
     typeof message === 'object' &&
     message !== null &&
     typeof message.type === 'string' &&
-    ['log', 'status', 'error', 'ping', 'pong', 'pause', 'resume'].includes(message.type) &&
+    (MESSAGE_TYPES as readonly string[]).includes(message.type) &&
+    (message.id === undefined || typeof message.id === 'string') &&
     'data' in message
   );
 }
 
+// Validate welcome payload
+export function isValidWelcome(data: any): data is WelcomeData {
+  return typeof data === 'object' && data !== null && typeof data.version === 'number' && typeof data.run === 'string';
+}
+
+// Validate live payload
+export function isValidLive(data: any): data is LiveData {
+  return typeof data === 'object' && data !== null && typeof data.count === 'number';
+}
+
+// Validate gap payload
+export function isValidGap(data: any): data is GapData {
+  return (
+    typeof data === 'object' &&
+    data !== null &&
+    (data.from === undefined || typeof data.from === 'number') &&
+    (data.to === undefined || typeof data.to === 'number') &&
+    (data.reset === undefined || typeof data.reset === 'boolean')
+  );
+}
+
+// Validate error payload
+export function isValidError(data: any): data is ErrorData {
+  return typeof data === 'object' && data !== null && typeof data.code === 'string' && typeof data.message === 'string';
+}
+
 // Validate log entry
 export function isValidLogEntry(data: any): data is LogEntry {
   return (
     typeof data === 'object' &&
     data !== null &&
+    typeof data.id === 'number' &&
     typeof data.level === 'string' &&
-    ['INFO', 'WARN', 'ERROR'].includes(data.level) &&
+    (LOG_LEVELS as readonly string[]).includes(data.level) &&
+    (data.rawLevel === undefined || typeof data.rawLevel === 'string') &&
     typeof data.message === 'string' &&
-    typeof data.timestamp === 'string'
+    typeof data.timestamp === 'string' &&
+    (data.receivedAt === undefined || typeof data.receivedAt === 'string') &&
+    (data.source === undefined || typeof data.source === 'string') &&
+    (data.host === undefined || typeof data.host === 'string') &&
+    (data.fields === undefined || (typeof data.fields === 'object' && data.fields !== null))
   );
 }
 
@@ -48,6 +144,8 @@ export interface ConnectionStatus {
   lastConnected?: Date;
   lastError?: string;
   reconnectAttempts: number;
+  protocolVersion?: number; // Negotiated in the hello/welcome handshake
+  missedLogs?: number; // Logs lost while reconnecting, no longer kept by the server
 }
 
 // WebSocket configuration
@@ -1,75 +1,223 @@
 // Package main is the entry point for the Smart Log Viewer Server.
 // This server provides a WebSocket endpoint for real-time log streaming
-// and generates mock log entries for demonstration purposes.
+// of entries produced by pluggable log sources.
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
-	"smart-log-viewer/server/internal/loggenerator"
-	"smart-log-viewer/server/internal/model"
+	"os"
+	"os/signal"
+	"smart-log-viewer/server/internal/config"
+	"smart-log-viewer/server/internal/ingest"
+	"smart-log-viewer/server/internal/process"
+	"smart-log-viewer/server/internal/source"
+	"smart-log-viewer/server/internal/stdin"
 	"smart-log-viewer/server/internal/websocket"
-	"strconv"
+	"strings"
+	"syscall"
 	"time"
 )
 
+// shutdownFlushDelay is how long the server waits after stopping the sources
+// so that connections can send the logs that were still queued.
+const shutdownFlushDelay = 500 * time.Millisecond
+
 // main is the entry point for the Smart Log Viewer Server application.
-// It initializes the WebSocket connection hub, starts log generation,
-// and sets up HTTP endpoints for WebSocket upgrades and server status.
+// It initializes the WebSocket connection hub, starts the configured
+// log sources, and sets up HTTP endpoints for WebSocket upgrades and server status.
 //
-// The server runs on port 8080 and provides:
+// The server listens on the configured address (default :8080) and provides:
 // - WebSocket endpoint at /ws for real-time log streaming
-// - Status endpoint at / for server health checks
-// - Mock log generation every second for demonstration
+// - Ingest endpoint at /api/ingest for logs pushed over HTTP
+// - OTLP/HTTP logs endpoint at /v1/logs
+// - Loki push endpoint at /loki/api/v1/push
+// - Splunk HEC endpoint at /services/collector/event
+// - Elasticsearch bulk endpoint at /_bulk
+// - Status endpoint at / for server health checks, including source health
 //
-// The function runs indefinitely until the program is terminated
-// or an unrecoverable error occurs.
+// Without a -config file a single mock source generates a log every second.
+// Arguments after "--" name a command whose stdout and stderr are streamed
+// instead, e.g. `server -- ./my-service --verbose`. With -stdin, lines piped
+// into the server are streamed, e.g. `kubectl logs -f pod | server -stdin`;
+// the server shuts down when the input ends unless -keep-alive is set.
+// The function runs until it receives SIGINT or SIGTERM, then stops all
+// sources and shuts the HTTP server down gracefully.
 func main() {
+	configPath := flag.String("config", "", "path to a JSON configuration file")
+	readStdin := flag.Bool("stdin", false, "stream lines read from standard input")
+	keepAlive := flag.Bool("keep-alive", false, "keep serving after standard input ends instead of shutting down")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [-- command [args...]]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
 	log.Printf("Starting Smart Log Viewer Server...")
 
+	cfg := config.Default()
+	if *configPath != "" {
+		loaded, err := config.Load(*configPath)
+		if err != nil {
+			log.Fatal("Failed to load configuration: ", err)
+		}
+		cfg = loaded
+	}
+
+	// Standard input or a command after "--" replace the default mock
+	// source unless a config file was given.
+	command := flag.Args()
+	if *configPath == "" && (*readStdin || len(command) > 0) {
+		cfg.Sources = nil
+	}
+
+	// A command after "--" is streamed by an exec source.
+	if len(command) > 0 {
+		options, err := json.Marshal(process.Config{Command: command})
+		if err != nil {
+			log.Fatal("Failed to configure command: ", err)
+		}
+		cfg.Sources = append(cfg.Sources, config.SourceConfig{Name: "exec", Type: "exec", Options: options})
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// Create connection hub
-	hub := websocket.NewConnectionHub()
+	hub := websocket.NewConnectionHub(cfg.History)
 
 	// Start hub in background
 	go hub.Run()
 
-	// Start log generation in background
-	go func() {
-		count := 0
-		for {
-			count++
-			time.Sleep(1 * time.Second)
-
-			// Create WebSocket message
-			message := model.WebSocketMessage{
-				Type: "log",
-				Data: loggenerator.GenerateMockLog(" - Test message " + strconv.Itoa(count)),
-			}
-
-			// Send to broadcast channel
-			log.Printf("Sending log #%d to broadcast channel...", count)
-			hub.Broadcast <- message
-			log.Printf("Sent log #%d to broadcast channel", count)
+	// Create and start log sources
+	registry := source.NewRegistry(hub.Broadcast)
+	if err := registerSources(registry, cfg); err != nil {
+		log.Fatal("Failed to configure sources: ", err)
+	}
+
+	// Lines piped into the server are streamed by a stdin source
+	if *readStdin {
+		onEOF := stop
+		if *keepAlive {
+			onEOF = nil
 		}
-	}()
+		// Piped lines carry no event time of their own, so they get the
+		// default stages, which take it from the start of each line.
+		stages, err := buildStages(config.SourceConfig{Name: "stdin", Type: "stdin"})
+		if err != nil {
+			log.Fatal("Failed to configure stdin source: ", err)
+		}
+		if err := registry.Register(stdin.NewSource("stdin", os.Stdin, onEOF), stages...); err != nil {
+			log.Fatal("Failed to register stdin source: ", err)
+		}
+	}
+
+	// The HTTP ingest receiver is a source too, fed by the push endpoints.
+	// Pushed plain-text lines may carry their event time, so it gets the
+	// default stages as well.
+	receiver := ingest.NewReceiver("ingest", cfg.Ingest)
+	stages, err := buildStages(config.SourceConfig{Name: "ingest", Type: "ingest"})
+	if err != nil {
+		log.Fatal("Failed to configure ingest receiver: ", err)
+	}
+	if err := registry.Register(receiver, stages...); err != nil {
+		log.Fatal("Failed to register ingest receiver: ", err)
+	}
+	if err := registry.Start(ctx); err != nil {
+		log.Printf("Some sources failed to start: %v", err)
+	}
+
+	mux := http.NewServeMux()
 
 	// HTTP handler for WebSocket upgrade
-	http.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
 		websocket.HandleWebSocket(w, r, hub)
 	})
 
+	// HTTP ingest endpoint for pushed logs
+	mux.HandleFunc("/api/ingest", receiver.HandleIngest)
+
+	// OTLP/HTTP logs endpoint, so OpenTelemetry exporters can target the viewer
+	mux.HandleFunc("/v1/logs", receiver.HandleOTLPLogs)
+
+	// Loki push API, so Promtail and Grafana Agent can ship logs unchanged
+	mux.HandleFunc("/loki/api/v1/push", receiver.HandleLokiPush)
+
+	// Splunk HEC and Elasticsearch bulk endpoints for Fluent Bit, Vector and Logstash
+	mux.HandleFunc("/services/collector", receiver.HandleHECEvent)
+	mux.HandleFunc("/services/collector/event", receiver.HandleHECEvent)
+	mux.HandleFunc("/services/collector/event/1.0", receiver.HandleHECEvent)
+	mux.HandleFunc("/services/collector/health", receiver.HandleHECHealth)
+	mux.HandleFunc("/services/collector/health/1.0", receiver.HandleHECHealth)
+	mux.HandleFunc("/_bulk", receiver.HandleBulk)
+	mux.HandleFunc("/{index}/_bulk", receiver.HandleBulk)
+
 	// Simple status endpoint
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		if _, err := w.Write([]byte("Smart Log Viewer Server is running!\nConnect to /ws for WebSocket")); err != nil {
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if _, err := w.Write([]byte(statusText(registry))); err != nil {
 			log.Printf("Error writing response: %v", err)
 		}
 	})
 
-	log.Printf("Server starting on :8080")
-	log.Printf("WebSocket endpoint: ws://localhost:8080/ws")
+	server := &http.Server{
+		Addr:    cfg.Addr,
+		Handler: mux,
+	}
+
+	go func() {
+		log.Printf("Server starting on %s", cfg.Addr)
+		log.Printf("WebSocket endpoint: ws://localhost%s/ws", cfg.Addr)
+
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Server failed to start:", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Printf("Shutting down Smart Log Viewer Server...")
+
+	if err := registry.Stop(); err != nil {
+		log.Printf("Error stopping sources: %v", err)
+	}
+
+	// Give connections a moment to write the last broadcast logs, which
+	// matters when a short pipeline ends right after producing its output.
+	time.Sleep(shutdownFlushDelay)
 
-	if err := http.ListenAndServe(":8080", nil); err != nil {
-		log.Fatal("Server failed to start:", err)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Error shutting down HTTP server: %v", err)
 	}
+}
 
+// statusText renders the plain-text status page including the health
+// of every registered source.
+//
+// Parameters:
+//   - registry: The source registry to report on
+//
+// Returns:
+//   - string: The status page body
+func statusText(registry *source.Registry) string {
+	var b strings.Builder
+	b.WriteString("Smart Log Viewer Server is running!\nConnect to /ws for WebSocket\n")
+
+	health := registry.Health()
+
+	b.WriteString("\nSources:\n")
+	for _, name := range registry.Names() {
+		h := health[name]
+		fmt.Fprintf(&b, "- %s: %s (%d logs)", name, h.State, h.Emitted)
+		if h.LastError != "" {
+			fmt.Fprintf(&b, " last error: %s", h.LastError)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
 }
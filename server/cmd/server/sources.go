@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+
+	"smart-log-viewer/server/internal/config"
+	"smart-log-viewer/server/internal/forward"
+	"smart-log-viewer/server/internal/gelf"
+	"smart-log-viewer/server/internal/loggenerator"
+	"smart-log-viewer/server/internal/parser"
+	"smart-log-viewer/server/internal/process"
+	"smart-log-viewer/server/internal/replay"
+	"smart-log-viewer/server/internal/source"
+	"smart-log-viewer/server/internal/syslog"
+	"smart-log-viewer/server/internal/tail"
+	"smart-log-viewer/server/internal/timestamp"
+)
+
+// buildSource creates the source described by a source configuration.
+//
+// Parameters:
+//   - sc: The source configuration from the config file
+//
+// Returns:
+//   - source.Source: The configured source, not yet started
+//   - error: nil on success, or an error for unknown types and invalid options
+func buildSource(sc config.SourceConfig) (source.Source, error) {
+	switch sc.Type {
+	case "mock":
+		var opts loggenerator.Config
+		if err := sc.Decode(&opts); err != nil {
+			return nil, err
+		}
+		return loggenerator.NewSource(sc.Name, opts), nil
+	case "tail":
+		var opts tail.Config
+		if err := sc.Decode(&opts); err != nil {
+			return nil, err
+		}
+		return tail.NewSource(sc.Name, opts)
+	case "glob":
+		var opts tail.GlobConfig
+		if err := sc.Decode(&opts); err != nil {
+			return nil, err
+		}
+		return tail.NewGlobSource(sc.Name, opts)
+	case "syslog":
+		var opts syslog.Config
+		if err := sc.Decode(&opts); err != nil {
+			return nil, err
+		}
+		return syslog.NewSource(sc.Name, opts)
+	case "forward":
+		var opts forward.Config
+		if err := sc.Decode(&opts); err != nil {
+			return nil, err
+		}
+		return forward.NewSource(sc.Name, opts), nil
+	case "gelf":
+		var opts gelf.Config
+		if err := sc.Decode(&opts); err != nil {
+			return nil, err
+		}
+		return gelf.NewSource(sc.Name, opts)
+	case "exec":
+		var opts process.Config
+		if err := sc.Decode(&opts); err != nil {
+			return nil, err
+		}
+		return process.NewSource(sc.Name, opts)
+	case "replay":
+		var opts replay.Config
+		if err := sc.Decode(&opts); err != nil {
+			return nil, err
+		}
+		return replay.NewSource(sc.Name, opts)
+	default:
+		return nil, fmt.Errorf("source %q: unknown type %q", sc.Name, sc.Type)
+	}
+}
+
+// buildStages creates the processing stages described by a source configuration.
+//
+// Parameters:
+//   - sc: The source configuration from the config file
+//
+// Returns:
+//   - []source.Stage: The stages in order: the parsers, if any are
+//     configured, and the timestamp extraction
+//   - error: nil on success, or an error for unknown types and invalid options
+func buildStages(sc config.SourceConfig) ([]source.Stage, error) {
+	var stages []source.Stage
+	chain, err := parser.Build(sc.Parsers)
+	if err != nil {
+		return nil, fmt.Errorf("source %q: %w", sc.Name, err)
+	}
+	if len(chain) > 0 {
+		stages = append(stages, chain)
+	}
+
+	extractor, err := timestamp.NewExtractor(sc.Timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("source %q: %w", sc.Name, err)
+	}
+	return append(stages, extractor), nil
+}
+
+// registerSources builds every configured source and adds it to the registry.
+//
+// Parameters:
+//   - registry: The registry to add the sources to
+//   - cfg: The server configuration
+//
+// Returns:
+//   - error: nil on success, or the first build or registration error
+func registerSources(registry *source.Registry, cfg *config.Config) error {
+	for _, sc := range cfg.Sources {
+		src, err := buildSource(sc)
+		if err != nil {
+			return err
+		}
+		stages, err := buildStages(sc)
+		if err != nil {
+			return err
+		}
+		if err := registry.Register(src, stages...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -2,4 +2,16 @@ module smart-log-viewer/server
 
 go 1.24.4
 
-require github.com/gorilla/websocket v1.5.3
+require (
+	github.com/fsnotify/fsnotify v1.9.0
+	github.com/golang/snappy v1.0.0
+	github.com/gorilla/websocket v1.5.3
+	github.com/klauspost/compress v1.18.0
+	github.com/vmihailenco/msgpack/v5 v5.4.1
+	google.golang.org/protobuf v1.36.9
+)
+
+require (
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	golang.org/x/sys v0.13.0 // indirect
+)
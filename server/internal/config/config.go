@@ -0,0 +1,242 @@
+// Package config provides configuration management for the server.
+// Configuration is read from an optional JSON file; without one the server
+// falls back to defaults that reproduce the original demo behaviour.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Config is the top-level server configuration.
+type Config struct {
+	// Addr is the TCP address the HTTP server listens on, e.g. ":8080".
+	Addr string `json:"addr"`
+
+	// Sources lists the log sources the server runs.
+	Sources []SourceConfig `json:"sources"`
+
+	// Ingest configures the HTTP endpoints that accept pushed logs.
+	Ingest IngestConfig `json:"ingest"`
+
+	// History configures the recent logs kept for clients that connect.
+	History HistoryConfig `json:"history"`
+}
+
+// HistoryConfig bounds the logs the server keeps in memory to backfill
+// clients. The oldest logs are evicted once either limit is reached.
+type HistoryConfig struct {
+	// MaxEntries is the most logs kept (default 10000).
+	MaxEntries int `json:"maxEntries"`
+
+	// MaxBytes is the most memory the kept logs may use, measured as their
+	// JSON size (default 32 MiB).
+	MaxBytes int64 `json:"maxBytes"`
+}
+
+// IngestConfig holds the options of the HTTP ingest endpoints.
+type IngestConfig struct {
+	// MaxBodySize is the largest accepted request body in bytes after
+	// decompression (default 10 MiB).
+	MaxBodySize int64 `json:"maxBodySize"`
+
+	// HECTokens lists the tokens accepted by the Splunk HEC endpoint.
+	// When empty any token is accepted.
+	HECTokens []string `json:"hecTokens"`
+}
+
+// SourceConfig describes one log source.
+// Options are kept raw so that each source package can decode its own settings.
+type SourceConfig struct {
+	// Name uniquely identifies the source; it defaults to the type.
+	Name string `json:"name"`
+
+	// Type selects the source implementation, e.g. "mock".
+	Type string `json:"type"`
+
+	// Options holds the type-specific settings of the source.
+	Options json.RawMessage `json:"options,omitempty"`
+
+	// Parsers lists the parsers applied to every line of the source.
+	Parsers []ParserConfig `json:"parsers,omitempty"`
+
+	// Timestamp configures how the event time of entries is found when
+	// neither the source nor a parser supplied one.
+	Timestamp TimestampConfig `json:"timestamp"`
+}
+
+// TimestampConfig holds the options of a source's timestamp extraction.
+type TimestampConfig struct {
+	// Field names a field that holds the event time. Without it the time is
+	// taken from the start of the message.
+	Field string `json:"field"`
+
+	// Timezone is the IANA zone of timestamps that carry none (default
+	// local time).
+	Timezone string `json:"timezone"`
+}
+
+// ParserConfig describes one parser of a source.
+// In JSON it is either an object or just the type name, e.g. "cri".
+type ParserConfig struct {
+	// Type selects the parser implementation, e.g. "docker" or "cri".
+	Type string `json:"type"`
+
+	// Options holds the type-specific settings of the parser.
+	Options json.RawMessage `json:"options,omitempty"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting the short string form.
+func (p *ParserConfig) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*p = ParserConfig{Type: name}
+		return nil
+	}
+
+	// The alias type drops this method so the object form decodes normally.
+	type plain ParserConfig
+	return json.Unmarshal(data, (*plain)(p))
+}
+
+// Decode unmarshals the parser options into v.
+// Empty options leave v untouched so that defaults apply.
+//
+// Parameters:
+//   - v: Pointer to the type-specific options struct
+//
+// Returns:
+//   - error: nil on success, or a decoding error naming the parser
+func (p ParserConfig) Decode(v interface{}) error {
+	if len(p.Options) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(p.Options, v); err != nil {
+		return fmt.Errorf("parser %q: invalid options: %w", p.Type, err)
+	}
+	return nil
+}
+
+// Decode unmarshals the source options into v.
+// Empty options leave v untouched so that defaults apply.
+//
+// Parameters:
+//   - v: Pointer to the type-specific options struct
+//
+// Returns:
+//   - error: nil on success, or a decoding error naming the source
+func (s SourceConfig) Decode(v interface{}) error {
+	if len(s.Options) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(s.Options, v); err != nil {
+		return fmt.Errorf("source %q: invalid options: %w", s.Name, err)
+	}
+	return nil
+}
+
+// Default returns the configuration used when no file is given:
+// a single mock source and the port from the PORT environment variable.
+//
+// Returns:
+//   - *Config: The default configuration
+func Default() *Config {
+	return &Config{
+		Addr: defaultAddr(),
+		Sources: []SourceConfig{
+			{Name: "mock", Type: "mock"},
+		},
+	}
+}
+
+// Load reads a JSON configuration file and fills in defaults.
+//
+// Parameters:
+//   - path: Path to the JSON configuration file
+//
+// Returns:
+//   - *Config: The loaded configuration
+//   - error: nil on success, or an error if the file cannot be read or is invalid
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+
+	cfg := &Config{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parse config %s: %w", path, err)
+	}
+
+	if cfg.Addr == "" {
+		cfg.Addr = defaultAddr()
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// validate fills in source names and checks that they are unique.
+func (c *Config) validate() error {
+	seen := make(map[string]bool, len(c.Sources))
+	for i := range c.Sources {
+		src := &c.Sources[i]
+		if src.Type == "" {
+			return fmt.Errorf("source #%d: missing type", i+1)
+		}
+		if src.Name == "" {
+			src.Name = src.Type
+		}
+		if seen[src.Name] {
+			return fmt.Errorf("duplicate source name %q", src.Name)
+		}
+		seen[src.Name] = true
+	}
+	return nil
+}
+
+// defaultAddr builds the listen address from the PORT environment variable.
+func defaultAddr() string {
+	if port := os.Getenv("PORT"); port != "" {
+		return ":" + port
+	}
+	return ":8080"
+}
+
+// Duration is a time.Duration that unmarshals from JSON strings such as "1s"
+// or "250ms", as well as from plain numbers of nanoseconds.
+type Duration struct {
+	time.Duration
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case float64:
+		d.Duration = time.Duration(v)
+		return nil
+	case string:
+		parsed, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		d.Duration = parsed
+		return nil
+	default:
+		return errors.New("invalid duration")
+	}
+}
+
+// MarshalJSON implements json.Marshaler.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
@@ -0,0 +1,98 @@
+// Package document maps the free-form JSON documents of log shippers, as
+// received by the Elasticsearch and Splunk endpoints and the Fluent Forward
+// listener, onto log entries.
+package document
+
+import (
+	"encoding/json"
+
+	"smart-log-viewer/server/internal/level"
+	"smart-log-viewer/server/internal/model"
+	"smart-log-viewer/server/internal/timestamp"
+)
+
+var (
+	// messageKeys are the keys log shippers put the log line under.
+	messageKeys = []string{"message", "msg", "log"}
+
+	// levelKeys are the keys log shippers put the level under.
+	levelKeys = []string{"level", "log.level", "severity", "levelname"}
+
+	// timeKeys are the keys log shippers put the timestamp under.
+	timeKeys = []string{"@timestamp", "timestamp", "time"}
+
+	// hostKeys are the keys log shippers put the hostname under.
+	hostKeys = []string{"host", "hostname", "host.name"}
+)
+
+// Log converts a free-form document from a log shipper into a log entry.
+// It never rejects a document: the message, level, timestamp and host come
+// from the first of the usual keys that holds a usable value, and every
+// other key becomes a field. A document without a message is shown as JSON.
+//
+// Parameters:
+//   - doc: The decoded document
+//
+// Returns:
+//   - model.Log: The log entry
+func Log(doc map[string]interface{}) model.Log {
+	entry := model.Log{
+		Level:  level.Info,
+		Fields: make(map[string]interface{}, len(doc)),
+	}
+	for key, value := range doc {
+		entry.Fields[key] = value
+	}
+
+	for _, key := range messageKeys {
+		if text, ok := entry.Fields[key].(string); ok {
+			entry.Message = text
+			delete(entry.Fields, key)
+			break
+		}
+	}
+	if entry.Message == "" {
+		text, _ := json.Marshal(doc)
+		entry.Message = string(text)
+	}
+
+	// ECS nests the level as {"log": {"level": "..."}}.
+	if nested, ok := entry.Fields["log"].(map[string]interface{}); ok {
+		if text, ok := nested["level"].(string); ok {
+			level.Apply(&entry, text)
+		}
+	}
+	for _, key := range levelKeys {
+		text, _ := entry.Fields[key].(string)
+		if level.Apply(&entry, text) {
+			delete(entry.Fields, key)
+			break
+		}
+	}
+
+	for _, key := range timeKeys {
+		if t, ok := timestamp.Value(entry.Fields[key]); ok {
+			entry.Timestamp = t
+			delete(entry.Fields, key)
+			break
+		}
+	}
+
+	// ECS nests the hostname as {"host": {"name": "..."}}; the rest of the
+	// host object stays a field.
+	if nested, ok := entry.Fields["host"].(map[string]interface{}); ok {
+		entry.Host, _ = nested["name"].(string)
+	}
+	for _, key := range hostKeys {
+		if text, ok := entry.Fields[key].(string); ok && entry.Host == "" {
+			entry.Host = text
+			delete(entry.Fields, key)
+			break
+		}
+	}
+
+	if len(entry.Fields) == 0 {
+		entry.Fields = nil
+	}
+	return entry
+}
@@ -0,0 +1,91 @@
+package document
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"smart-log-viewer/server/internal/model"
+)
+
+func TestLog(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want model.Log
+	}{
+		{
+			name: "common keys",
+			doc:  `{"message":"m","level":"warning","@timestamp":"2024-03-10T11:22:33Z","host":"web01","user":"ann"}`,
+			want: model.Log{
+				Level: "WARN", RawLevel: "warning", Message: "m", Host: "web01",
+				Timestamp: time.Date(2024, time.March, 10, 11, 22, 33, 0, time.UTC),
+				Fields:    map[string]interface{}{"user": "ann"},
+			},
+		},
+		{
+			name: "first usable key wins",
+			doc:  `{"msg":"from msg","log":"from log","severity":"loud","levelname":"ERROR","timestamp":"soon","time":1710069753}`,
+			want: model.Log{
+				Level: "ERROR", RawLevel: "ERROR", Message: "from msg", Timestamp: time.Unix(1710069753, 0),
+				Fields: map[string]interface{}{"log": "from log", "severity": "loud", "timestamp": "soon"},
+			},
+		},
+		{
+			name: "ecs nesting",
+			doc:  `{"message":"m","log":{"level":"debug","logger":"db"},"host":{"name":"web01","ip":"10.0.0.1"}}`,
+			want: model.Log{
+				Level: "DEBUG", RawLevel: "debug", Message: "m", Host: "web01",
+				Fields: map[string]interface{}{
+					"log":  map[string]interface{}{"level": "debug", "logger": "db"},
+					"host": map[string]interface{}{"name": "web01", "ip": "10.0.0.1"},
+				},
+			},
+		},
+		{
+			name: "dotted keys",
+			doc:  `{"message":"m","log.level":"error","host.name":"db01"}`,
+			want: model.Log{Level: "ERROR", RawLevel: "error", Message: "m", Host: "db01"},
+		},
+		{
+			name: "no message is shown as JSON",
+			doc:  `{"count":2}`,
+			want: model.Log{Level: "INFO", Message: `{"count":2}`, Fields: map[string]interface{}{"count": json.Number("2")}},
+		},
+		{
+			name: "unusable values stay fields",
+			doc:  `{"message":1,"level":3,"time":"NaN","hostname":["a"]}`,
+			want: model.Log{
+				Level: "INFO", Message: `{"hostname":["a"],"level":3,"message":1,"time":"NaN"}`,
+				Fields: map[string]interface{}{"message": json.Number("1"), "level": json.Number("3"), "time": "NaN", "hostname": []interface{}{"a"}},
+			},
+		},
+		{
+			name: "empty",
+			doc:  `{}`,
+			want: model.Log{Level: "INFO", Message: `{}`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder := json.NewDecoder(strings.NewReader(tt.doc))
+			decoder.UseNumber()
+			var doc map[string]interface{}
+			if err := decoder.Decode(&doc); err != nil {
+				t.Fatal(err)
+			}
+
+			got := Log(doc)
+			if !got.Timestamp.Equal(tt.want.Timestamp) {
+				t.Errorf("Timestamp = %v, want %v", got.Timestamp, tt.want.Timestamp)
+			}
+			got.Timestamp, tt.want.Timestamp = time.Time{}, time.Time{}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Log() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,409 @@
+// Package forward provides a log source that receives logs over the Fluentd
+// Forward protocol, which Fluentd and Fluent Bit use to ship logs between
+// nodes. The Message, Forward, PackedForward and CompressedPackedForward
+// modes are understood, and chunks are acknowledged when the sender asks.
+package forward
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"github.com/vmihailenco/msgpack/v5/msgpcode"
+
+	"smart-log-viewer/server/internal/document"
+	"smart-log-viewer/server/internal/model"
+	"smart-log-viewer/server/internal/source"
+)
+
+const (
+	// defaultAddr is the standard Forward protocol port.
+	defaultAddr = ":24224"
+
+	// maxDecompressedSize caps a decompressed CompressedPackedForward chunk.
+	maxDecompressedSize = 64 * 1024 * 1024
+
+	// eventTimeExt is the MessagePack extension type of an EventTime.
+	eventTimeExt = 0
+)
+
+// Config holds the options of a forward source.
+type Config struct {
+	// Addr is the TCP address to listen on (default ":24224").
+	Addr string `json:"addr"`
+}
+
+// Source is a source.Source that listens for Forward protocol connections.
+type Source struct {
+	source.Status
+
+	name      string
+	cfg       Config
+	ln        net.Listener
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	conns     source.Conns
+	closeOnce sync.Once
+}
+
+// event is one log event of a Forward protocol message.
+type event struct {
+	time   time.Time
+	record map[string]interface{}
+}
+
+// NewSource creates a forward source.
+//
+// Parameters:
+//   - name: The unique name of the source
+//   - cfg: The source options
+//
+// Returns:
+//   - *Source: A new forward source instance
+func NewSource(name string, cfg Config) *Source {
+	if cfg.Addr == "" {
+		cfg.Addr = defaultAddr
+	}
+	return &Source{name: name, cfg: cfg}
+}
+
+// Name returns the unique name of the source.
+func (s *Source) Name() string {
+	return s.name
+}
+
+// Start opens the listener.
+//
+// Parameters:
+//   - ctx: Context that stops the listener when cancelled
+//   - out: Channel received log entries are sent to
+//
+// Returns:
+//   - error: nil on success, or an error if the listener cannot be opened
+func (s *Source) Start(ctx context.Context, out chan<- model.Log) error {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	ln, err := net.Listen("tcp", s.cfg.Addr)
+	if err != nil {
+		s.cancel()
+		s.SetError(source.StateFailed, err)
+		return fmt.Errorf("listen forward tcp: %w", err)
+	}
+	s.ln = ln
+	log.Printf("Forward source %q listening on tcp %s", s.name, ln.Addr())
+	s.SetState(source.StateRunning)
+
+	s.wg.Add(1)
+	go s.serve(ctx, out)
+
+	// Closing the listener unblocks the serving goroutines on shutdown.
+	go func() {
+		<-ctx.Done()
+		s.closeListener()
+	}()
+	return nil
+}
+
+// serve accepts connections and serves each in its own goroutine.
+func (s *Source) serve(ctx context.Context, out chan<- model.Log) {
+	defer s.wg.Done()
+
+	err := s.conns.Accept(s.ln, &s.wg, func(conn net.Conn) {
+		s.serveConn(ctx, out, conn)
+	})
+	if ctx.Err() == nil {
+		log.Printf("Forward source %q: %v", s.name, err)
+		s.SetError(source.StateDegraded, err)
+	}
+}
+
+// serveConn reads Forward protocol messages from one connection.
+func (s *Source) serveConn(ctx context.Context, out chan<- model.Log, conn net.Conn) {
+	dec := msgpack.NewDecoder(bufio.NewReader(conn))
+	for {
+		if err := s.handleMessage(ctx, out, dec, conn); err != nil {
+			if !errors.Is(err, io.EOF) && ctx.Err() == nil {
+				log.Printf("Forward source %q: closing connection from %s: %v", s.name, conn.RemoteAddr(), err)
+			}
+			return
+		}
+	}
+}
+
+// handleMessage reads one message, emits its events and acknowledges it
+// if the sender asked for it. The message is one of:
+//
+//	Message:                 [tag, time, record, option?]
+//	Forward:                 [tag, [[time, record], ...], option?]
+//	PackedForward:           [tag, bin(entries), option?]
+//	CompressedPackedForward: [tag, bin(gzip(entries)), {"compressed": "gzip", ...}]
+//
+// Returns:
+//   - error: io.EOF at the end of the stream, a protocol error, or
+//     ctx.Err() if the source is shutting down
+func (s *Source) handleMessage(ctx context.Context, out chan<- model.Log, dec *msgpack.Decoder, conn net.Conn) error {
+	n, err := dec.DecodeArrayLen()
+	if err != nil {
+		return err
+	}
+	if n < 2 || n > 4 {
+		return fmt.Errorf("invalid message with %d elements", n)
+	}
+	tag, err := dec.DecodeString()
+	if err != nil {
+		return fmt.Errorf("read tag: %w", err)
+	}
+
+	code, err := dec.PeekCode()
+	if err != nil {
+		return err
+	}
+
+	var events []event
+	var packed []byte
+	read := 2
+	switch {
+	case msgpcode.IsFixedArray(code) || code == msgpcode.Array16 || code == msgpcode.Array32:
+		count, err := dec.DecodeArrayLen()
+		if err != nil {
+			return err
+		}
+		for i := 0; i < count; i++ {
+			ev, err := decodeEntry(dec)
+			if err != nil {
+				return err
+			}
+			events = append(events, ev)
+		}
+	case msgpcode.IsBin(code) || msgpcode.IsString(code):
+		if packed, err = dec.DecodeBytes(); err != nil {
+			return err
+		}
+	default:
+		var ev event
+		if ev.time, err = decodeTime(dec); err != nil {
+			return err
+		}
+		if ev.record, err = dec.DecodeMap(); err != nil {
+			return fmt.Errorf("read record: %w", err)
+		}
+		events = append(events, ev)
+		read = 3
+	}
+
+	var option map[string]interface{}
+	if n > read {
+		if option, err = dec.DecodeMap(); err != nil {
+			return fmt.Errorf("read option: %w", err)
+		}
+	}
+
+	if packed != nil {
+		if option["compressed"] == "gzip" {
+			if packed, err = gunzip(packed); err != nil {
+				return err
+			}
+		}
+		if events, err = decodePacked(packed); err != nil {
+			return err
+		}
+	}
+
+	for _, ev := range events {
+		if !s.Emit(ctx, out, eventLog(tag, ev)) {
+			return ctx.Err()
+		}
+	}
+
+	if chunk, ok := option["chunk"].(string); ok && chunk != "" {
+		if err := msgpack.NewEncoder(conn).Encode(map[string]string{"ack": chunk}); err != nil {
+			return fmt.Errorf("send ack: %w", err)
+		}
+	}
+	return nil
+}
+
+// decodeEntry reads one [time, record] entry. Fluent Bit 2.1 and later may
+// send [[time, metadata], record] instead; the metadata is ignored.
+func decodeEntry(dec *msgpack.Decoder) (event, error) {
+	var ev event
+	n, err := dec.DecodeArrayLen()
+	if err != nil {
+		return ev, err
+	}
+	if n != 2 {
+		return ev, fmt.Errorf("invalid entry with %d elements", n)
+	}
+
+	code, err := dec.PeekCode()
+	if err != nil {
+		return ev, err
+	}
+	if msgpcode.IsFixedArray(code) {
+		header, err := dec.DecodeArrayLen()
+		if err != nil {
+			return ev, err
+		}
+		if header < 1 {
+			return ev, errors.New("empty entry header")
+		}
+		if ev.time, err = decodeTime(dec); err != nil {
+			return ev, err
+		}
+		for i := 1; i < header; i++ {
+			if err := dec.Skip(); err != nil {
+				return ev, err
+			}
+		}
+	} else if ev.time, err = decodeTime(dec); err != nil {
+		return ev, err
+	}
+
+	if ev.record, err = dec.DecodeMap(); err != nil {
+		return ev, fmt.Errorf("read record: %w", err)
+	}
+	return ev, nil
+}
+
+// decodePacked decodes the entry stream of a PackedForward message.
+func decodePacked(data []byte) ([]event, error) {
+	reader := bytes.NewReader(data)
+	dec := msgpack.NewDecoder(reader)
+
+	var events []event
+	for reader.Len() > 0 {
+		ev, err := decodeEntry(dec)
+		if err != nil {
+			return nil, fmt.Errorf("read packed entry: %w", err)
+		}
+		events = append(events, ev)
+	}
+	return events, nil
+}
+
+// decodeTime reads an event time: an EventTime extension with nanosecond
+// precision, or an integer or float of seconds since the epoch.
+func decodeTime(dec *msgpack.Decoder) (time.Time, error) {
+	code, err := dec.PeekCode()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if code == msgpcode.FixExt8 || code == msgpcode.Ext8 {
+		extID, extLen, err := dec.DecodeExtHeader()
+		if err != nil {
+			return time.Time{}, err
+		}
+		if extID != eventTimeExt || extLen != 8 {
+			return time.Time{}, fmt.Errorf("unexpected extension type %d of %d bytes as time", extID, extLen)
+		}
+		var buf [8]byte
+		if err := dec.ReadFull(buf[:]); err != nil {
+			return time.Time{}, err
+		}
+		seconds := binary.BigEndian.Uint32(buf[:4])
+		nanos := binary.BigEndian.Uint32(buf[4:])
+		return time.Unix(int64(seconds), int64(nanos)), nil
+	}
+
+	value, err := dec.DecodeInterfaceLoose()
+	if err != nil {
+		return time.Time{}, err
+	}
+	switch v := value.(type) {
+	case int64:
+		return time.Unix(v, 0), nil
+	case uint64:
+		return time.Unix(int64(v), 0), nil
+	case float64:
+		return time.UnixMicro(int64(math.Round(v * 1e6))), nil
+	case time.Time:
+		return v, nil
+	default:
+		return time.Time{}, fmt.Errorf("invalid event time of type %T", value)
+	}
+}
+
+// gunzip decompresses a CompressedPackedForward chunk, which may consist of
+// several concatenated gzip members.
+func gunzip(data []byte) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid gzip chunk: %w", err)
+	}
+	defer gz.Close()
+
+	decompressed, err := io.ReadAll(io.LimitReader(gz, maxDecompressedSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("invalid gzip chunk: %w", err)
+	}
+	if len(decompressed) > maxDecompressedSize {
+		return nil, fmt.Errorf("decompressed chunk exceeds limit of %d bytes", maxDecompressedSize)
+	}
+	return decompressed, nil
+}
+
+// eventLog converts an event into a log entry. The record is mapped like
+// a document pushed over HTTP, and the tag becomes the source.
+func eventLog(tag string, ev event) model.Log {
+	entry := document.Log(normalize(ev.record).(map[string]interface{}))
+	entry.Timestamp = ev.time
+	entry.Source = tag
+	return entry
+}
+
+// normalize converts MessagePack binary values, which some senders use
+// for strings, into strings so that records render as text.
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalize(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalize(item)
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+// closeListener closes the listener and all open connections.
+func (s *Source) closeListener() {
+	s.closeOnce.Do(func() {
+		if s.ln != nil {
+			s.ln.Close()
+		}
+	})
+	s.conns.CloseAll()
+}
+
+// Stop closes the listener and waits for all connections to finish.
+//
+// Returns:
+//   - error: Always nil
+func (s *Source) Stop() error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.closeListener()
+	s.wg.Wait()
+	s.SetState(source.StateStopped)
+	return nil
+}
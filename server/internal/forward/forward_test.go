@@ -0,0 +1,307 @@
+package forward
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+
+	"smart-log-viewer/server/internal/model"
+)
+
+// mp encodes values as consecutive MessagePack values.
+func mp(t *testing.T, values ...interface{}) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	for _, value := range values {
+		if err := enc.Encode(value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes()
+}
+
+// eventTime encodes an EventTime extension.
+func eventTime(seconds, nanos uint32) msgpack.RawMessage {
+	raw := []byte{0xd7, eventTimeExt, 0, 0, 0, 0, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(raw[2:], seconds)
+	binary.BigEndian.PutUint32(raw[6:], nanos)
+	return raw
+}
+
+// gzipBytes compresses data.
+func gzipBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecodeTime(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     []byte
+		want    time.Time
+		wantErr string
+	}{
+		{name: "event time", raw: eventTime(1710069753, 123456789), want: time.Unix(1710069753, 123456789)},
+		{name: "ext8 event time", raw: append([]byte{0xc7, 8}, eventTime(1710069753, 1)[1:]...), want: time.Unix(1710069753, 1)},
+		{name: "integer", raw: mp(t, int64(1710069753)), want: time.Unix(1710069753, 0)},
+		{name: "unsigned", raw: mp(t, uint64(1710069753)), want: time.Unix(1710069753, 0)},
+		{name: "float", raw: mp(t, 1710069753.25), want: time.UnixMilli(1710069753250)},
+		{name: "string", raw: mp(t, "now"), wantErr: "invalid event time of type string"},
+		{name: "other extension", raw: []byte{0xd7, 5, 0, 0, 0, 0, 0, 0, 0, 0}, wantErr: "unexpected extension type 5"},
+		{name: "truncated event time", raw: eventTime(1710069753, 0)[:6], wantErr: "EOF"},
+		{name: "empty", raw: nil, wantErr: "EOF"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeTime(msgpack.NewDecoder(bytes.NewReader(tt.raw)))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("decodeTime() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeTime() error = %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("decodeTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeEntry(t *testing.T) {
+	record := map[string]interface{}{"message": "hi"}
+
+	tests := []struct {
+		name    string
+		raw     []byte
+		want    event
+		wantErr string
+	}{
+		{
+			name: "time and record",
+			raw:  mp(t, []interface{}{eventTime(1710069753, 5), record}),
+			want: event{time: time.Unix(1710069753, 5), record: record},
+		},
+		{
+			name: "time with metadata",
+			raw:  mp(t, []interface{}{[]interface{}{eventTime(1710069753, 5), map[string]interface{}{"otlp": true}}, record}),
+			want: event{time: time.Unix(1710069753, 5), record: record},
+		},
+		{name: "three elements", raw: mp(t, []interface{}{1, record, 3}), wantErr: "invalid entry with 3 elements"},
+		{name: "empty header", raw: mp(t, []interface{}{[]interface{}{}, record}), wantErr: "empty entry header"},
+		{name: "record not a map", raw: mp(t, []interface{}{1, "hi"}), wantErr: "read record"},
+		{name: "not an array", raw: mp(t, "entry"), wantErr: "msgpack"},
+		{name: "truncated", raw: mp(t, []interface{}{1, record})[:5], wantErr: "EOF"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeEntry(msgpack.NewDecoder(bytes.NewReader(tt.raw)))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("decodeEntry() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeEntry() error = %v", err)
+			}
+			if !got.time.Equal(tt.want.time) || !reflect.DeepEqual(got.record, tt.want.record) {
+				t.Errorf("decodeEntry() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// ackConn records what handleMessage writes back to the sender.
+type ackConn struct {
+	net.Conn
+	written bytes.Buffer
+}
+
+func (c *ackConn) Write(b []byte) (int, error) {
+	return c.written.Write(b)
+}
+
+func TestHandleMessage(t *testing.T) {
+	entry := func(seconds uint32, message string) []interface{} {
+		return []interface{}{eventTime(seconds, 0), map[string]interface{}{"message": message}}
+	}
+	packed := mp(t, entry(1, "p1"), entry(2, "p2"))
+
+	tests := []struct {
+		name    string
+		raw     []byte
+		want    []string
+		wantAck string
+		wantErr string
+	}{
+		{
+			name: "message",
+			raw:  mp(t, []interface{}{"app.web", eventTime(1, 0), map[string]interface{}{"log": "m", "level": "warn"}}),
+			want: []string{"app.web WARN m"},
+		},
+		{
+			name:    "message with chunk",
+			raw:     mp(t, []interface{}{"app", 1, map[string]interface{}{"message": "m"}, map[string]interface{}{"chunk": "abc"}}),
+			want:    []string{"app INFO m"},
+			wantAck: "abc",
+		},
+		{
+			name: "forward",
+			raw:  mp(t, []interface{}{"app", []interface{}{entry(1, "f1"), entry(2, "f2")}}),
+			want: []string{"app INFO f1", "app INFO f2"},
+		},
+		{
+			name:    "packed forward with chunk",
+			raw:     mp(t, []interface{}{"app", packed, map[string]interface{}{"chunk": "p", "size": 2}}),
+			want:    []string{"app INFO p1", "app INFO p2"},
+			wantAck: "p",
+		},
+		{
+			name: "packed forward as string",
+			raw:  mp(t, []interface{}{"app", string(packed)}),
+			want: []string{"app INFO p1", "app INFO p2"},
+		},
+		{
+			name: "compressed packed forward",
+			raw: mp(t, []interface{}{"app", append(gzipBytes(t, mp(t, entry(1, "c1"))), gzipBytes(t, mp(t, entry(2, "c2")))...),
+				map[string]interface{}{"compressed": "gzip"}}),
+			want: []string{"app INFO c1", "app INFO c2"},
+		},
+		{
+			name: "binary record values",
+			raw:  mp(t, []interface{}{"app", 1, map[string]interface{}{"message": []byte("bin"), "tags": []interface{}{[]byte("a")}}}),
+			want: []string{"app INFO bin"},
+		},
+		{name: "end of stream", raw: nil, wantErr: "EOF"},
+		{name: "too few elements", raw: mp(t, []interface{}{"app"}), wantErr: "invalid message with 1 elements"},
+		{name: "too many elements", raw: mp(t, []interface{}{"app", 1, 2, 3, 4}), wantErr: "invalid message with 5 elements"},
+		{name: "tag not a string", raw: mp(t, []interface{}{1, 1, map[string]interface{}{}}), wantErr: "read tag"},
+		{name: "record not a map", raw: mp(t, []interface{}{"app", 1, "record"}), wantErr: "read record"},
+		{name: "option not a map", raw: mp(t, []interface{}{"app", 1, map[string]interface{}{}, "option"}), wantErr: "read option"},
+		{
+			name:    "invalid gzip",
+			raw:     mp(t, []interface{}{"app", packed, map[string]interface{}{"compressed": "gzip"}}),
+			wantErr: "invalid gzip chunk",
+		},
+		{
+			name:    "truncated packed entries",
+			raw:     mp(t, []interface{}{"app", packed[:len(packed)-3]}),
+			wantErr: "read packed entry",
+		},
+		{
+			name:    "truncated message",
+			raw:     mp(t, []interface{}{"app", []interface{}{entry(1, "f1"), entry(2, "f2")}})[:20],
+			wantErr: "EOF",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := NewSource("forward", Config{})
+			out := make(chan model.Log, 10)
+			conn := &ackConn{}
+
+			err := src.handleMessage(context.Background(), out, msgpack.NewDecoder(bytes.NewReader(tt.raw)), conn)
+			close(out)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("handleMessage() error = %v, want %q", err, tt.wantErr)
+				}
+				if len(out) != 0 || conn.written.Len() != 0 {
+					t.Errorf("emitted %d entries and wrote %q for an invalid message", len(out), conn.written.String())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("handleMessage() error = %v", err)
+			}
+
+			var got []string
+			for entry := range out {
+				got = append(got, entry.Source+" "+entry.Level+" "+entry.Message)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("entries = %q, want %q", got, tt.want)
+			}
+
+			var ack map[string]string
+			if tt.wantAck == "" {
+				if conn.written.Len() != 0 {
+					t.Errorf("wrote %q without a chunk option", conn.written.String())
+				}
+				return
+			}
+			if err := msgpack.Unmarshal(conn.written.Bytes(), &ack); err != nil || ack["ack"] != tt.wantAck {
+				t.Errorf("ack = %v (%v), want %q", ack, err, tt.wantAck)
+			}
+		})
+	}
+}
+
+func TestSourceReceivesAndAcknowledges(t *testing.T) {
+	src := NewSource("forward", Config{Addr: "127.0.0.1:0"})
+	out := make(chan model.Log, 10)
+	if err := src.Start(context.Background(), out); err != nil {
+		t.Fatal(err)
+	}
+	defer src.Stop()
+
+	conn, err := net.Dial("tcp", src.ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	message := mp(t, []interface{}{"app", eventTime(1710069753, 0), map[string]interface{}{"message": "over tcp"}, map[string]interface{}{"chunk": "c1"}})
+	if _, err := conn.Write(message); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case entry := <-out:
+		if entry.Message != "over tcp" || entry.Source != "app" || !entry.Timestamp.Equal(time.Unix(1710069753, 0)) {
+			t.Errorf("entry = %+v, want the sent event", entry)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no entry received")
+	}
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var ack map[string]string
+	if err := msgpack.NewDecoder(conn).Decode(&ack); err != nil || ack["ack"] != "c1" {
+		t.Fatalf("ack = %v (%v), want c1", ack, err)
+	}
+
+	// A protocol error closes the connection.
+	if _, err := conn.Write(mp(t, "garbage")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Read(make([]byte, 1)); !errors.Is(err, io.EOF) {
+		t.Errorf("read after protocol error = %v, want EOF", err)
+	}
+}
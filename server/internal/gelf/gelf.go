@@ -0,0 +1,289 @@
+// Package gelf provides a log source that receives Graylog Extended Log
+// Format messages. UDP datagrams may be chunked and gzip- or
+// zlib-compressed; TCP streams carry null-delimited messages.
+package gelf
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"smart-log-viewer/server/internal/config"
+	"smart-log-viewer/server/internal/model"
+	"smart-log-viewer/server/internal/source"
+)
+
+const (
+	// defaultMaxMessageSize is the largest message accepted when none is
+	// configured, enough for 128 chunks of a full-sized datagram.
+	defaultMaxMessageSize = 1024 * 1024
+
+	// defaultChunkTimeout is how long the chunks of a message may take to arrive.
+	defaultChunkTimeout = 5 * time.Second
+
+	// maxDatagramSize is the largest UDP datagram that can be received.
+	maxDatagramSize = 65535
+)
+
+// Config holds the options of a GELF source.
+type Config struct {
+	// UDPAddr is the UDP address to listen on, e.g. ":12201". Empty disables UDP.
+	UDPAddr string `json:"udp"`
+
+	// TCPAddr is the TCP address to listen on, e.g. ":12201". Empty disables TCP.
+	TCPAddr string `json:"tcp"`
+
+	// MaxMessageSize is the largest accepted message in bytes after
+	// reassembly and decompression (default 1 MiB).
+	MaxMessageSize int `json:"maxMessageSize"`
+
+	// ChunkTimeout is how long to wait for all chunks of a UDP message (default 5s).
+	ChunkTimeout config.Duration `json:"chunkTimeout"`
+}
+
+// Source is a source.Source that listens for GELF messages.
+type Source struct {
+	source.Status
+
+	name      string
+	cfg       Config
+	udpConn   net.PacketConn
+	tcpLn     net.Listener
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	conns     source.Conns
+	closeOnce sync.Once
+}
+
+// NewSource creates a GELF source.
+//
+// Parameters:
+//   - name: The unique name of the source
+//   - cfg: The source options
+//
+// Returns:
+//   - *Source: A new GELF source instance
+//   - error: nil on success, or an error if no listen address is configured
+func NewSource(name string, cfg Config) (*Source, error) {
+	if cfg.UDPAddr == "" && cfg.TCPAddr == "" {
+		return nil, errors.New("gelf source needs a udp or tcp address")
+	}
+	if cfg.MaxMessageSize <= 0 {
+		cfg.MaxMessageSize = defaultMaxMessageSize
+	}
+	if cfg.ChunkTimeout.Duration <= 0 {
+		cfg.ChunkTimeout.Duration = defaultChunkTimeout
+	}
+	return &Source{name: name, cfg: cfg}, nil
+}
+
+// Name returns the unique name of the source.
+func (s *Source) Name() string {
+	return s.name
+}
+
+// Start opens the configured listeners.
+//
+// Parameters:
+//   - ctx: Context that stops the listeners when cancelled
+//   - out: Channel parsed log entries are sent to
+//
+// Returns:
+//   - error: nil on success, or an error if a listener cannot be opened
+func (s *Source) Start(ctx context.Context, out chan<- model.Log) error {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	if s.cfg.UDPAddr != "" {
+		conn, err := net.ListenPacket("udp", s.cfg.UDPAddr)
+		if err != nil {
+			s.cancel()
+			s.SetError(source.StateFailed, err)
+			return fmt.Errorf("listen gelf udp: %w", err)
+		}
+		s.udpConn = conn
+		log.Printf("GELF source %q listening on udp %s", s.name, conn.LocalAddr())
+	}
+
+	if s.cfg.TCPAddr != "" {
+		ln, err := net.Listen("tcp", s.cfg.TCPAddr)
+		if err != nil {
+			s.cancel()
+			s.closeListeners()
+			s.SetError(source.StateFailed, err)
+			return fmt.Errorf("listen gelf tcp: %w", err)
+		}
+		s.tcpLn = ln
+		log.Printf("GELF source %q listening on tcp %s", s.name, ln.Addr())
+	}
+
+	s.SetState(source.StateRunning)
+
+	if s.udpConn != nil {
+		s.wg.Add(1)
+		go s.serveUDP(ctx, out)
+	}
+	if s.tcpLn != nil {
+		s.wg.Add(1)
+		go s.serveTCP(ctx, out)
+	}
+
+	// Closing the listeners unblocks the serving goroutines on shutdown.
+	go func() {
+		<-ctx.Done()
+		s.closeListeners()
+	}()
+	return nil
+}
+
+// serveUDP reads datagrams, reassembling chunked messages.
+func (s *Source) serveUDP(ctx context.Context, out chan<- model.Log) {
+	defer s.wg.Done()
+
+	chunks := newAssembler(s.cfg.ChunkTimeout.Duration, s.cfg.MaxMessageSize)
+	buf := make([]byte, maxDatagramSize)
+	for {
+		n, addr, err := s.udpConn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.reportError(err)
+			}
+			return
+		}
+
+		message, err := chunks.add(buf[:n], time.Now())
+		if err != nil {
+			log.Printf("GELF source %q: dropping chunk from %s: %v", s.name, addr, err)
+			continue
+		}
+		if message == nil {
+			continue
+		}
+		if !s.handle(ctx, out, message, addr) {
+			return
+		}
+	}
+}
+
+// serveTCP accepts TCP connections and serves each in its own goroutine.
+func (s *Source) serveTCP(ctx context.Context, out chan<- model.Log) {
+	defer s.wg.Done()
+
+	err := s.conns.Accept(s.tcpLn, &s.wg, func(conn net.Conn) {
+		s.serveConn(ctx, out, conn)
+	})
+	if ctx.Err() == nil {
+		s.reportError(err)
+	}
+}
+
+// serveConn reads null-delimited messages from one TCP connection.
+func (s *Source) serveConn(ctx context.Context, out chan<- model.Log, conn net.Conn) {
+	reader := bufio.NewReaderSize(conn, 4096)
+	for {
+		frame, err := readFrame(reader, s.cfg.MaxMessageSize)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && ctx.Err() == nil {
+				log.Printf("GELF source %q: closing connection from %s: %v", s.name, conn.RemoteAddr(), err)
+			}
+			return
+		}
+		if !s.handle(ctx, out, frame, conn.RemoteAddr()) {
+			return
+		}
+	}
+}
+
+// readFrame reads one null-delimited message from a TCP stream.
+//
+// Parameters:
+//   - reader: The buffered connection reader
+//   - maxSize: The largest accepted frame in bytes
+//
+// Returns:
+//   - []byte: The message without the delimiter
+//   - error: io.EOF at the end of the stream, or a framing error
+func readFrame(reader *bufio.Reader, maxSize int) ([]byte, error) {
+	var frame []byte
+	for {
+		chunk, err := reader.ReadSlice(0)
+		frame = append(frame, chunk...)
+		if len(frame) > maxSize+1 {
+			return nil, fmt.Errorf("message exceeds limit of %d bytes", maxSize)
+		}
+		if err == nil {
+			return frame[:len(frame)-1], nil
+		}
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		if errors.Is(err, io.EOF) && len(frame) > 0 {
+			return frame, nil
+		}
+		return nil, err
+	}
+}
+
+// handle decompresses and parses a message and emits it.
+//
+// Returns:
+//   - bool: false if the source is shutting down
+func (s *Source) handle(ctx context.Context, out chan<- model.Log, data []byte, addr net.Addr) bool {
+	payload, err := decompress(data, s.cfg.MaxMessageSize)
+	var entry model.Log
+	if err == nil {
+		entry, err = parseMessage(payload, time.Now())
+	}
+	if err != nil {
+		if !errors.Is(err, errEmptyMessage) {
+			log.Printf("GELF source %q: dropping message from %s: %v", s.name, addr, err)
+		}
+		return true
+	}
+
+	entry.Source = s.name
+	if entry.Host == "" {
+		if host, _, err := net.SplitHostPort(addr.String()); err == nil {
+			entry.Host = host
+		}
+	}
+	return s.Emit(ctx, out, entry)
+}
+
+// reportError marks the source as degraded after a listener error.
+func (s *Source) reportError(err error) {
+	log.Printf("GELF source %q: %v", s.name, err)
+	s.SetError(source.StateDegraded, err)
+}
+
+// closeListeners closes the listeners and all open TCP connections.
+func (s *Source) closeListeners() {
+	s.closeOnce.Do(func() {
+		if s.udpConn != nil {
+			s.udpConn.Close()
+		}
+		if s.tcpLn != nil {
+			s.tcpLn.Close()
+		}
+	})
+	s.conns.CloseAll()
+}
+
+// Stop closes the listeners and waits for all connections to finish.
+//
+// Returns:
+//   - error: Always nil
+func (s *Source) Stop() error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.closeListeners()
+	s.wg.Wait()
+	s.SetState(source.StateStopped)
+	return nil
+}
@@ -0,0 +1,91 @@
+package gelf
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"smart-log-viewer/server/internal/model"
+)
+
+func TestSourceReceivesUDPAndTCP(t *testing.T) {
+	src, err := NewSource("gelf", Config{UDPAddr: "127.0.0.1:0", TCPAddr: "127.0.0.1:0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := make(chan model.Log, 10)
+	if err := src.Start(context.Background(), out); err != nil {
+		t.Fatal(err)
+	}
+	defer src.Stop()
+
+	udp, err := net.Dial("udp", src.udpConn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer udp.Close()
+	for _, datagram := range [][]byte{
+		chunk("AAAAAAAA", 1, 2, `"over udp"}`),
+		chunk("AAAAAAAA", 0, 2, `{"short_message":`),
+	} {
+		if _, err := udp.Write(datagram); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tcp, err := net.Dial("tcp", src.tcpLn.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tcp.Close()
+	if _, err := tcp.Write([]byte("{\"short_message\":\"over tcp\",\"host\":\"web01\"}\x00")); err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]model.Log)
+	for len(got) < 2 {
+		select {
+		case entry := <-out:
+			got[entry.Message] = entry
+		case <-time.After(2 * time.Second):
+			t.Fatalf("received %d of 2 messages", len(got))
+		}
+	}
+	if entry := got["over udp"]; entry.Source != "gelf" || entry.Host != "127.0.0.1" {
+		t.Errorf("udp entry from %q on %q, want gelf and the sender's address", entry.Source, entry.Host)
+	}
+	if entry := got["over tcp"]; entry.Host != "web01" {
+		t.Errorf("tcp entry host = %q, want the message's host", entry.Host)
+	}
+}
+
+func TestStopClosesOpenConnections(t *testing.T) {
+	src, err := NewSource("gelf", Config{TCPAddr: "127.0.0.1:0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := src.Start(context.Background(), make(chan model.Log, 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Idle connections must not keep Stop waiting.
+	for i := 0; i < 5; i++ {
+		conn, err := net.Dial("tcp", src.tcpLn.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		src.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Stop did not return with connections open")
+	}
+}
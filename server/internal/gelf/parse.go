@@ -0,0 +1,238 @@
+package gelf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"smart-log-viewer/server/internal/level"
+	"smart-log-viewer/server/internal/model"
+)
+
+const (
+	// maxChunks is the most chunks a GELF message may be split into.
+	maxChunks = 128
+
+	// maxPendingMessages caps the chunked messages reassembled at once.
+	maxPendingMessages = 1024
+
+	// defaultLevel is the GELF default severity, 1 (alert).
+	defaultLevel = 1
+)
+
+// chunkMagic starts every chunk of a chunked GELF message.
+var chunkMagic = []byte{0x1e, 0x0f}
+
+// errEmptyMessage is returned for payloads that contain no message at all.
+var errEmptyMessage = errors.New("empty GELF message")
+
+// decompress returns the JSON payload of a GELF message, which may be
+// gzip- or zlib-compressed. The result is limited to maxSize bytes.
+//
+// Parameters:
+//   - data: The message as received
+//   - maxSize: The largest accepted decompressed message in bytes
+//
+// Returns:
+//   - []byte: The uncompressed payload
+//   - error: nil on success, or a decompression error
+func decompress(data []byte, maxSize int) ([]byte, error) {
+	var reader io.ReadCloser
+	var err error
+	switch {
+	case len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b:
+		reader, err = gzip.NewReader(bytes.NewReader(data))
+	case len(data) >= 2 && data[0] == 0x78 && (uint16(data[0])<<8|uint16(data[1]))%31 == 0:
+		reader, err = zlib.NewReader(bytes.NewReader(data))
+	default:
+		return data, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid compressed message: %w", err)
+	}
+	defer reader.Close()
+
+	payload, err := io.ReadAll(io.LimitReader(reader, int64(maxSize)+1))
+	if err != nil {
+		return nil, fmt.Errorf("invalid compressed message: %w", err)
+	}
+	if len(payload) > maxSize {
+		return nil, fmt.Errorf("decompressed message exceeds limit of %d bytes", maxSize)
+	}
+	return payload, nil
+}
+
+// parseMessage parses a GELF JSON payload into a log entry. short_message
+// becomes the message, level is a syslog severity, host is the host, and
+// additional fields (prefixed with an underscore) become fields without
+// the underscore.
+//
+// Parameters:
+//   - payload: The uncompressed GELF payload
+//   - received: When the message was received, used when it carries no timestamp
+//
+// Returns:
+//   - model.Log: The parsed log entry
+//   - error: nil on success, or an error if the payload is not a GELF message
+func parseMessage(payload []byte, received time.Time) (model.Log, error) {
+	payload = bytes.TrimSpace(payload)
+	if len(payload) == 0 {
+		return model.Log{}, errEmptyMessage
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	var obj map[string]interface{}
+	if err := decoder.Decode(&obj); err != nil || obj == nil {
+		return model.Log{}, errors.New("GELF message must be a JSON object")
+	}
+
+	message, _ := obj["short_message"].(string)
+	if strings.TrimSpace(message) == "" {
+		return model.Log{}, errors.New("short_message is required")
+	}
+
+	entry := model.Log{
+		Message:   message,
+		Timestamp: received,
+		Fields:    make(map[string]interface{}),
+	}
+
+	severity := defaultLevel
+	if number, ok := obj["level"].(json.Number); ok {
+		if value, err := number.Int64(); err == nil && value >= 0 && value <= 7 {
+			severity = int(value)
+		}
+	}
+	entry.Level = level.FromSyslog(severity)
+	entry.RawLevel = level.SyslogName(severity)
+
+	if number, ok := obj["timestamp"].(json.Number); ok {
+		if seconds, err := number.Float64(); err == nil && seconds > 0 {
+			entry.Timestamp = time.UnixMicro(int64(math.Round(seconds * 1e6)))
+		}
+	}
+
+	for key, value := range obj {
+		switch key {
+		case "version", "short_message", "level", "timestamp":
+		case "host":
+			entry.Host, _ = value.(string)
+		case "full_message", "facility", "file", "line":
+			entry.Fields[key] = value
+		default:
+			// Additional fields start with an underscore; _id is reserved.
+			if name := strings.TrimPrefix(key, "_"); name != key && name != "id" && name != "" {
+				entry.Fields[name] = value
+			}
+		}
+	}
+	return entry, nil
+}
+
+// chunkedMessage collects the chunks of one chunked GELF message.
+type chunkedMessage struct {
+	chunks   [][]byte
+	arrived  []bool // Which chunks arrived; an empty chunk is nil too
+	received int
+	size     int
+	started  time.Time
+}
+
+// assembler reassembles chunked GELF messages. Chunks may arrive in any
+// order; messages that are not complete within the timeout are dropped.
+// It is used by a single goroutine and needs no locking.
+type assembler struct {
+	timeout time.Duration
+	maxSize int
+	pending map[string]*chunkedMessage
+}
+
+// newAssembler creates a chunk assembler.
+//
+// Parameters:
+//   - timeout: How long to wait for the remaining chunks of a message
+//   - maxSize: The largest accepted reassembled message in bytes
+//
+// Returns:
+//   - *assembler: A new assembler
+func newAssembler(timeout time.Duration, maxSize int) *assembler {
+	return &assembler{
+		timeout: timeout,
+		maxSize: maxSize,
+		pending: make(map[string]*chunkedMessage),
+	}
+}
+
+// add processes one datagram. Datagrams that are not chunks are returned
+// unchanged; chunks are stored until their message is complete.
+//
+// Parameters:
+//   - datagram: The datagram as received
+//   - now: The current time, used to expire incomplete messages
+//
+// Returns:
+//   - []byte: The complete message, or nil while chunks are missing
+//   - error: nil on success, or an error for malformed or oversized chunks
+func (a *assembler) add(datagram []byte, now time.Time) ([]byte, error) {
+	if !bytes.HasPrefix(datagram, chunkMagic) {
+		return datagram, nil
+	}
+	if len(datagram) < 12 {
+		return nil, errors.New("truncated GELF chunk header")
+	}
+
+	id := string(datagram[2:10])
+	sequence, count := int(datagram[10]), int(datagram[11])
+	if count == 0 || count > maxChunks || sequence >= count {
+		return nil, fmt.Errorf("invalid GELF chunk %d of %d", sequence, count)
+	}
+
+	a.expire(now)
+	msg, ok := a.pending[id]
+	if !ok {
+		if len(a.pending) >= maxPendingMessages {
+			return nil, errors.New("too many incomplete GELF messages")
+		}
+		msg = &chunkedMessage{chunks: make([][]byte, count), arrived: make([]bool, count), started: now}
+		a.pending[id] = msg
+	}
+	if len(msg.chunks) != count {
+		delete(a.pending, id)
+		return nil, errors.New("GELF chunks disagree on the chunk count")
+	}
+	if msg.arrived[sequence] {
+		return nil, nil
+	}
+
+	msg.chunks[sequence] = append([]byte(nil), datagram[12:]...)
+	msg.arrived[sequence] = true
+	msg.received++
+	msg.size += len(datagram) - 12
+	if msg.size > a.maxSize {
+		delete(a.pending, id)
+		return nil, fmt.Errorf("chunked message exceeds limit of %d bytes", a.maxSize)
+	}
+	if msg.received < count {
+		return nil, nil
+	}
+
+	delete(a.pending, id)
+	return bytes.Join(msg.chunks, nil), nil
+}
+
+// expire drops messages whose chunks did not all arrive in time.
+func (a *assembler) expire(now time.Time) {
+	for id, msg := range a.pending {
+		if now.Sub(msg.started) > a.timeout {
+			delete(a.pending, id)
+		}
+	}
+}
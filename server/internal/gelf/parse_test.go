@@ -0,0 +1,296 @@
+package gelf
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"smart-log-viewer/server/internal/model"
+)
+
+// chunk builds a chunked GELF datagram.
+func chunk(id string, sequence, count byte, payload string) []byte {
+	datagram := append([]byte{0x1e, 0x0f}, id...)
+	datagram = append(datagram, sequence, count)
+	return append(datagram, payload...)
+}
+
+func TestAssembler(t *testing.T) {
+	tests := []struct {
+		name      string
+		datagrams [][]byte
+		maxSize   int
+		want      []string
+		wantErrs  []string
+	}{
+		{
+			name:      "unchunked datagram",
+			datagrams: [][]byte{[]byte(`{"short_message":"a"}`)},
+			want:      []string{`{"short_message":"a"}`},
+		},
+		{
+			name:      "chunks in order",
+			datagrams: [][]byte{chunk("AAAAAAAA", 0, 2, `{"short_`), chunk("AAAAAAAA", 1, 2, `message":"a"}`)},
+			want:      []string{"", `{"short_message":"a"}`},
+		},
+		{
+			name:      "chunks out of order and interleaved",
+			datagrams: [][]byte{chunk("AAAAAAAA", 2, 3, "3"), chunk("BBBBBBBB", 1, 2, "y"), chunk("AAAAAAAA", 0, 3, "1"), chunk("BBBBBBBB", 0, 2, "x"), chunk("AAAAAAAA", 1, 3, "2")},
+			want:      []string{"", "", "", "xy", "123"},
+		},
+		{
+			name:      "duplicate chunk is ignored",
+			datagrams: [][]byte{chunk("AAAAAAAA", 0, 2, "a"), chunk("AAAAAAAA", 0, 2, "z"), chunk("AAAAAAAA", 1, 2, "b")},
+			want:      []string{"", "", "ab"},
+		},
+		{
+			name:      "duplicate empty chunk counts once",
+			datagrams: [][]byte{chunk("AAAAAAAA", 0, 3, ""), chunk("AAAAAAAA", 0, 3, ""), chunk("AAAAAAAA", 1, 3, "b"), chunk("AAAAAAAA", 2, 3, "c")},
+			want:      []string{"", "", "", "bc"},
+		},
+		{
+			name:      "truncated header",
+			datagrams: [][]byte{{0x1e, 0x0f, 1, 2, 3}},
+			want:      []string{""},
+			wantErrs:  []string{"truncated GELF chunk header"},
+		},
+		{
+			name:      "sequence out of range",
+			datagrams: [][]byte{chunk("AAAAAAAA", 2, 2, "x")},
+			want:      []string{""},
+			wantErrs:  []string{"invalid GELF chunk 2 of 2"},
+		},
+		{
+			name:      "zero chunks",
+			datagrams: [][]byte{chunk("AAAAAAAA", 0, 0, "x")},
+			want:      []string{""},
+			wantErrs:  []string{"invalid GELF chunk 0 of 0"},
+		},
+		{
+			name:      "too many chunks",
+			datagrams: [][]byte{chunk("AAAAAAAA", 0, maxChunks+1, "x")},
+			want:      []string{""},
+			wantErrs:  []string{"invalid GELF chunk 0 of 129"},
+		},
+		{
+			name:      "chunk counts disagree",
+			datagrams: [][]byte{chunk("AAAAAAAA", 0, 2, "a"), chunk("AAAAAAAA", 1, 3, "b"), chunk("AAAAAAAA", 1, 2, "c")},
+			want:      []string{"", "", ""},
+			wantErrs:  []string{"", "disagree on the chunk count", ""},
+		},
+		{
+			name:      "reassembled message too large",
+			datagrams: [][]byte{chunk("AAAAAAAA", 0, 2, "12345"), chunk("AAAAAAAA", 1, 2, "67890")},
+			maxSize:   8,
+			want:      []string{"", ""},
+			wantErrs:  []string{"", "exceeds limit of 8 bytes"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxSize := tt.maxSize
+			if maxSize == 0 {
+				maxSize = defaultMaxMessageSize
+			}
+			a := newAssembler(time.Second, maxSize)
+			now := time.Now()
+
+			var got, errs []string
+			for _, datagram := range tt.datagrams {
+				message, err := a.add(datagram, now)
+				got = append(got, string(message))
+				if err != nil {
+					errs = append(errs, err.Error())
+				} else {
+					errs = append(errs, "")
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("messages = %q, want %q", got, tt.want)
+			}
+			for i, want := range tt.wantErrs {
+				if !strings.Contains(errs[i], want) || (want == "" && errs[i] != "") {
+					t.Errorf("datagram %d: error = %q, want %q", i, errs[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestAssemblerExpiresIncompleteMessages(t *testing.T) {
+	a := newAssembler(time.Second, defaultMaxMessageSize)
+	now := time.Now()
+
+	if _, err := a.add(chunk("AAAAAAAA", 0, 2, "old"), now); err != nil {
+		t.Fatal(err)
+	}
+	// The remaining chunk arrives too late and starts a new message.
+	message, err := a.add(chunk("AAAAAAAA", 1, 2, "late"), now.Add(2*time.Second))
+	if err != nil || message != nil {
+		t.Fatalf("add() = %q, %v, want nothing for an expired message", message, err)
+	}
+	if len(a.pending) != 1 {
+		t.Errorf("%d pending messages, want only the new one", len(a.pending))
+	}
+}
+
+func TestDecompress(t *testing.T) {
+	payload := `{"short_message":"zipped"}`
+
+	var gz, zl bytes.Buffer
+	gw := gzip.NewWriter(&gz)
+	gw.Write([]byte(payload))
+	gw.Close()
+	zw := zlib.NewWriter(&zl)
+	zw.Write([]byte(payload))
+	zw.Close()
+
+	tests := []struct {
+		name    string
+		data    []byte
+		maxSize int
+		want    string
+		wantErr string
+	}{
+		{name: "plain", data: []byte(payload), want: payload},
+		{name: "gzip", data: gz.Bytes(), want: payload},
+		{name: "zlib", data: zl.Bytes(), want: payload},
+		{name: "truncated gzip", data: gz.Bytes()[:gz.Len()-6], wantErr: "invalid compressed message"},
+		{name: "truncated zlib", data: zl.Bytes()[:4], wantErr: "invalid compressed message"},
+		{name: "gzip past the limit", data: gz.Bytes(), maxSize: 10, wantErr: "exceeds limit of 10 bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxSize := tt.maxSize
+			if maxSize == 0 {
+				maxSize = defaultMaxMessageSize
+			}
+			got, err := decompress(tt.data, maxSize)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("decompress() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("decompress() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMessage(t *testing.T) {
+	received := time.Date(2024, time.March, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		payload string
+		want    model.Log
+		wantErr string
+	}{
+		{
+			name:    "full message",
+			payload: `{"version":"1.1","host":"web01","short_message":"boom","full_message":"boom\nat x","timestamp":1710069753.25,"level":3,"_user":"ann","_id":"x","line":12}`,
+			want: model.Log{
+				Level: "ERROR", RawLevel: "err", Message: "boom", Host: "web01",
+				Timestamp: time.UnixMilli(1710069753250),
+				Fields:    map[string]interface{}{"full_message": "boom\nat x", "user": "ann", "line": json.Number("12")},
+			},
+		},
+		{
+			name:    "defaults",
+			payload: ` {"short_message":"m","_":"ignored","extra":"ignored"} `,
+			want:    model.Log{Level: "CRITICAL", RawLevel: "alert", Message: "m", Timestamp: received, Fields: map[string]interface{}{}},
+		},
+		{
+			name:    "unusable level and timestamp",
+			payload: `{"short_message":"m","level":9,"timestamp":"soon"}`,
+			want:    model.Log{Level: "CRITICAL", RawLevel: "alert", Message: "m", Timestamp: received, Fields: map[string]interface{}{}},
+		},
+		{name: "empty", payload: " \n", wantErr: "empty GELF message"},
+		{name: "not an object", payload: `["m"]`, wantErr: "must be a JSON object"},
+		{name: "truncated", payload: `{"short_message":"m`, wantErr: "must be a JSON object"},
+		{name: "missing short message", payload: `{"full_message":"m"}`, wantErr: "short_message is required"},
+		{name: "blank short message", payload: `{"short_message":" "}`, wantErr: "short_message is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseMessage([]byte(tt.payload), received)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseMessage() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Timestamp.Equal(tt.want.Timestamp) {
+				t.Errorf("Timestamp = %v, want %v", got.Timestamp, tt.want.Timestamp)
+			}
+			got.Timestamp, tt.want.Timestamp = time.Time{}, time.Time{}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseMessage() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadFrame(t *testing.T) {
+	tests := []struct {
+		name    string
+		stream  string
+		maxSize int
+		want    []string
+		wantErr string
+	}{
+		{name: "null delimited", stream: "a\x00bc\x00", want: []string{"a", "bc"}},
+		{name: "last frame without delimiter", stream: "a\x00bc", want: []string{"a", "bc"}},
+		{name: "frame longer than the buffer", stream: strings.Repeat("x", 40) + "\x00", want: []string{strings.Repeat("x", 40)}},
+		{name: "frame over limit", stream: strings.Repeat("x", 40) + "\x00", maxSize: 20, wantErr: "exceeds limit of 20 bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxSize := tt.maxSize
+			if maxSize == 0 {
+				maxSize = defaultMaxMessageSize
+			}
+			reader := bufio.NewReaderSize(strings.NewReader(tt.stream), 16)
+
+			var got []string
+			var err error
+			for {
+				var frame []byte
+				if frame, err = readFrame(reader, maxSize); err != nil {
+					break
+				}
+				got = append(got, string(frame))
+			}
+			if tt.wantErr != "" {
+				if !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("readFrame() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err.Error() != "EOF" {
+				t.Fatalf("readFrame() error = %v, want EOF", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("frames = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
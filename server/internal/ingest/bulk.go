@@ -0,0 +1,182 @@
+package ingest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"smart-log-viewer/server/internal/document"
+	"smart-log-viewer/server/internal/model"
+)
+
+// bulkError describes a failed bulk item or request, as Elasticsearch does.
+type bulkError struct {
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+// bulkItemResult is the outcome of one bulk action.
+type bulkItemResult struct {
+	Index  string     `json:"_index,omitempty"`
+	ID     string     `json:"_id,omitempty"`
+	Status int        `json:"status"`
+	Result string     `json:"result,omitempty"`
+	Error  *bulkError `json:"error,omitempty"`
+}
+
+// bulkResponse is the JSON body returned by the bulk endpoint.
+type bulkResponse struct {
+	Took   int64                       `json:"took"`
+	Errors bool                        `json:"errors"`
+	Items  []map[string]bulkItemResult `json:"items"`
+}
+
+// bulkRequestError is the body of a bulk request that failed as a whole.
+type bulkRequestError struct {
+	Error  bulkError `json:"error"`
+	Status int       `json:"status"`
+}
+
+// newDocumentID returns a random ID for a document pushed without one.
+func newDocumentID() string {
+	var id [15]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return fmt.Sprint(time.Now().UnixNano())
+	}
+	return base64.RawURLEncoding.EncodeToString(id[:])
+}
+
+// HandleBulk handles POST /_bulk and POST /{index}/_bulk, the Elasticsearch
+// bulk API. The body is newline-delimited JSON of action and document lines,
+// optionally gzip-encoded. Documents of index and create actions become log
+// entries; updates and deletes are acknowledged without effect, since logs
+// are never changed once shown.
+//
+// Parameters:
+//   - w: HTTP response writer
+//   - req: The bulk request
+func (r *Receiver) HandleBulk(w http.ResponseWriter, req *http.Request) {
+	started := time.Now()
+	w.Header().Set("X-Elastic-Product", "Elasticsearch")
+
+	if req.Method != http.MethodPost && req.Method != http.MethodPut {
+		w.Header().Set("Allow", "POST, PUT")
+		writeBulkError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	body, err := r.readBody(w, req)
+	if err != nil {
+		writeBulkError(w, bodyErrorStatus(err), err.Error())
+		return
+	}
+
+	var lines [][]byte
+	for _, line := range bytes.Split(body, []byte{'\n'}) {
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		writeBulkError(w, http.StatusBadRequest, "request body is required")
+		return
+	}
+
+	resp := bulkResponse{Items: []map[string]bulkItemResult{}}
+	var entries []model.Log
+	for i := 0; i < len(lines); i++ {
+		var action map[string]struct {
+			Index string `json:"_index"`
+			ID    string `json:"_id"`
+		}
+		if err := json.Unmarshal(lines[i], &action); err != nil || len(action) != 1 {
+			writeBulkError(w, http.StatusBadRequest, fmt.Sprintf("malformed action/metadata line [%d]", i+1))
+			return
+		}
+
+		for op, meta := range action {
+			result := bulkItemResult{Index: meta.Index, ID: meta.ID}
+			if result.Index == "" {
+				result.Index = req.PathValue("index")
+			}
+
+			switch op {
+			case "index", "create", "update":
+				i++
+				if i >= len(lines) {
+					writeBulkError(w, http.StatusBadRequest, fmt.Sprintf("missing document for action line [%d]", i))
+					return
+				}
+			case "delete":
+			default:
+				writeBulkError(w, http.StatusBadRequest, fmt.Sprintf("unknown bulk action %q", op))
+				return
+			}
+
+			switch op {
+			case "index", "create":
+				entry, err := bulkDocument(lines[i], result.Index)
+				if err != nil {
+					result.Status = http.StatusBadRequest
+					result.Error = &bulkError{Type: "document_parsing_exception", Reason: err.Error()}
+					resp.Errors = true
+					break
+				}
+				entries = append(entries, entry)
+				if result.ID == "" {
+					result.ID = newDocumentID()
+				}
+				result.Status, result.Result = http.StatusCreated, "created"
+			case "update":
+				result.Status, result.Result = http.StatusOK, "noop"
+			case "delete":
+				result.Status, result.Result = http.StatusNotFound, "not_found"
+			}
+			resp.Items = append(resp.Items, map[string]bulkItemResult{op: result})
+		}
+	}
+
+	sent, err := r.emit(req.Context(), entries)
+	if err != nil {
+		writeBulkError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	log.Printf("Ingest %s: accepted %d", req.URL.Path, sent)
+
+	resp.Took = time.Since(started).Milliseconds()
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// bulkDocument converts one bulk document into a log entry.
+// The index name is kept as the index field.
+func bulkDocument(raw []byte, index string) (model.Log, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var doc map[string]interface{}
+	if err := decoder.Decode(&doc); err != nil || doc == nil {
+		return model.Log{}, fmt.Errorf("document must be a JSON object")
+	}
+
+	entry := document.Log(doc)
+	if index != "" {
+		if entry.Fields == nil {
+			entry.Fields = make(map[string]interface{})
+		}
+		entry.Fields["index"] = index
+	}
+	return entry, nil
+}
+
+// writeBulkError writes an Elasticsearch style error for a failed request.
+func writeBulkError(w http.ResponseWriter, status int, reason string) {
+	writeJSON(w, status, bulkRequestError{
+		Error:  bulkError{Type: "illegal_argument_exception", Reason: reason},
+		Status: status,
+	})
+}
@@ -0,0 +1,129 @@
+package ingest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"smart-log-viewer/server/internal/config"
+)
+
+func TestHandleBulk(t *testing.T) {
+	tests := []struct {
+		name        string
+		pathIndex   string
+		body        string
+		wantStatus  int
+		wantErrors  bool
+		wantItems   []string
+		wantReason  string
+		wantIndex   string
+		wantEmitted int
+	}{
+		{
+			name: "index and create",
+			body: `{"index":{"_index":"logs","_id":"1"}}
+{"message":"a","level":"warn"}
+{"create":{}}
+{"msg":"b"}
+`,
+			wantStatus:  http.StatusOK,
+			wantItems:   []string{"index 201 created", "create 201 created"},
+			wantEmitted: 2,
+		},
+		{
+			name: "update and delete are acknowledged",
+			body: `{"update":{"_id":"1"}}
+{"doc":{"message":"x"}}
+{"delete":{"_id":"2"}}`,
+			wantStatus: http.StatusOK,
+			wantItems:  []string{"update 200 noop", "delete 404 not_found"},
+		},
+		{
+			name: "invalid document",
+			body: `{"index":{}}
+[1,2]
+{"index":{}}
+{"message":"ok"}`,
+			wantStatus:  http.StatusOK,
+			wantErrors:  true,
+			wantItems:   []string{"index 400 ", "index 201 created"},
+			wantEmitted: 1,
+		},
+		{
+			name:        "index from the path",
+			pathIndex:   "logs",
+			body:        "{\"index\":{}}\n{\"message\":\"a\"}",
+			wantStatus:  http.StatusOK,
+			wantItems:   []string{"index 201 created"},
+			wantIndex:   "logs",
+			wantEmitted: 1,
+		},
+		{name: "empty", body: "\n\n", wantStatus: http.StatusBadRequest, wantReason: "request body is required"},
+		{name: "malformed action", body: `{"index":`, wantStatus: http.StatusBadRequest, wantReason: "malformed action/metadata line [1]"},
+		{name: "two actions in a line", body: `{"index":{},"create":{}}`, wantStatus: http.StatusBadRequest, wantReason: "malformed action/metadata line [1]"},
+		{name: "unknown action", body: `{"upsert":{}}`, wantStatus: http.StatusBadRequest, wantReason: `unknown bulk action "upsert"`},
+		{name: "truncated before the document", body: `{"index":{}}`, wantStatus: http.StatusBadRequest, wantReason: "missing document for action line [1]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver, out := startReceiver(t, config.IngestConfig{})
+
+			req := httptest.NewRequest(http.MethodPost, "/_bulk", strings.NewReader(tt.body))
+			req.SetPathValue("index", tt.pathIndex)
+			rec := httptest.NewRecorder()
+			receiver.HandleBulk(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if rec.Header().Get("X-Elastic-Product") != "Elasticsearch" {
+				t.Error("missing X-Elastic-Product header")
+			}
+
+			if tt.wantReason != "" {
+				var resp bulkRequestError
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("invalid response %q: %v", rec.Body.String(), err)
+				}
+				if resp.Error.Reason != tt.wantReason {
+					t.Errorf("reason = %q, want %q", resp.Error.Reason, tt.wantReason)
+				}
+				return
+			}
+
+			var resp bulkResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("invalid response %q: %v", rec.Body.String(), err)
+			}
+			if resp.Errors != tt.wantErrors {
+				t.Errorf("errors = %v, want %v", resp.Errors, tt.wantErrors)
+			}
+			var items []string
+			for _, item := range resp.Items {
+				for op, result := range item {
+					items = append(items, fmt.Sprintf("%s %d %s", op, result.Status, result.Result))
+					if result.Status == http.StatusCreated && result.ID == "" {
+						t.Errorf("%s item has no ID", op)
+					}
+				}
+			}
+			if strings.Join(items, "|") != strings.Join(tt.wantItems, "|") {
+				t.Errorf("items = %q, want %q", items, tt.wantItems)
+			}
+			entries := drain(out)
+			if len(entries) != tt.wantEmitted {
+				t.Errorf("emitted %d entries, want %d", len(entries), tt.wantEmitted)
+			}
+			for _, entry := range entries {
+				if index, _ := entry.Fields["index"].(string); tt.wantIndex != "" && index != tt.wantIndex {
+					t.Errorf("index field = %q, want %q", index, tt.wantIndex)
+				}
+			}
+		})
+	}
+}
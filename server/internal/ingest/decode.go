@@ -0,0 +1,158 @@
+package ingest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"smart-log-viewer/server/internal/level"
+	"smart-log-viewer/server/internal/model"
+)
+
+// splitDocuments splits an ingest body into individual JSON documents.
+// A body that is one valid JSON value is either an array of logs or a single
+// log; anything else is treated as newline-delimited JSON, one log per line.
+//
+// Parameters:
+//   - body: The decompressed request body
+//
+// Returns:
+//   - [][]byte: The raw JSON documents, one per log entry
+func splitDocuments(body []byte) [][]byte {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return nil
+	}
+
+	if json.Valid(trimmed) {
+		if trimmed[0] != '[' {
+			return [][]byte{trimmed}
+		}
+		var items []json.RawMessage
+		if err := json.Unmarshal(trimmed, &items); err == nil {
+			docs := make([][]byte, len(items))
+			for i, item := range items {
+				docs[i] = item
+			}
+			return docs
+		}
+	}
+
+	var docs [][]byte
+	for _, line := range bytes.Split(trimmed, []byte{'\n'}) {
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			docs = append(docs, line)
+		}
+	}
+	return docs
+}
+
+// decodeLog validates one JSON document and converts it into a log entry.
+// The known keys level, message, timestamp, source, host and fields map
+// onto the log model; any other key is kept as a structured field.
+//
+// Parameters:
+//   - raw: The JSON document
+//
+// Returns:
+//   - model.Log: The decoded log entry
+//   - error: nil on success, or a validation error
+func decodeLog(raw []byte) (model.Log, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var obj map[string]interface{}
+	if err := decoder.Decode(&obj); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return model.Log{}, errors.New("entry must be a JSON object")
+		}
+		return model.Log{}, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if obj == nil {
+		return model.Log{}, errors.New("entry must be a JSON object")
+	}
+
+	entry := model.Log{
+		Level:  level.Info,
+		Fields: make(map[string]interface{}),
+	}
+
+	message, ok := obj["message"].(string)
+	if !ok || strings.TrimSpace(message) == "" {
+		return model.Log{}, errors.New("message is required and must be a non-empty string")
+	}
+	entry.Message = message
+
+	if value, present := obj["level"]; present {
+		switch v := value.(type) {
+		case string:
+			if !level.Apply(&entry, v) {
+				return model.Log{}, fmt.Errorf("unknown level %q", v)
+			}
+		case json.Number:
+			// A number is a syslog severity.
+			severity, err := v.Int64()
+			if err != nil || severity < 0 || severity > 7 {
+				return model.Log{}, fmt.Errorf("level %s is not a syslog severity from 0 to 7", v)
+			}
+			entry.Level = level.FromSyslog(int(severity))
+			entry.RawLevel = v.String()
+		default:
+			return model.Log{}, errors.New("level must be a string or a syslog severity number")
+		}
+	}
+
+	if value, present := obj["timestamp"]; present {
+		text, ok := value.(string)
+		if !ok {
+			return model.Log{}, errors.New("timestamp must be an RFC 3339 string")
+		}
+		timestamp, err := time.Parse(time.RFC3339Nano, text)
+		if err != nil {
+			return model.Log{}, fmt.Errorf("invalid timestamp %q", text)
+		}
+		entry.Timestamp = timestamp
+	}
+
+	if value, present := obj["source"]; present {
+		text, ok := value.(string)
+		if !ok {
+			return model.Log{}, errors.New("source must be a string")
+		}
+		entry.Source = text
+	}
+
+	if value, present := obj["host"]; present {
+		text, ok := value.(string)
+		if !ok {
+			return model.Log{}, errors.New("host must be a string")
+		}
+		entry.Host = text
+	}
+
+	if value, present := obj["fields"]; present {
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return model.Log{}, errors.New("fields must be a JSON object")
+		}
+		for key, v := range fields {
+			entry.Fields[key] = v
+		}
+	}
+
+	for key, value := range obj {
+		switch key {
+		case "level", "message", "timestamp", "source", "host", "fields":
+		default:
+			entry.Fields[key] = value
+		}
+	}
+	if len(entry.Fields) == 0 {
+		entry.Fields = nil
+	}
+	return entry, nil
+}
@@ -0,0 +1,117 @@
+package ingest
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"smart-log-viewer/server/internal/model"
+)
+
+func TestSplitDocuments(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{name: "empty", body: " \n "},
+		{name: "single object", body: ` {"message":"a"} `, want: []string{`{"message":"a"}`}},
+		{name: "array", body: `[{"message":"a"}, {"message":"b"}]`, want: []string{`{"message":"a"}`, `{"message":"b"}`}},
+		{name: "empty array", body: `[]`, want: []string{}},
+		{
+			name: "newline delimited",
+			body: "{\"message\":\"a\"}\r\n\n{\"message\":\"b\"}\n",
+			want: []string{`{"message":"a"}`, `{"message":"b"}`},
+		},
+		{
+			name: "newline delimited with a truncated line",
+			body: "{\"message\":\"a\"}\n{\"message\":",
+			want: []string{`{"message":"a"}`, `{"message":`},
+		},
+		{name: "truncated array", body: `[{"message":"a"}`, want: []string{`[{"message":"a"}`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, doc := range splitDocuments([]byte(tt.body)) {
+				got = append(got, string(doc))
+			}
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitDocuments() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeLog(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    model.Log
+		wantErr string
+	}{
+		{
+			name: "message only",
+			raw:  `{"message":"hello"}`,
+			want: model.Log{Level: "INFO", Message: "hello"},
+		},
+		{
+			name: "all known keys and extra fields",
+			raw:  `{"message":"m","level":"warning","timestamp":"2024-03-10T11:22:33.5+01:00","source":"api","host":"web01","fields":{"user":"ann"},"status":200}`,
+			want: model.Log{
+				Level: "WARN", RawLevel: "warning", Message: "m", Source: "api", Host: "web01",
+				Timestamp: time.Date(2024, time.March, 10, 10, 22, 33, 5e8, time.UTC),
+				Fields:    map[string]interface{}{"user": "ann", "status": json.Number("200")},
+			},
+		},
+		{
+			name: "syslog severity number",
+			raw:  `{"message":"m","level":3}`,
+			want: model.Log{Level: "ERROR", RawLevel: "3", Message: "m"},
+		},
+		{name: "not JSON", raw: `message=hello`, wantErr: "invalid JSON"},
+		{name: "truncated", raw: `{"message":"hel`, wantErr: "invalid JSON"},
+		{name: "array", raw: `["hello"]`, wantErr: "must be a JSON object"},
+		{name: "null", raw: `null`, wantErr: "must be a JSON object"},
+		{name: "missing message", raw: `{"level":"info"}`, wantErr: "message is required"},
+		{name: "blank message", raw: `{"message":"  "}`, wantErr: "message is required"},
+		{name: "message not a string", raw: `{"message":1}`, wantErr: "message is required"},
+		{name: "unknown level", raw: `{"message":"m","level":"loud"}`, wantErr: `unknown level "loud"`},
+		{name: "severity out of range", raw: `{"message":"m","level":9}`, wantErr: "not a syslog severity"},
+		{name: "fractional severity", raw: `{"message":"m","level":2.5}`, wantErr: "not a syslog severity"},
+		{name: "level of wrong type", raw: `{"message":"m","level":true}`, wantErr: "level must be a string"},
+		{name: "timestamp not a string", raw: `{"message":"m","timestamp":1710069753}`, wantErr: "timestamp must be"},
+		{name: "invalid timestamp", raw: `{"message":"m","timestamp":"yesterday"}`, wantErr: "invalid timestamp"},
+		{name: "source not a string", raw: `{"message":"m","source":1}`, wantErr: "source must be"},
+		{name: "host not a string", raw: `{"message":"m","host":[]}`, wantErr: "host must be"},
+		{name: "fields not an object", raw: `{"message":"m","fields":"x"}`, wantErr: "fields must be"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := decodeLog([]byte(tt.raw))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("decodeLog() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeLog() error = %v", err)
+			}
+			if !entry.Timestamp.Equal(tt.want.Timestamp) {
+				t.Errorf("Timestamp = %v, want %v", entry.Timestamp, tt.want.Timestamp)
+			}
+			entry.Timestamp, tt.want.Timestamp = time.Time{}, time.Time{}
+			if !reflect.DeepEqual(entry, tt.want) {
+				t.Errorf("decodeLog() = %+v, want %+v", entry, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package ingest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"smart-log-viewer/server/internal/document"
+	"smart-log-viewer/server/internal/level"
+	"smart-log-viewer/server/internal/model"
+	"smart-log-viewer/server/internal/timestamp"
+)
+
+// hecResponse is the JSON body returned by the Splunk HEC endpoints.
+// Text and Code follow Splunk's status codes so shippers report them.
+type hecResponse struct {
+	Text string `json:"text"`
+	Code int    `json:"code"`
+
+	// InvalidEventNumber is the zero-based index of the first bad event.
+	InvalidEventNumber *int `json:"invalid-event-number,omitempty"`
+}
+
+// hecEvent is one event of a Splunk HEC request.
+type hecEvent struct {
+	// Time is the event time in epoch seconds, as a number or string.
+	Time       interface{}            `json:"time"`
+	Host       string                 `json:"host"`
+	Source     string                 `json:"source"`
+	Sourcetype string                 `json:"sourcetype"`
+	Index      string                 `json:"index"`
+	Event      interface{}            `json:"event"`
+	Fields     map[string]interface{} `json:"fields"`
+}
+
+// log converts the event into a log entry. A string event is the message;
+// an object event is mapped like an Elasticsearch document.
+func (e hecEvent) log() model.Log {
+	var entry model.Log
+	switch event := e.Event.(type) {
+	case map[string]interface{}:
+		entry = document.Log(event)
+	case string:
+		entry = model.Log{Level: level.Info, Message: event}
+	default:
+		text, _ := json.Marshal(event)
+		entry = model.Log{Level: level.Info, Message: string(text)}
+	}
+
+	if t, ok := timestamp.Value(e.Time); ok {
+		entry.Timestamp = t
+	}
+	entry.Source = e.Source
+	if e.Host != "" {
+		entry.Host = e.Host
+	}
+
+	if entry.Fields == nil {
+		entry.Fields = make(map[string]interface{})
+	}
+	for key, value := range e.Fields {
+		entry.Fields[key] = value
+	}
+	setField(entry.Fields, "sourcetype", e.Sourcetype)
+	setField(entry.Fields, "index", e.Index)
+	if len(entry.Fields) == 0 {
+		entry.Fields = nil
+	}
+	return entry
+}
+
+// checkHECToken validates the "Authorization: Splunk <token>" header.
+// Without configured tokens any token is accepted.
+//
+// Parameters:
+//   - req: The HEC request
+//
+// Returns:
+//   - *hecResponse: nil if the request is authorized, otherwise the error response
+func (r *Receiver) checkHECToken(req *http.Request) *hecResponse {
+	header := req.Header.Get("Authorization")
+	if header == "" {
+		return &hecResponse{Text: "Token is required", Code: 2}
+	}
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Splunk") {
+		return &hecResponse{Text: "Invalid authorization", Code: 3}
+	}
+	if len(r.hecTokens) > 0 && !r.hecTokens[strings.TrimSpace(token)] {
+		return &hecResponse{Text: "Invalid token", Code: 4}
+	}
+	return nil
+}
+
+// HandleHECEvent handles POST /services/collector/event, the Splunk HTTP
+// Event Collector endpoint. The body holds one or more concatenated JSON
+// events, optionally gzip-encoded. As with Splunk, events before the first
+// invalid one are still accepted.
+//
+// Parameters:
+//   - w: HTTP response writer
+//   - req: The HEC request
+func (r *Receiver) HandleHECEvent(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, hecResponse{Text: "Method not allowed", Code: 6})
+		return
+	}
+	if resp := r.checkHECToken(req); resp != nil {
+		writeJSON(w, http.StatusUnauthorized, resp)
+		return
+	}
+
+	body, err := r.readBody(w, req)
+	if err != nil {
+		writeJSON(w, bodyErrorStatus(err), hecResponse{Text: err.Error(), Code: 6})
+		return
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var entries []model.Log
+	var invalid *hecResponse
+	for index := 0; ; index++ {
+		var event hecEvent
+		err := decoder.Decode(&event)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		switch {
+		case err != nil:
+			invalid = &hecResponse{Text: "Invalid data format", Code: 6}
+		case event.Event == nil:
+			invalid = &hecResponse{Text: "Event field is required", Code: 12}
+		case event.Event == "":
+			invalid = &hecResponse{Text: "Event field cannot be blank", Code: 13}
+		}
+		if invalid != nil {
+			invalid.InvalidEventNumber = &index
+			break
+		}
+		entries = append(entries, event.log())
+	}
+	if len(entries) == 0 && invalid == nil {
+		writeJSON(w, http.StatusBadRequest, hecResponse{Text: "No data", Code: 5})
+		return
+	}
+
+	sent, err := r.emit(req.Context(), entries)
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, hecResponse{Text: "Server is busy", Code: 9})
+		return
+	}
+	log.Printf("Ingest %s: accepted %d", req.URL.Path, sent)
+
+	if invalid != nil {
+		writeJSON(w, http.StatusBadRequest, invalid)
+		return
+	}
+	writeJSON(w, http.StatusOK, hecResponse{Text: "Success", Code: 0})
+}
+
+// HandleHECHealth handles GET /services/collector/health, which shippers
+// such as Vector call before sending.
+//
+// Parameters:
+//   - w: HTTP response writer
+//   - req: The health request
+func (r *Receiver) HandleHECHealth(w http.ResponseWriter, req *http.Request) {
+	if !r.running() {
+		writeJSON(w, http.StatusServiceUnavailable, hecResponse{Text: "HEC is unhealthy", Code: 9})
+		return
+	}
+	writeJSON(w, http.StatusOK, hecResponse{Text: "HEC is healthy", Code: 17})
+}
@@ -0,0 +1,176 @@
+package ingest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"smart-log-viewer/server/internal/config"
+	"smart-log-viewer/server/internal/model"
+)
+
+func TestHECEventLog(t *testing.T) {
+	tests := []struct {
+		name  string
+		event string
+		want  model.Log
+	}{
+		{
+			name:  "string event with metadata",
+			event: `{"time":1710069753.25,"host":"web01","source":"/var/log/app.log","sourcetype":"app","index":"main","event":"hello","fields":{"env":"prod"}}`,
+			want: model.Log{
+				Level: "INFO", Message: "hello", Host: "web01", Source: "/var/log/app.log",
+				Timestamp: time.UnixMilli(1710069753250),
+				Fields:    map[string]interface{}{"env": "prod", "sourcetype": "app", "index": "main"},
+			},
+		},
+		{
+			name:  "string time",
+			event: `{"time":"1710069753","event":"hello"}`,
+			want:  model.Log{Level: "INFO", Message: "hello", Timestamp: time.Unix(1710069753, 0)},
+		},
+		{
+			name:  "object event",
+			event: `{"event":{"message":"boom","level":"error","host":"db01","code":7}}`,
+			want: model.Log{
+				Level: "ERROR", RawLevel: "error", Message: "boom", Host: "db01",
+				Fields: map[string]interface{}{"code": json.Number("7")},
+			},
+		},
+		{
+			name:  "event host wins over document host",
+			event: `{"host":"web01","event":{"message":"m","host":"db01"}}`,
+			want:  model.Log{Level: "INFO", Message: "m", Host: "web01"},
+		},
+		{
+			name:  "other event types are shown as JSON",
+			event: `{"event":[1,"two"]}`,
+			want:  model.Log{Level: "INFO", Message: `[1,"two"]`},
+		},
+		{
+			name:  "unusable times are left to the receiver",
+			event: `{"time":"NaN","event":"a"}`,
+			want:  model.Log{Level: "INFO", Message: "a"},
+		},
+		{
+			name:  "infinite time",
+			event: `{"time":"-Inf","event":"a"}`,
+			want:  model.Log{Level: "INFO", Message: "a"},
+		},
+		{
+			name:  "time not a number",
+			event: `{"time":true,"event":"a"}`,
+			want:  model.Log{Level: "INFO", Message: "a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder := json.NewDecoder(strings.NewReader(tt.event))
+			decoder.UseNumber()
+			var event hecEvent
+			if err := decoder.Decode(&event); err != nil {
+				t.Fatal(err)
+			}
+			if got := event.log(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("log() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleHECEvent(t *testing.T) {
+	tests := []struct {
+		name          string
+		tokens        []string
+		auth          string
+		body          string
+		wantStatus    int
+		wantCode      int
+		wantInvalidAt *int
+		wantEmitted   int
+	}{
+		{
+			name:        "concatenated events",
+			auth:        "Splunk any",
+			body:        `{"event":"a"}{"event":"b"} {"event":{"message":"c"}}`,
+			wantStatus:  http.StatusOK,
+			wantEmitted: 3,
+		},
+		{
+			name:        "configured token",
+			tokens:      []string{"secret"},
+			auth:        "splunk secret",
+			body:        `{"event":"a"}`,
+			wantStatus:  http.StatusOK,
+			wantEmitted: 1,
+		},
+		{name: "missing token", body: `{"event":"a"}`, wantStatus: http.StatusUnauthorized, wantCode: 2},
+		{name: "wrong scheme", auth: "Bearer x", body: `{"event":"a"}`, wantStatus: http.StatusUnauthorized, wantCode: 3},
+		{name: "unknown token", tokens: []string{"secret"}, auth: "Splunk guess", body: `{"event":"a"}`, wantStatus: http.StatusUnauthorized, wantCode: 4},
+		{name: "no data", auth: "Splunk x", body: " ", wantStatus: http.StatusBadRequest, wantCode: 5},
+		{
+			name:          "truncated event after a valid one",
+			auth:          "Splunk x",
+			body:          `{"event":"a"}{"event":"b`,
+			wantStatus:    http.StatusBadRequest,
+			wantCode:      6,
+			wantInvalidAt: ptr(1),
+			wantEmitted:   1,
+		},
+		{name: "missing event", auth: "Splunk x", body: `{"time":1}`, wantStatus: http.StatusBadRequest, wantCode: 12, wantInvalidAt: ptr(0)},
+		{name: "blank event", auth: "Splunk x", body: `{"event":""}`, wantStatus: http.StatusBadRequest, wantCode: 13, wantInvalidAt: ptr(0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver, out := startReceiver(t, config.IngestConfig{HECTokens: tt.tokens})
+
+			req := httptest.NewRequest(http.MethodPost, "/services/collector/event", strings.NewReader(tt.body))
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			rec := httptest.NewRecorder()
+			receiver.HandleHECEvent(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			var resp hecResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("invalid response %q: %v", rec.Body.String(), err)
+			}
+			if resp.Code != tt.wantCode || !reflect.DeepEqual(resp.InvalidEventNumber, tt.wantInvalidAt) {
+				t.Errorf("response = %s, want code %d", rec.Body.String(), tt.wantCode)
+			}
+			if got := len(drain(out)); got != tt.wantEmitted {
+				t.Errorf("emitted %d entries, want %d", got, tt.wantEmitted)
+			}
+		})
+	}
+}
+
+func TestHandleHECHealth(t *testing.T) {
+	receiver := NewReceiver("ingest", config.IngestConfig{})
+
+	rec := httptest.NewRecorder()
+	receiver.HandleHECHealth(rec, httptest.NewRequest(http.MethodGet, "/services/collector/health", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status before start = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+
+	receiver, _ = startReceiver(t, config.IngestConfig{})
+	rec = httptest.NewRecorder()
+	receiver.HandleHECHealth(rec, httptest.NewRequest(http.MethodGet, "/services/collector/health", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("status when running = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func ptr(i int) *int {
+	return &i
+}
@@ -0,0 +1,294 @@
+// Package ingest provides the HTTP endpoints that let services push logs into
+// the server. The Receiver is a source.Source, so pushed logs flow through the
+// source registry to the connection hub like logs from any other source.
+package ingest
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"smart-log-viewer/server/internal/config"
+	"smart-log-viewer/server/internal/model"
+	"smart-log-viewer/server/internal/source"
+)
+
+const (
+	// defaultMaxBodySize is the largest accepted body when none is configured.
+	defaultMaxBodySize = 10 * 1024 * 1024
+
+	// maxReportedErrors caps the number of per-entry errors in a response.
+	maxReportedErrors = 20
+)
+
+// errNotRunning is returned by emit when the receiver is not started.
+var errNotRunning = errors.New("ingest receiver is not running")
+
+// Receiver accepts logs pushed over HTTP and emits them as a source.
+type Receiver struct {
+	source.Status
+
+	name        string
+	maxBodySize int64
+	hecTokens   map[string]bool
+
+	mu  sync.RWMutex
+	ctx context.Context
+	out chan<- model.Log
+}
+
+// NewReceiver creates an HTTP ingest receiver.
+//
+// Parameters:
+//   - name: The unique source name of the receiver
+//   - cfg: The ingest options
+//
+// Returns:
+//   - *Receiver: A new receiver; its handlers reject requests until it is started
+func NewReceiver(name string, cfg config.IngestConfig) *Receiver {
+	maxBodySize := cfg.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = defaultMaxBodySize
+	}
+	hecTokens := make(map[string]bool, len(cfg.HECTokens))
+	for _, token := range cfg.HECTokens {
+		hecTokens[token] = true
+	}
+	return &Receiver{
+		name:        name,
+		maxBodySize: maxBodySize,
+		hecTokens:   hecTokens,
+	}
+}
+
+// Name returns the unique name of the source.
+func (r *Receiver) Name() string {
+	return r.name
+}
+
+// Start makes the receiver accept requests.
+//
+// Parameters:
+//   - ctx: Context that stops the receiver when cancelled
+//   - out: Channel pushed log entries are sent to
+//
+// Returns:
+//   - error: Always nil
+func (r *Receiver) Start(ctx context.Context, out chan<- model.Log) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.ctx = ctx
+	r.out = out
+	r.SetState(source.StateRunning)
+	return nil
+}
+
+// Stop makes the receiver reject further requests.
+//
+// Returns:
+//   - error: Always nil
+func (r *Receiver) Stop() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.out = nil
+	r.SetState(source.StateStopped)
+	return nil
+}
+
+// running reports whether the receiver accepts logs.
+func (r *Receiver) running() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.out != nil
+}
+
+// emit sends decoded entries to the registry. Sending stops early when the
+// request is cancelled or the receiver shuts down.
+//
+// Parameters:
+//   - reqCtx: Context of the HTTP request
+//   - entries: The entries to send
+//
+// Returns:
+//   - int: The number of entries sent
+//   - error: nil if all entries were sent
+func (r *Receiver) emit(reqCtx context.Context, entries []model.Log) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.out == nil {
+		return 0, errNotRunning
+	}
+
+	ctx, cancel := context.WithCancel(reqCtx)
+	defer cancel()
+	stop := context.AfterFunc(r.ctx, cancel)
+	defer stop()
+
+	for i, entry := range entries {
+		if !r.Emit(ctx, r.out, entry) {
+			return i, ctx.Err()
+		}
+	}
+	return len(entries), nil
+}
+
+// entryError describes why one pushed entry was rejected.
+type entryError struct {
+	// Index is the zero-based position of the entry in the request.
+	Index int `json:"index"`
+
+	// Error is the reason the entry was rejected.
+	Error string `json:"error"`
+}
+
+// ingestResponse is the JSON body returned by the ingest endpoints.
+type ingestResponse struct {
+	// Accepted counts the entries forwarded to clients.
+	Accepted int `json:"accepted"`
+
+	// Rejected counts the entries that failed validation.
+	Rejected int `json:"rejected"`
+
+	// Errors lists the first rejected entries and why they were rejected.
+	Errors []entryError `json:"errors,omitempty"`
+
+	// Error describes a failure of the request as a whole.
+	Error string `json:"error,omitempty"`
+}
+
+// addError records a rejected entry, keeping at most maxReportedErrors details.
+func (resp *ingestResponse) addError(index int, err error) {
+	resp.Rejected++
+	if len(resp.Errors) < maxReportedErrors {
+		resp.Errors = append(resp.Errors, entryError{Index: index, Error: err.Error()})
+	}
+}
+
+// HandleIngest handles POST /api/ingest. The body may be a single JSON log,
+// a JSON array of logs, or newline-delimited JSON, optionally gzip-encoded.
+// The response reports how many entries were accepted and rejected.
+//
+// Parameters:
+//   - w: HTTP response writer
+//   - req: The ingest request
+func (r *Receiver) HandleIngest(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, ingestResponse{Error: "method not allowed"})
+		return
+	}
+
+	body, err := r.readBody(w, req)
+	if err != nil {
+		writeJSON(w, bodyErrorStatus(err), ingestResponse{Error: err.Error()})
+		return
+	}
+
+	var resp ingestResponse
+	var entries []model.Log
+	for i, raw := range splitDocuments(body) {
+		entry, err := decodeLog(raw)
+		if err != nil {
+			resp.addError(i, err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	r.finish(w, req, entries, resp)
+}
+
+// finish emits the accepted entries and writes the response.
+// Requests in which every entry was rejected get 400 Bad Request.
+func (r *Receiver) finish(w http.ResponseWriter, req *http.Request, entries []model.Log, resp ingestResponse) {
+	sent, err := r.emit(req.Context(), entries)
+	resp.Accepted = sent
+	if err != nil {
+		resp.Error = err.Error()
+		writeJSON(w, http.StatusServiceUnavailable, resp)
+		return
+	}
+
+	status := http.StatusOK
+	if resp.Accepted == 0 && resp.Rejected > 0 {
+		status = http.StatusBadRequest
+	}
+	log.Printf("Ingest %s: accepted %d, rejected %d", req.URL.Path, resp.Accepted, resp.Rejected)
+	writeJSON(w, status, resp)
+}
+
+// errBodyTooLarge is returned when a body exceeds the configured limit.
+var errBodyTooLarge = errors.New("request body too large")
+
+// readBody reads the request body, transparently decompressing gzip,
+// and enforces the size limit on the decompressed data.
+//
+// Parameters:
+//   - w: HTTP response writer, used by the size limiter
+//   - req: The request whose body is read
+//
+// Returns:
+//   - []byte: The decompressed body
+//   - error: nil on success, or an error describing why the body is unusable
+func (r *Receiver) readBody(w http.ResponseWriter, req *http.Request) ([]byte, error) {
+	var reader io.Reader = http.MaxBytesReader(w, req.Body, r.maxBodySize)
+
+	switch strings.ToLower(strings.TrimSpace(req.Header.Get("Content-Encoding"))) {
+	case "", "identity":
+	case "gzip", "x-gzip":
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip body: %w", err)
+		}
+		defer gz.Close()
+		reader = gz
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", req.Header.Get("Content-Encoding"))
+	}
+
+	// Read one byte past the limit so that decompression bombs are caught too.
+	body, err := io.ReadAll(io.LimitReader(reader, r.maxBodySize+1))
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return nil, errBodyTooLarge
+		}
+		return nil, fmt.Errorf("read body: %w", err)
+	}
+	if int64(len(body)) > r.maxBodySize {
+		return nil, errBodyTooLarge
+	}
+	return body, nil
+}
+
+// bodyErrorStatus maps a readBody error to an HTTP status code.
+func bodyErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, errBodyTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case strings.HasPrefix(err.Error(), "unsupported content encoding"):
+		return http.StatusUnsupportedMediaType
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// writeJSON writes v as a JSON response with the given status code.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Error writing ingest response: %v", err)
+	}
+}
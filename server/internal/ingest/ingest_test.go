@@ -0,0 +1,183 @@
+package ingest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"smart-log-viewer/server/internal/config"
+	"smart-log-viewer/server/internal/model"
+)
+
+// startReceiver returns a running receiver and the channel it emits to.
+func startReceiver(t *testing.T, cfg config.IngestConfig) (*Receiver, chan model.Log) {
+	t.Helper()
+
+	receiver := NewReceiver("ingest", cfg)
+	out := make(chan model.Log, 100)
+	if err := receiver.Start(context.Background(), out); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { receiver.Stop() })
+	return receiver, out
+}
+
+// drain returns the entries waiting in out.
+func drain(out chan model.Log) []model.Log {
+	var logs []model.Log
+	for {
+		select {
+		case entry := <-out:
+			logs = append(logs, entry)
+		default:
+			return logs
+		}
+	}
+}
+
+// gzipped compresses data.
+func gzipped(t *testing.T, data string) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestHandleIngest(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		encoding     string
+		body         string
+		maxBodySize  int64
+		wantStatus   int
+		wantAccepted int
+		wantRejected int
+		wantError    string
+	}{
+		{
+			name:         "ndjson",
+			body:         "{\"message\":\"a\"}\n{\"message\":\"b\"}",
+			wantStatus:   http.StatusOK,
+			wantAccepted: 2,
+		},
+		{
+			name:         "partly rejected",
+			body:         `[{"message":"a"},{"level":"info"},{"message":"c"}]`,
+			wantStatus:   http.StatusOK,
+			wantAccepted: 2,
+			wantRejected: 1,
+		},
+		{
+			name:         "all rejected",
+			body:         `{"message":`,
+			wantStatus:   http.StatusBadRequest,
+			wantRejected: 1,
+		},
+		{
+			name:         "gzip",
+			encoding:     "gzip",
+			body:         gzipped(t, `{"message":"zipped"}`),
+			wantStatus:   http.StatusOK,
+			wantAccepted: 1,
+		},
+		{
+			name:       "truncated gzip",
+			encoding:   "gzip",
+			body:       gzipped(t, `{"message":"zipped"}`)[:12],
+			wantStatus: http.StatusBadRequest,
+			wantError:  "read body",
+		},
+		{
+			name:       "not gzip",
+			encoding:   "gzip",
+			body:       `{"message":"plain"}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid gzip body",
+		},
+		{
+			name:       "unsupported encoding",
+			encoding:   "br",
+			body:       `{"message":"a"}`,
+			wantStatus: http.StatusUnsupportedMediaType,
+			wantError:  "unsupported content encoding",
+		},
+		{
+			name:        "too large",
+			body:        `{"message":"too long"}`,
+			maxBodySize: 10,
+			wantStatus:  http.StatusRequestEntityTooLarge,
+			wantError:   "too large",
+		},
+		{
+			name:        "decompresses past the limit",
+			encoding:    "gzip",
+			body:        gzipped(t, `{"message":"`+strings.Repeat("a", 1000)+`"}`),
+			maxBodySize: 100,
+			wantStatus:  http.StatusRequestEntityTooLarge,
+			wantError:   "too large",
+		},
+		{
+			name:       "wrong method",
+			method:     http.MethodGet,
+			wantStatus: http.StatusMethodNotAllowed,
+			wantError:  "method not allowed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver, out := startReceiver(t, config.IngestConfig{MaxBodySize: tt.maxBodySize})
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodPost
+			}
+			req := httptest.NewRequest(method, "/api/ingest", strings.NewReader(tt.body))
+			if tt.encoding != "" {
+				req.Header.Set("Content-Encoding", tt.encoding)
+			}
+			rec := httptest.NewRecorder()
+			receiver.HandleIngest(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			var resp ingestResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("invalid response %q: %v", rec.Body.String(), err)
+			}
+			if resp.Accepted != tt.wantAccepted || resp.Rejected != tt.wantRejected {
+				t.Errorf("accepted %d, rejected %d, want %d and %d", resp.Accepted, resp.Rejected, tt.wantAccepted, tt.wantRejected)
+			}
+			if !strings.Contains(resp.Error, tt.wantError) || (tt.wantError == "" && resp.Error != "") {
+				t.Errorf("error = %q, want %q", resp.Error, tt.wantError)
+			}
+			if got := len(drain(out)); got != tt.wantAccepted {
+				t.Errorf("emitted %d entries, want %d", got, tt.wantAccepted)
+			}
+		})
+	}
+}
+
+func TestHandleIngestNotRunning(t *testing.T) {
+	receiver := NewReceiver("ingest", config.IngestConfig{})
+
+	rec := httptest.NewRecorder()
+	receiver.HandleIngest(rec, httptest.NewRequest(http.MethodPost, "/api/ingest", strings.NewReader(`{"message":"a"}`)))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
@@ -0,0 +1,358 @@
+package ingest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/snappy"
+
+	"smart-log-viewer/server/internal/level"
+	"smart-log-viewer/server/internal/model"
+)
+
+// lokiStream is one stream of a Loki push request: a label set and the
+// entries logged under it.
+type lokiStream struct {
+	labels  map[string]string
+	entries []lokiEntry
+}
+
+// lokiEntry is one log line of a stream.
+type lokiEntry struct {
+	timestamp time.Time
+	line      string
+
+	// metadata holds the structured metadata attached to the line.
+	metadata map[string]string
+}
+
+// decodeLokiJSON decodes a JSON push request:
+//
+//	{"streams":[{"stream":{"job":"app"},"values":[["<unix ns>","line",{"key":"value"}]]}]}
+//
+// Parameters:
+//   - body: The request body
+//
+// Returns:
+//   - []lokiStream: The decoded streams
+//   - error: nil on success, or a decoding error
+func decodeLokiJSON(body []byte) ([]lokiStream, error) {
+	var req struct {
+		Streams []struct {
+			Stream map[string]string   `json:"stream"`
+			Values [][]json.RawMessage `json:"values"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, err
+	}
+
+	streams := make([]lokiStream, 0, len(req.Streams))
+	for i, s := range req.Streams {
+		stream := lokiStream{labels: s.Stream}
+		for j, value := range s.Values {
+			entry, err := decodeLokiJSONValue(value)
+			if err != nil {
+				return nil, fmt.Errorf("stream %d, value %d: %w", i, j, err)
+			}
+			stream.entries = append(stream.entries, entry)
+		}
+		streams = append(streams, stream)
+	}
+	return streams, nil
+}
+
+// decodeLokiJSONValue decodes one ["<unix ns>", "line", {metadata}] value.
+func decodeLokiJSONValue(value []json.RawMessage) (lokiEntry, error) {
+	if len(value) < 2 || len(value) > 3 {
+		return lokiEntry{}, errors.New("value must be [timestamp, line] or [timestamp, line, metadata]")
+	}
+
+	var entry lokiEntry
+	var ns string
+	if err := json.Unmarshal(value[0], &ns); err != nil {
+		return lokiEntry{}, errors.New("timestamp must be a string of Unix nanoseconds")
+	}
+	nanos, err := strconv.ParseInt(ns, 10, 64)
+	if err != nil {
+		return lokiEntry{}, fmt.Errorf("invalid timestamp %q", ns)
+	}
+	entry.timestamp = time.Unix(0, nanos)
+
+	if err := json.Unmarshal(value[1], &entry.line); err != nil {
+		return lokiEntry{}, errors.New("line must be a string")
+	}
+	if len(value) == 3 {
+		if err := json.Unmarshal(value[2], &entry.metadata); err != nil {
+			return lokiEntry{}, errors.New("structured metadata must be an object of strings")
+		}
+	}
+	return entry, nil
+}
+
+// decodeLokiProto decodes a protobuf PushRequest, the format Promtail and
+// Grafana Agent send. Its labels are in Prometheus notation, e.g. {job="app"}.
+//
+// Parameters:
+//   - b: The uncompressed protobuf message
+//
+// Returns:
+//   - []lokiStream: The decoded streams
+//   - error: nil on success, or a decoding error
+func decodeLokiProto(b []byte) ([]lokiStream, error) {
+	var streams []lokiStream
+	err := walkProto(b, func(f protoField) error {
+		if f.num != 1 {
+			return nil
+		}
+		stream, err := decodeLokiStream(f.raw)
+		streams = append(streams, stream)
+		return err
+	})
+	return streams, err
+}
+
+// decodeLokiStream decodes a StreamAdapter message.
+func decodeLokiStream(b []byte) (lokiStream, error) {
+	var stream lokiStream
+	err := walkProto(b, func(f protoField) error {
+		var err error
+		switch f.num {
+		case 1:
+			stream.labels, err = parseLokiLabels(string(f.raw))
+		case 2:
+			var entry lokiEntry
+			entry, err = decodeLokiEntry(f.raw)
+			stream.entries = append(stream.entries, entry)
+		}
+		return err
+	})
+	return stream, err
+}
+
+// decodeLokiEntry decodes an EntryAdapter message.
+func decodeLokiEntry(b []byte) (lokiEntry, error) {
+	var entry lokiEntry
+	err := walkProto(b, func(f protoField) error {
+		switch f.num {
+		case 1:
+			var seconds, nanos int64
+			err := walkProto(f.raw, func(f protoField) error {
+				switch f.num {
+				case 1:
+					seconds = int64(f.u64)
+				case 2:
+					nanos = int64(int32(f.u64))
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			entry.timestamp = time.Unix(seconds, nanos)
+		case 2:
+			entry.line = string(f.raw)
+		case 3:
+			var name, value string
+			err := walkProto(f.raw, func(f protoField) error {
+				switch f.num {
+				case 1:
+					name = string(f.raw)
+				case 2:
+					value = string(f.raw)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if entry.metadata == nil {
+				entry.metadata = make(map[string]string)
+			}
+			entry.metadata[name] = value
+		}
+		return nil
+	})
+	return entry, err
+}
+
+// parseLokiLabels parses a label set in Prometheus notation,
+// e.g. {job="app", filename="/var/log/app.log"}.
+//
+// Parameters:
+//   - text: The label set
+//
+// Returns:
+//   - map[string]string: The labels by name
+//   - error: nil on success, or an error describing the malformed label set
+func parseLokiLabels(text string) (map[string]string, error) {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "{") || !strings.HasSuffix(text, "}") {
+		return nil, fmt.Errorf("invalid label set %q", text)
+	}
+
+	labels := make(map[string]string)
+	rest := strings.TrimSpace(text[1 : len(text)-1])
+	for rest != "" {
+		name, value, ok := strings.Cut(rest, "=")
+		name = strings.TrimSpace(name)
+		value = strings.TrimSpace(value)
+		if !ok || name == "" || !strings.HasPrefix(value, `"`) {
+			return nil, fmt.Errorf("invalid label set %q", text)
+		}
+
+		// Find the closing quote, skipping escaped characters.
+		end := 1
+		for end < len(value) && value[end] != '"' {
+			if value[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(value) {
+			return nil, fmt.Errorf("invalid label set %q", text)
+		}
+		unquoted, err := strconv.Unquote(value[:end+1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid label value %s", value[:end+1])
+		}
+		labels[name] = unquoted
+
+		rest = strings.TrimSpace(value[end+1:])
+		rest = strings.TrimSpace(strings.TrimPrefix(rest, ","))
+	}
+	return labels, nil
+}
+
+// lokiLevelKeys are the labels and metadata keys a level is read from.
+var lokiLevelKeys = []string{"level", "detected_level", "severity"}
+
+// lokiEntries converts Loki streams into log entries. Stream labels and
+// structured metadata become fields; the service_name or job label becomes
+// the source and the host or hostname label the host.
+//
+// Parameters:
+//   - streams: The decoded streams
+//
+// Returns:
+//   - []model.Log: One entry per line
+func lokiEntries(streams []lokiStream) []model.Log {
+	var entries []model.Log
+	for _, stream := range streams {
+		source := stream.labels["service_name"]
+		if source == "" {
+			source = stream.labels["job"]
+		}
+		host := stream.labels["host"]
+		if host == "" {
+			host = stream.labels["hostname"]
+		}
+
+		for _, line := range stream.entries {
+			entry := model.Log{
+				Level:     level.Info,
+				Message:   line.line,
+				Timestamp: line.timestamp,
+				Source:    source,
+				Host:      host,
+				Fields:    make(map[string]interface{}, len(stream.labels)+len(line.metadata)),
+			}
+			for key, value := range stream.labels {
+				entry.Fields[key] = value
+			}
+			for key, value := range line.metadata {
+				entry.Fields[key] = value
+			}
+			for _, key := range lokiLevelKeys {
+				text, _ := entry.Fields[key].(string)
+				if level.Apply(&entry, text) {
+					break
+				}
+			}
+
+			if len(entry.Fields) == 0 {
+				entry.Fields = nil
+			}
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// HandleLokiPush handles POST /loki/api/v1/push, the Grafana Loki push API,
+// so Promtail and Grafana Agent can ship logs to the viewer unchanged.
+// Bodies are snappy-compressed protobuf (application/x-protobuf, the
+// default) or JSON (application/json, optionally gzip-encoded).
+//
+// Parameters:
+//   - w: HTTP response writer
+//   - req: The push request
+func (r *Receiver) HandleLokiPush(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := r.readBody(w, req)
+	if err != nil {
+		http.Error(w, err.Error(), bodyErrorStatus(err))
+		return
+	}
+
+	var streams []lokiStream
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/json":
+		streams, err = decodeLokiJSON(body)
+	case "", "application/x-protobuf":
+		var decoded []byte
+		decoded, err = r.decodeSnappy(body)
+		if errors.Is(err, errBodyTooLarge) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err == nil {
+			streams, err = decodeLokiProto(decoded)
+		}
+	default:
+		http.Error(w, fmt.Sprintf("unsupported content type %q", mediaType), http.StatusUnsupportedMediaType)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid push request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	sent, err := r.emit(req.Context(), lokiEntries(streams))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	log.Printf("Ingest %s: accepted %d", req.URL.Path, sent)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeSnappy decompresses a snappy block, enforcing the body size limit
+// on the decompressed data.
+func (r *Receiver) decodeSnappy(body []byte) ([]byte, error) {
+	size, err := snappy.DecodedLen(body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snappy body: %w", err)
+	}
+	if int64(size) > r.maxBodySize {
+		return nil, errBodyTooLarge
+	}
+	decoded, err := snappy.Decode(nil, body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snappy body: %w", err)
+	}
+	return decoded, nil
+}
@@ -0,0 +1,232 @@
+package ingest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+
+	"smart-log-viewer/server/internal/config"
+	"smart-log-viewer/server/internal/model"
+)
+
+func TestParseLokiLabels(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    map[string]string
+		wantErr string
+	}{
+		{text: `{}`, want: map[string]string{}},
+		{text: ` {job="app"} `, want: map[string]string{"job": "app"}},
+		{
+			text: `{job="app", filename="/var/log/app.log",}`,
+			want: map[string]string{"job": "app", "filename": "/var/log/app.log"},
+		},
+		{text: `{msg="say \"hi\", then \\ leave"}`, want: map[string]string{"msg": `say "hi", then \ leave`}},
+		{text: `{a="x=y",b=""}`, want: map[string]string{"a": "x=y", "b": ""}},
+		{text: `job="app"`, wantErr: "invalid label set"},
+		{text: `{job="app"`, wantErr: "invalid label set"},
+		{text: `{job}`, wantErr: "invalid label set"},
+		{text: `{="app"}`, wantErr: "invalid label set"},
+		{text: `{job=app}`, wantErr: "invalid label set"},
+		{text: `{job="app}`, wantErr: "invalid label set"},
+		{text: `{job="a\qb"}`, wantErr: "invalid label value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := parseLokiLabels(tt.text)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseLokiLabels() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseLokiLabels() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseLokiLabels() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// lokiProtoEntry encodes an EntryAdapter message.
+func lokiProtoEntry(ts time.Time, line string, metadata ...[2]string) []byte {
+	entry := pb(pbBytes(1, pb(pbVarint(1, uint64(ts.Unix())), pbVarint(2, uint64(ts.Nanosecond())))), pbString(2, line))
+	for _, kv := range metadata {
+		entry = append(entry, pbBytes(3, pb(pbString(1, kv[0]), pbString(2, kv[1])))...)
+	}
+	return entry
+}
+
+// lokiProtoRequest encodes a PushRequest with one stream.
+func lokiProtoRequest(labels string, entries ...[]byte) []byte {
+	stream := pbString(1, labels)
+	for _, entry := range entries {
+		stream = append(stream, pbBytes(2, entry)...)
+	}
+	return pbBytes(1, stream)
+}
+
+func TestDecodeLokiProto(t *testing.T) {
+	ts := time.Unix(1710069753, 456)
+
+	tests := []struct {
+		name    string
+		body    []byte
+		want    []model.Log
+		wantErr string
+	}{
+		{name: "empty", body: nil},
+		{
+			name: "labels and metadata",
+			body: lokiProtoRequest(`{service_name="checkout", host="web01", level="warn"}`,
+				lokiProtoEntry(ts, "slow query", [2]string{"trace_id", "abc"}),
+				lokiProtoEntry(ts, "overridden", [2]string{"level", "error"})),
+			want: []model.Log{
+				{
+					Level: "WARN", RawLevel: "warn", Message: "slow query", Timestamp: ts, Source: "checkout", Host: "web01",
+					Fields: map[string]interface{}{"service_name": "checkout", "host": "web01", "level": "warn", "trace_id": "abc"},
+				},
+				{
+					Level: "ERROR", RawLevel: "error", Message: "overridden", Timestamp: ts, Source: "checkout", Host: "web01",
+					Fields: map[string]interface{}{"service_name": "checkout", "host": "web01", "level": "error"},
+				},
+			},
+		},
+		{
+			name: "job and hostname",
+			body: lokiProtoRequest(`{job="varlogs", hostname="db01"}`, lokiProtoEntry(ts, "line")),
+			want: []model.Log{{
+				Level: "INFO", Message: "line", Timestamp: ts, Source: "varlogs", Host: "db01",
+				Fields: map[string]interface{}{"job": "varlogs", "hostname": "db01"},
+			}},
+		},
+		{name: "malformed labels", body: lokiProtoRequest(`job="x"`), wantErr: "invalid label set"},
+		{name: "truncated", body: lokiProtoRequest(`{job="x"}`, lokiProtoEntry(ts, "line"))[:20], wantErr: "unexpected EOF"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			streams, err := decodeLokiProto(tt.body)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("decodeLokiProto() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeLokiProto() error = %v", err)
+			}
+			if got := lokiEntries(streams); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("entries = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeLokiJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    []model.Log
+		wantErr string
+	}{
+		{
+			name: "values with and without metadata",
+			body: `{"streams":[{"stream":{"job":"app"},"values":[["1710069753000000001","one"],["1710069753000000002","two",{"detected_level":"debug"}]]}]}`,
+			want: []model.Log{
+				{Level: "INFO", Message: "one", Timestamp: time.Unix(0, 1710069753000000001), Source: "app", Fields: map[string]interface{}{"job": "app"}},
+				{
+					Level: "DEBUG", RawLevel: "debug", Message: "two", Timestamp: time.Unix(0, 1710069753000000002), Source: "app",
+					Fields: map[string]interface{}{"job": "app", "detected_level": "debug"},
+				},
+			},
+		},
+		{name: "no streams", body: `{"streams":[]}`},
+		{name: "truncated", body: `{"streams":[{"stream":`, wantErr: "unexpected end of JSON input"},
+		{name: "short value", body: `{"streams":[{"values":[["1"]]}]}`, wantErr: "stream 0, value 0: value must be"},
+		{name: "numeric timestamp", body: `{"streams":[{"values":[[1,"x"]]}]}`, wantErr: "timestamp must be a string"},
+		{name: "invalid timestamp", body: `{"streams":[{"values":[["now","x"]]}]}`, wantErr: `invalid timestamp "now"`},
+		{name: "line not a string", body: `{"streams":[{"values":[["1",{}]]}]}`, wantErr: "line must be a string"},
+		{name: "metadata not strings", body: `{"streams":[{"values":[["1","x",{"a":1}]]}]}`, wantErr: "structured metadata must be"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			streams, err := decodeLokiJSON([]byte(tt.body))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("decodeLokiJSON() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeLokiJSON() error = %v", err)
+			}
+			if got := lokiEntries(streams); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("entries = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleLokiPush(t *testing.T) {
+	push := snappy.Encode(nil, lokiProtoRequest(`{job="app"}`, lokiProtoEntry(time.Now(), "hello")))
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		maxBodySize int64
+		wantStatus  int
+		wantEmitted int
+	}{
+		{name: "snappy protobuf", contentType: "application/x-protobuf", body: string(push), wantStatus: http.StatusNoContent, wantEmitted: 1},
+		{name: "default content type", body: string(push), wantStatus: http.StatusNoContent, wantEmitted: 1},
+		{
+			name:        "json",
+			contentType: "application/json",
+			body:        `{"streams":[{"stream":{"job":"app"},"values":[["1","hello"]]}]}`,
+			wantStatus:  http.StatusNoContent,
+			wantEmitted: 1,
+		},
+		{name: "not snappy", contentType: "application/x-protobuf", body: "\xff\xff\xff\xff\xff", wantStatus: http.StatusBadRequest},
+		{name: "truncated snappy", contentType: "application/x-protobuf", body: string(push[:len(push)-3]), wantStatus: http.StatusBadRequest},
+		{
+			name:        "decompresses past the limit",
+			contentType: "application/x-protobuf",
+			body:        string(snappy.Encode(nil, lokiProtoRequest(`{job="app"}`, lokiProtoEntry(time.Now(), strings.Repeat("a", 1000))))),
+			maxBodySize: 200,
+			wantStatus:  http.StatusRequestEntityTooLarge,
+		},
+		{name: "malformed json", contentType: "application/json", body: `{"streams":`, wantStatus: http.StatusBadRequest},
+		{name: "unsupported content type", contentType: "text/plain", body: "hello", wantStatus: http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver, out := startReceiver(t, config.IngestConfig{MaxBodySize: tt.maxBodySize})
+
+			req := httptest.NewRequest(http.MethodPost, "/loki/api/v1/push", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+			receiver.HandleLokiPush(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d (%s), want %d", rec.Code, rec.Body.String(), tt.wantStatus)
+			}
+			if got := len(drain(out)); got != tt.wantEmitted {
+				t.Errorf("emitted %d entries, want %d", got, tt.wantEmitted)
+			}
+		})
+	}
+}
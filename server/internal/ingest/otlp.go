@@ -0,0 +1,611 @@
+package ingest
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"smart-log-viewer/server/internal/level"
+	"smart-log-viewer/server/internal/model"
+)
+
+// maxAnyValueDepth limits how deeply nested protobuf attribute values may be.
+const maxAnyValueDepth = 32
+
+// The OTLP types below mirror the parts of the OpenTelemetry logs data model
+// the viewer uses. They decode OTLP/JSON directly; OTLP/protobuf is decoded
+// into the same types by decodeOTLPProto.
+
+// otlpRequest is an ExportLogsServiceRequest.
+type otlpRequest struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+// otlpResourceLogs groups the logs of one resource, e.g. one service instance.
+type otlpResourceLogs struct {
+	Resource  otlpResource    `json:"resource"`
+	ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+}
+
+// otlpResource describes the entity that produced the logs.
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+// otlpScopeLogs groups the logs of one instrumentation scope.
+type otlpScopeLogs struct {
+	Scope      otlpScope       `json:"scope"`
+	LogRecords []otlpLogRecord `json:"logRecords"`
+}
+
+// otlpScope is the instrumentation library that emitted the logs.
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// otlpLogRecord is a single OpenTelemetry log record.
+type otlpLogRecord struct {
+	TimeUnixNano         otlpUint64     `json:"timeUnixNano"`
+	ObservedTimeUnixNano otlpUint64     `json:"observedTimeUnixNano"`
+	SeverityNumber       otlpSeverity   `json:"severityNumber"`
+	SeverityText         string         `json:"severityText"`
+	Body                 otlpAnyValue   `json:"body"`
+	Attributes           []otlpKeyValue `json:"attributes"`
+	TraceID              string         `json:"traceId"`
+	SpanID               string         `json:"spanId"`
+	EventName            string         `json:"eventName"`
+}
+
+// otlpKeyValue is one attribute.
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+// otlpUint64 is a 64-bit integer, which OTLP/JSON encodes as a string.
+type otlpUint64 uint64
+
+// UnmarshalJSON implements json.Unmarshaler, accepting strings and numbers.
+func (u *otlpUint64) UnmarshalJSON(data []byte) error {
+	text := strings.Trim(string(data), `"`)
+	if text == "" || text == "null" {
+		return nil
+	}
+	value, err := strconv.ParseUint(text, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid integer %s", data)
+	}
+	*u = otlpUint64(value)
+	return nil
+}
+
+// otlpSeverity is a SeverityNumber, encoded as a number or an enum name.
+type otlpSeverity int32
+
+// UnmarshalJSON implements json.Unmarshaler, accepting numbers and names
+// such as "SEVERITY_NUMBER_WARN".
+func (s *otlpSeverity) UnmarshalJSON(data []byte) error {
+	var number int32
+	if err := json.Unmarshal(data, &number); err == nil {
+		*s = otlpSeverity(number)
+		return nil
+	}
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return fmt.Errorf("invalid severity number %s", data)
+	}
+	value, ok := severityNumbers[name]
+	if !ok {
+		return fmt.Errorf("unknown severity number %q", name)
+	}
+	*s = value
+	return nil
+}
+
+// severityNumbers maps the SeverityNumber enum names onto their values.
+var severityNumbers = func() map[string]otlpSeverity {
+	names := map[string]otlpSeverity{"SEVERITY_NUMBER_UNSPECIFIED": 0}
+	for i, level := range []string{"TRACE", "DEBUG", "INFO", "WARN", "ERROR", "FATAL"} {
+		for step := 0; step < 4; step++ {
+			suffix := ""
+			if step > 0 {
+				suffix = strconv.Itoa(step + 1)
+			}
+			names["SEVERITY_NUMBER_"+level+suffix] = otlpSeverity(i*4 + step + 1)
+		}
+	}
+	return names
+}()
+
+// apply sets the level of an entry from the severity number, keeping the
+// severity text as the raw level. The unspecified severity falls back to
+// the severity text.
+func (s otlpSeverity) apply(entry *model.Log, text string) {
+	if canonical, ok := level.FromOTel(int(s)); ok {
+		entry.Level = canonical
+	} else if canonical, ok := level.Parse(text); ok {
+		entry.Level = canonical
+	}
+	entry.RawLevel = text
+}
+
+// otlpAnyValue holds an attribute or body value converted to a plain Go
+// value: string, bool, int64, float64, []interface{} or map[string]interface{}.
+// Bytes are kept base64-encoded, as in OTLP/JSON.
+type otlpAnyValue struct {
+	value interface{}
+}
+
+// UnmarshalJSON implements json.Unmarshaler for the AnyValue oneof.
+func (v *otlpAnyValue) UnmarshalJSON(data []byte) error {
+	var obj struct {
+		StringValue *string                          `json:"stringValue"`
+		BoolValue   *bool                            `json:"boolValue"`
+		IntValue    *otlpInt64                       `json:"intValue"`
+		DoubleValue *float64                         `json:"doubleValue"`
+		BytesValue  *string                          `json:"bytesValue"`
+		ArrayValue  *struct{ Values []otlpAnyValue } `json:"arrayValue"`
+		KvlistValue *struct{ Values []otlpKeyValue } `json:"kvlistValue"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+
+	switch {
+	case obj.StringValue != nil:
+		v.value = *obj.StringValue
+	case obj.BoolValue != nil:
+		v.value = *obj.BoolValue
+	case obj.IntValue != nil:
+		v.value = int64(*obj.IntValue)
+	case obj.DoubleValue != nil:
+		v.value = *obj.DoubleValue
+	case obj.BytesValue != nil:
+		v.value = *obj.BytesValue
+	case obj.ArrayValue != nil:
+		values := make([]interface{}, len(obj.ArrayValue.Values))
+		for i, item := range obj.ArrayValue.Values {
+			values[i] = item.value
+		}
+		v.value = values
+	case obj.KvlistValue != nil:
+		v.value = attributeMap(obj.KvlistValue.Values)
+	}
+	return nil
+}
+
+// otlpInt64 is a signed 64-bit integer, which OTLP/JSON encodes as a string.
+type otlpInt64 int64
+
+// UnmarshalJSON implements json.Unmarshaler, accepting strings and numbers.
+func (i *otlpInt64) UnmarshalJSON(data []byte) error {
+	value, err := strconv.ParseInt(strings.Trim(string(data), `"`), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid integer %s", data)
+	}
+	*i = otlpInt64(value)
+	return nil
+}
+
+// attributeMap converts a list of attributes into a map.
+func attributeMap(attributes []otlpKeyValue) map[string]interface{} {
+	values := make(map[string]interface{}, len(attributes))
+	for _, kv := range attributes {
+		values[kv.Key] = kv.Value.value
+	}
+	return values
+}
+
+// protoField is one decoded field of a protobuf message.
+type protoField struct {
+	num protowire.Number
+	typ protowire.Type
+
+	// u64 holds varint, fixed32 and fixed64 values.
+	u64 uint64
+
+	// raw holds length-delimited values: strings, bytes and messages.
+	raw []byte
+}
+
+// walkProto calls fn for every field of a protobuf message.
+// Groups and other unused wire types are skipped.
+func walkProto(b []byte, fn func(f protoField) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		f := protoField{num: num, typ: typ}
+		switch typ {
+		case protowire.VarintType:
+			f.u64, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed32Type:
+			var v uint32
+			v, n = protowire.ConsumeFixed32(b)
+			f.u64 = uint64(v)
+		case protowire.Fixed64Type:
+			f.u64, n = protowire.ConsumeFixed64(b)
+		case protowire.BytesType:
+			f.raw, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeOTLPProto decodes a protobuf-encoded ExportLogsServiceRequest.
+//
+// Parameters:
+//   - b: The protobuf message
+//
+// Returns:
+//   - otlpRequest: The decoded request
+//   - error: nil on success, or a decoding error
+func decodeOTLPProto(b []byte) (otlpRequest, error) {
+	var req otlpRequest
+	err := walkProto(b, func(f protoField) error {
+		if f.num != 1 {
+			return nil
+		}
+		var rl otlpResourceLogs
+		if err := decodeResourceLogs(f.raw, &rl); err != nil {
+			return err
+		}
+		req.ResourceLogs = append(req.ResourceLogs, rl)
+		return nil
+	})
+	return req, err
+}
+
+// decodeResourceLogs decodes a ResourceLogs message.
+func decodeResourceLogs(b []byte, rl *otlpResourceLogs) error {
+	return walkProto(b, func(f protoField) error {
+		switch f.num {
+		case 1:
+			return walkProto(f.raw, func(f protoField) error {
+				if f.num != 1 {
+					return nil
+				}
+				kv, err := decodeKeyValue(f.raw, 0)
+				rl.Resource.Attributes = append(rl.Resource.Attributes, kv)
+				return err
+			})
+		case 2:
+			var sl otlpScopeLogs
+			if err := decodeScopeLogs(f.raw, &sl); err != nil {
+				return err
+			}
+			rl.ScopeLogs = append(rl.ScopeLogs, sl)
+		}
+		return nil
+	})
+}
+
+// decodeScopeLogs decodes a ScopeLogs message.
+func decodeScopeLogs(b []byte, sl *otlpScopeLogs) error {
+	return walkProto(b, func(f protoField) error {
+		switch f.num {
+		case 1:
+			return walkProto(f.raw, func(f protoField) error {
+				switch f.num {
+				case 1:
+					sl.Scope.Name = string(f.raw)
+				case 2:
+					sl.Scope.Version = string(f.raw)
+				}
+				return nil
+			})
+		case 2:
+			var record otlpLogRecord
+			if err := decodeLogRecord(f.raw, &record); err != nil {
+				return err
+			}
+			sl.LogRecords = append(sl.LogRecords, record)
+		}
+		return nil
+	})
+}
+
+// decodeLogRecord decodes a LogRecord message.
+func decodeLogRecord(b []byte, record *otlpLogRecord) error {
+	return walkProto(b, func(f protoField) error {
+		var err error
+		switch f.num {
+		case 1:
+			record.TimeUnixNano = otlpUint64(f.u64)
+		case 11:
+			record.ObservedTimeUnixNano = otlpUint64(f.u64)
+		case 2:
+			record.SeverityNumber = otlpSeverity(int32(f.u64))
+		case 3:
+			record.SeverityText = string(f.raw)
+		case 5:
+			record.Body, err = decodeAnyValue(f.raw, 0)
+		case 6:
+			var kv otlpKeyValue
+			kv, err = decodeKeyValue(f.raw, 0)
+			record.Attributes = append(record.Attributes, kv)
+		case 9:
+			record.TraceID = hex.EncodeToString(f.raw)
+		case 10:
+			record.SpanID = hex.EncodeToString(f.raw)
+		case 12:
+			record.EventName = string(f.raw)
+		}
+		return err
+	})
+}
+
+// decodeKeyValue decodes a KeyValue message.
+func decodeKeyValue(b []byte, depth int) (otlpKeyValue, error) {
+	var kv otlpKeyValue
+	err := walkProto(b, func(f protoField) error {
+		var err error
+		switch f.num {
+		case 1:
+			kv.Key = string(f.raw)
+		case 2:
+			kv.Value, err = decodeAnyValue(f.raw, depth+1)
+		}
+		return err
+	})
+	return kv, err
+}
+
+// decodeAnyValue decodes an AnyValue message.
+func decodeAnyValue(b []byte, depth int) (otlpAnyValue, error) {
+	if depth > maxAnyValueDepth {
+		return otlpAnyValue{}, errors.New("attribute values nested too deeply")
+	}
+
+	var v otlpAnyValue
+	err := walkProto(b, func(f protoField) error {
+		switch f.num {
+		case 1:
+			v.value = string(f.raw)
+		case 2:
+			v.value = f.u64 != 0
+		case 3:
+			v.value = int64(f.u64)
+		case 4:
+			v.value = math.Float64frombits(f.u64)
+		case 5:
+			var values []interface{}
+			err := walkProto(f.raw, func(f protoField) error {
+				if f.num != 1 {
+					return nil
+				}
+				item, err := decodeAnyValue(f.raw, depth+1)
+				values = append(values, item.value)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			if values == nil {
+				values = []interface{}{}
+			}
+			v.value = values
+		case 6:
+			var attributes []otlpKeyValue
+			err := walkProto(f.raw, func(f protoField) error {
+				if f.num != 1 {
+					return nil
+				}
+				kv, err := decodeKeyValue(f.raw, depth+1)
+				attributes = append(attributes, kv)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			v.value = attributeMap(attributes)
+		case 7:
+			v.value = base64.StdEncoding.EncodeToString(f.raw)
+		}
+		return nil
+	})
+	return v, err
+}
+
+// otlpEntries converts the log records of a request into log entries.
+// Resource attributes become fields, overridden by record attributes of
+// the same name; the service.name and host.name resource attributes become
+// the source and host.
+//
+// Parameters:
+//   - req: The decoded export request
+//
+// Returns:
+//   - []model.Log: One entry per log record
+func otlpEntries(req otlpRequest) []model.Log {
+	var entries []model.Log
+	for _, rl := range req.ResourceLogs {
+		resource := attributeMap(rl.Resource.Attributes)
+		service, _ := resource["service.name"].(string)
+		host, _ := resource["host.name"].(string)
+
+		for _, sl := range rl.ScopeLogs {
+			for _, record := range sl.LogRecords {
+				entry := model.Log{
+					Level:     level.Info,
+					Message:   bodyText(record.Body.value, record.EventName),
+					Timestamp: recordTime(record),
+					Source:    service,
+					Host:      host,
+					Fields:    make(map[string]interface{}),
+				}
+
+				record.SeverityNumber.apply(&entry, record.SeverityText)
+				for key, value := range resource {
+					entry.Fields[key] = value
+				}
+				for _, kv := range record.Attributes {
+					entry.Fields[kv.Key] = kv.Value.value
+				}
+				setField(entry.Fields, "scope", sl.Scope.Name)
+				setField(entry.Fields, "event_name", record.EventName)
+				if record.SeverityNumber != 0 {
+					entry.Fields["severity_number"] = int32(record.SeverityNumber)
+				}
+				setField(entry.Fields, "trace_id", validID(record.TraceID))
+				setField(entry.Fields, "span_id", validID(record.SpanID))
+
+				if len(entry.Fields) == 0 {
+					entry.Fields = nil
+				}
+				entries = append(entries, entry)
+			}
+		}
+	}
+	return entries
+}
+
+// setField stores a string field unless it is empty.
+func setField(fields map[string]interface{}, key, value string) {
+	if value != "" {
+		fields[key] = value
+	}
+}
+
+// validID returns a hex trace or span ID, or "" if it is unset or all zeros.
+func validID(id string) string {
+	if strings.Trim(id, "0") == "" {
+		return ""
+	}
+	return strings.ToLower(id)
+}
+
+// recordTime returns when the event happened, falling back to when it was
+// observed. Records with neither are left to the processing stages and
+// the receive time. Times too large for nanoseconds in an int64, past the
+// year 2262, are treated as missing.
+func recordTime(record otlpLogRecord) time.Time {
+	for _, nanos := range []otlpUint64{record.TimeUnixNano, record.ObservedTimeUnixNano} {
+		if nanos != 0 && nanos <= math.MaxInt64 {
+			return time.Unix(0, int64(nanos))
+		}
+	}
+	return time.Time{}
+}
+
+// bodyText renders a log record body as the message. Structured bodies are
+// shown as JSON; records without a body show their event name.
+func bodyText(body interface{}, eventName string) string {
+	switch value := body.(type) {
+	case nil:
+		return eventName
+	case string:
+		return value
+	case map[string]interface{}, []interface{}:
+		text, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprint(value)
+		}
+		return string(text)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// HandleOTLPLogs handles POST /v1/logs, the OTLP/HTTP logs endpoint.
+// Bodies are ExportLogsServiceRequest messages encoded as protobuf
+// (application/x-protobuf) or JSON (application/json), optionally
+// gzip-encoded. Responses use the encoding of the request.
+//
+// Parameters:
+//   - w: HTTP response writer
+//   - req: The export request
+func (r *Receiver) HandleOTLPLogs(w http.ResponseWriter, req *http.Request) {
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	useProto := mediaType == "application/x-protobuf" || mediaType == "application/protobuf"
+
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeOTLPStatus(w, useProto, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if !useProto && mediaType != "application/json" {
+		writeOTLPStatus(w, false, http.StatusUnsupportedMediaType,
+			fmt.Sprintf("unsupported content type %q", req.Header.Get("Content-Type")))
+		return
+	}
+
+	body, err := r.readBody(w, req)
+	if err != nil {
+		writeOTLPStatus(w, useProto, bodyErrorStatus(err), err.Error())
+		return
+	}
+
+	var export otlpRequest
+	if useProto {
+		export, err = decodeOTLPProto(body)
+	} else {
+		err = json.Unmarshal(body, &export)
+	}
+	if err != nil {
+		writeOTLPStatus(w, useProto, http.StatusBadRequest, fmt.Sprintf("invalid export request: %v", err))
+		return
+	}
+
+	entries := otlpEntries(export)
+	sent, err := r.emit(req.Context(), entries)
+	if err != nil {
+		// 503 tells OTLP exporters to retry the whole request later.
+		writeOTLPStatus(w, useProto, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	log.Printf("Ingest %s: accepted %d", req.URL.Path, sent)
+
+	// An empty ExportLogsServiceResponse reports full success.
+	if useProto {
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	writeJSON(w, http.StatusOK, struct{}{})
+}
+
+// writeOTLPStatus writes an OTLP error response, a google.rpc.Status
+// carrying only a message, in the encoding of the request.
+func writeOTLPStatus(w http.ResponseWriter, useProto bool, status int, message string) {
+	if !useProto {
+		writeJSON(w, status, struct {
+			Message string `json:"message"`
+		}{message})
+		return
+	}
+
+	var b []byte
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	b = protowire.AppendString(b, message)
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.WriteHeader(status)
+	if _, err := w.Write(b); err != nil {
+		log.Printf("Error writing ingest response: %v", err)
+	}
+}
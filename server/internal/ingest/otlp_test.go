@@ -0,0 +1,297 @@
+package ingest
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"smart-log-viewer/server/internal/config"
+	"smart-log-viewer/server/internal/model"
+)
+
+// pb concatenates encoded protobuf fields into a message.
+func pb(fields ...[]byte) []byte {
+	return bytes.Join(fields, nil)
+}
+
+// pbBytes encodes a length-delimited field: a string, bytes or a message.
+func pbBytes(num protowire.Number, value []byte) []byte {
+	b := protowire.AppendTag(nil, num, protowire.BytesType)
+	return protowire.AppendBytes(b, value)
+}
+
+// pbString encodes a string field.
+func pbString(num protowire.Number, value string) []byte {
+	return pbBytes(num, []byte(value))
+}
+
+// pbVarint encodes a varint field.
+func pbVarint(num protowire.Number, value uint64) []byte {
+	b := protowire.AppendTag(nil, num, protowire.VarintType)
+	return protowire.AppendVarint(b, value)
+}
+
+// pbFixed64 encodes a fixed64 field.
+func pbFixed64(num protowire.Number, value uint64) []byte {
+	b := protowire.AppendTag(nil, num, protowire.Fixed64Type)
+	return protowire.AppendFixed64(b, value)
+}
+
+// pbAttribute encodes a KeyValue message holding value.
+func pbAttribute(key string, value []byte) []byte {
+	return pb(pbString(1, key), pbBytes(2, value))
+}
+
+// otlpProtoRequest encodes an export request with one resource holding
+// resource attributes and one scope holding records.
+func otlpProtoRequest(resource [][]byte, records ...[]byte) []byte {
+	var attributes [][]byte
+	for _, kv := range resource {
+		attributes = append(attributes, pbBytes(1, kv))
+	}
+	scope := pb(pbBytes(1, pb(pbString(1, "my.scope"), pbString(2, "1.0"))))
+	for _, record := range records {
+		scope = append(scope, pbBytes(2, record)...)
+	}
+	return pbBytes(1, pb(pbBytes(1, pb(attributes...)), pbBytes(2, scope)))
+}
+
+func TestDecodeOTLPProto(t *testing.T) {
+	eventTime := uint64(time.Date(2024, time.March, 10, 11, 22, 33, 0, time.UTC).UnixNano())
+
+	fullRecord := pb(
+		pbFixed64(1, eventTime),
+		pbVarint(2, 17),
+		pbString(3, "Error"),
+		pbBytes(5, pbString(1, "disk full")),
+		pbBytes(6, pbAttribute("retries", pbVarint(3, 3))),
+		pbBytes(6, pbAttribute("ratio", pbFixed64(4, math.Float64bits(0.5)))),
+		pbBytes(6, pbAttribute("ok", pbVarint(2, 1))),
+		pbBytes(6, pbAttribute("raw", pbBytes(7, []byte{0xde, 0xad}))),
+		pbBytes(6, pbAttribute("list", pbBytes(5, pb(pbBytes(1, pbString(1, "a")), pbBytes(1, pbVarint(3, 1)))))),
+		pbBytes(6, pbAttribute("map", pbBytes(6, pbBytes(1, pbAttribute("k", pbString(1, "v")))))),
+		pbBytes(9, bytes.Repeat([]byte{0xab}, 16)),
+		pbBytes(10, make([]byte, 8)),
+		pbString(12, "disk.event"),
+		pbVarint(99, 1),
+	)
+
+	// deep nests array values deeper than maxAnyValueDepth allows.
+	deep := pbString(1, "leaf")
+	for i := 0; i <= maxAnyValueDepth+1; i++ {
+		deep = pbBytes(5, pbBytes(1, deep))
+	}
+
+	tests := []struct {
+		name    string
+		body    []byte
+		want    []model.Log
+		wantErr string
+	}{
+		{name: "empty request", body: nil},
+		{
+			name: "full record",
+			body: otlpProtoRequest([][]byte{
+				pbAttribute("service.name", pbString(1, "checkout")),
+				pbAttribute("host.name", pbString(1, "web01")),
+			}, fullRecord),
+			want: []model.Log{{
+				Level: "ERROR", RawLevel: "Error", Message: "disk full", Source: "checkout", Host: "web01",
+				Timestamp: time.Unix(0, int64(eventTime)),
+				Fields: map[string]interface{}{
+					"service.name": "checkout", "host.name": "web01",
+					"retries": int64(3), "ratio": 0.5, "ok": true, "raw": "3q0=",
+					"list": []interface{}{"a", int64(1)}, "map": map[string]interface{}{"k": "v"},
+					"scope": "my.scope", "event_name": "disk.event", "severity_number": int32(17),
+					"trace_id": strings.Repeat("ab", 16),
+				},
+			}},
+		},
+		{
+			name: "observed time and severity text only",
+			body: otlpProtoRequest(nil, pb(pbFixed64(11, eventTime), pbString(3, "warning"), pbBytes(5, pbVarint(3, 42)))),
+			want: []model.Log{{
+				Level: "WARN", RawLevel: "warning", Message: "42",
+				Timestamp: time.Unix(0, int64(eventTime)),
+				Fields:    map[string]interface{}{"scope": "my.scope"},
+			}},
+		},
+		{
+			name: "no time and a structured body",
+			body: otlpProtoRequest(nil, pb(pbBytes(5, pbBytes(6, pbBytes(1, pbAttribute("a", pbString(1, "b"))))))),
+			want: []model.Log{{
+				Level: "INFO", Message: `{"a":"b"}`,
+				Fields: map[string]interface{}{"scope": "my.scope"},
+			}},
+		},
+		{name: "truncated", body: otlpProtoRequest(nil, fullRecord)[:40], wantErr: "unexpected EOF"},
+		{name: "truncated tag", body: []byte{0x80}, wantErr: "unexpected EOF"},
+		{name: "invalid field number", body: []byte{0x00}, wantErr: "invalid field number"},
+		{name: "nested too deeply", body: otlpProtoRequest(nil, pbBytes(5, deep)), wantErr: "nested too deeply"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := decodeOTLPProto(tt.body)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("decodeOTLPProto() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeOTLPProto() error = %v", err)
+			}
+			if got := otlpEntries(req); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("entries = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeOTLPJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    []model.Log
+		wantErr string
+	}{
+		{
+			name: "string integers and severity name",
+			body: `{"resourceLogs":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"api"}}]},
+				"scopeLogs":[{"logRecords":[{"timeUnixNano":"1710069753000000000","severityNumber":"SEVERITY_NUMBER_WARN2",
+				"body":{"stringValue":"slow"},"attributes":[{"key":"n","value":{"intValue":"7"}},
+				{"key":"b","value":{"boolValue":false}},{"key":"d","value":{"doubleValue":1.5}},
+				{"key":"arr","value":{"arrayValue":{"values":[{"stringValue":"x"}]}}},
+				{"key":"kv","value":{"kvlistValue":{"values":[{"key":"k","value":{"intValue":1}}]}}}],
+				"traceId":"00000000000000000000000000000000","spanId":"ABCDEF0123456789"}]}]}]}`,
+			want: []model.Log{{
+				Level: "WARN", Message: "slow", Source: "api", Timestamp: time.Unix(1710069753, 0),
+				Fields: map[string]interface{}{
+					"service.name": "api", "n": int64(7), "b": false, "d": 1.5,
+					"arr": []interface{}{"x"}, "kv": map[string]interface{}{"k": int64(1)},
+					"severity_number": int32(14), "span_id": "abcdef0123456789",
+				},
+			}},
+		},
+		{
+			name: "event without body",
+			body: `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"severityNumber":21,"eventName":"login"}]}]}]}`,
+			want: []model.Log{{
+				Level: "FATAL", Message: "login",
+				Fields: map[string]interface{}{"event_name": "login", "severity_number": int32(21)},
+			}},
+		},
+		{name: "empty", body: `{}`},
+		{name: "truncated", body: `{"resourceLogs":[{"scopeLogs":`, wantErr: "unexpected end of JSON input"},
+		{name: "unknown severity name", body: `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"severityNumber":"LOUD"}]}]}]}`, wantErr: "unknown severity number"},
+		{name: "invalid time", body: `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"timeUnixNano":"soon"}]}]}]}`, wantErr: "invalid integer"},
+		{name: "invalid int value", body: `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"intValue":"1.5"}}]}]}]}`, wantErr: "invalid integer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req otlpRequest
+			err := json.Unmarshal([]byte(tt.body), &req)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Unmarshal() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if got := otlpEntries(req); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("entries = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordTime(t *testing.T) {
+	eventTime := time.Date(2024, time.March, 10, 11, 22, 33, 0, time.UTC)
+	observed := eventTime.Add(time.Second)
+	nanos := func(t time.Time) otlpUint64 { return otlpUint64(t.UnixNano()) }
+
+	tests := []struct {
+		name   string
+		record otlpLogRecord
+		want   time.Time
+	}{
+		{name: "event time", record: otlpLogRecord{TimeUnixNano: nanos(eventTime), ObservedTimeUnixNano: nanos(observed)}, want: eventTime},
+		{name: "observed time", record: otlpLogRecord{ObservedTimeUnixNano: nanos(observed)}, want: observed},
+		{name: "event time out of range", record: otlpLogRecord{TimeUnixNano: math.MaxInt64 + 1, ObservedTimeUnixNano: nanos(observed)}, want: observed},
+		{name: "largest event time", record: otlpLogRecord{TimeUnixNano: math.MaxInt64}, want: time.Unix(0, math.MaxInt64)},
+		{name: "both out of range", record: otlpLogRecord{TimeUnixNano: math.MaxUint64, ObservedTimeUnixNano: math.MaxUint64}},
+		{name: "neither"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := recordTime(tt.record); !got.Equal(tt.want) {
+				t.Errorf("recordTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleOTLPLogs(t *testing.T) {
+	record := otlpProtoRequest(nil, pb(pbBytes(5, pbString(1, "hello"))))
+
+	tests := []struct {
+		name        string
+		method      string
+		contentType string
+		body        string
+		wantStatus  int
+		wantEmitted int
+		wantMessage string
+	}{
+		{name: "protobuf", contentType: "application/x-protobuf", body: string(record), wantStatus: http.StatusOK, wantEmitted: 1},
+		{
+			name:        "json",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"body":{"stringValue":"hello"}}]}]}]}`,
+			wantStatus:  http.StatusOK,
+			wantEmitted: 1,
+		},
+		{name: "truncated protobuf", contentType: "application/x-protobuf", body: string(record[:len(record)-2]), wantStatus: http.StatusBadRequest, wantMessage: "invalid export request"},
+		{name: "malformed json", contentType: "application/json", body: `{"resourceLogs":`, wantStatus: http.StatusBadRequest, wantMessage: "invalid export request"},
+		{name: "unsupported content type", contentType: "text/plain", body: "hello", wantStatus: http.StatusUnsupportedMediaType, wantMessage: "unsupported content type"},
+		{name: "wrong method", method: http.MethodGet, contentType: "application/json", wantStatus: http.StatusMethodNotAllowed, wantMessage: "method not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver, out := startReceiver(t, config.IngestConfig{})
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodPost
+			}
+			req := httptest.NewRequest(method, "/v1/logs", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			rec := httptest.NewRecorder()
+			receiver.HandleOTLPLogs(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantMessage) {
+				t.Errorf("body = %q, want it to contain %q", rec.Body.String(), tt.wantMessage)
+			}
+			if got := len(drain(out)); got != tt.wantEmitted {
+				t.Errorf("emitted %d entries, want %d", got, tt.wantEmitted)
+			}
+		})
+	}
+}
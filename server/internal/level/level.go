@@ -0,0 +1,143 @@
+// Package level defines the severity scale of the viewer and maps the
+// level names, syslog severities and OpenTelemetry severity numbers that
+// log producers use onto it.
+package level
+
+import (
+	"strconv"
+	"strings"
+
+	"smart-log-viewer/server/internal/model"
+)
+
+// The canonical levels, from least to most severe.
+const (
+	Trace    = "TRACE"
+	Debug    = "DEBUG"
+	Info     = "INFO"
+	Notice   = "NOTICE"
+	Warn     = "WARN"
+	Error    = "ERROR"
+	Critical = "CRITICAL"
+	Fatal    = "FATAL"
+)
+
+// Levels lists the canonical levels in order of severity, least severe first.
+var Levels = []string{Trace, Debug, Info, Notice, Warn, Error, Critical, Fatal}
+
+// aliases maps the spellings logging libraries use onto the canonical
+// levels, including the single letters of glog, klog and Android.
+var aliases = map[string]string{
+	"TRACE": Trace, "T": Trace, "FINEST": Trace, "FINER": Trace, "VERBOSE": Trace, "V": Trace,
+	"DEBUG": Debug, "D": Debug, "DBG": Debug, "FINE": Debug,
+	"INFO": Info, "I": Info, "INF": Info, "INFORMATION": Info, "INFORMATIONAL": Info, "CONFIG": Info,
+	"NOTICE": Notice, "N": Notice,
+	"WARN": Warn, "W": Warn, "WRN": Warn, "WARNING": Warn,
+	"ERROR": Error, "E": Error, "ERR": Error, "SEVERE": Error,
+	"CRITICAL": Critical, "C": Critical, "CRIT": Critical, "ALERT": Critical,
+	"FATAL": Fatal, "F": Fatal, "FTL": Fatal, "PANIC": Fatal, "EMERG": Fatal, "EMERGENCY": Fatal,
+}
+
+// syslogLevels maps syslog severity codes, 0 (emerg) to 7 (debug), onto
+// the canonical levels.
+var syslogLevels = []string{Fatal, Critical, Critical, Error, Warn, Notice, Info, Debug}
+
+// syslogNames maps syslog severity codes to their conventional names.
+var syslogNames = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+// Parse maps a level as written by a log producer onto the canonical scale.
+// Names are matched in any case; a number from 0 to 7 is read as a syslog
+// severity.
+//
+// Parameters:
+//   - text: The level, e.g. "warning", "E" or "3"
+//
+// Returns:
+//   - string: The canonical level
+//   - bool: true if the level is known
+func Parse(text string) (string, bool) {
+	text = strings.TrimSpace(text)
+	if level, ok := aliases[strings.ToUpper(text)]; ok {
+		return level, true
+	}
+	if severity, err := strconv.Atoi(text); err == nil && severity >= 0 && severity < len(syslogLevels) {
+		return syslogLevels[severity], true
+	}
+	return "", false
+}
+
+// Apply sets the level of an entry from the level a producer wrote, which
+// is kept as the entry's raw level. Unknown levels leave the entry as is.
+//
+// Parameters:
+//   - entry: The entry to update
+//   - text: The level as written by the producer
+//
+// Returns:
+//   - bool: true if the level is known
+func Apply(entry *model.Log, text string) bool {
+	level, ok := Parse(text)
+	if ok {
+		entry.Level = level
+		entry.RawLevel = strings.TrimSpace(text)
+	}
+	return ok
+}
+
+// FromSyslog maps a syslog severity code onto the canonical scale.
+//
+// Parameters:
+//   - severity: The severity code, 0 (emerg) to 7 (debug)
+//
+// Returns:
+//   - string: The canonical level, INFO for codes out of range
+func FromSyslog(severity int) string {
+	if severity < 0 || severity >= len(syslogLevels) {
+		return Info
+	}
+	return syslogLevels[severity]
+}
+
+// SyslogName returns the conventional name of a syslog severity code, as
+// kept for the raw level of formats that use syslog severities.
+//
+// Parameters:
+//   - severity: The severity code, 0 (emerg) to 7 (debug)
+//
+// Returns:
+//   - string: The severity name, or the code itself if it is out of range
+func SyslogName(severity int) string {
+	if severity < 0 || severity >= len(syslogNames) {
+		return strconv.Itoa(severity)
+	}
+	return syslogNames[severity]
+}
+
+// FromOTel maps an OpenTelemetry severity number onto the canonical scale.
+// Each of the six OpenTelemetry ranges of four numbers maps onto the level
+// of the same name.
+//
+// Parameters:
+//   - number: The severity number, 1 (TRACE) to 24 (FATAL4)
+//
+// Returns:
+//   - string: The canonical level
+//   - bool: false for 0 (unspecified) and numbers out of range
+func FromOTel(number int) (string, bool) {
+	switch {
+	case number >= 25 || number <= 0:
+		return "", false
+	case number >= 21:
+		return Fatal, true
+	case number >= 17:
+		return Error, true
+	case number >= 13:
+		return Warn, true
+	case number >= 9:
+		return Info, true
+	case number >= 5:
+		return Debug, true
+	default:
+		return Trace, true
+	}
+}
@@ -0,0 +1,128 @@
+package level
+
+import (
+	"testing"
+
+	"smart-log-viewer/server/internal/model"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text   string
+		want   string
+		wantOK bool
+	}{
+		{text: "INFO", want: Info, wantOK: true},
+		{text: "warning", want: Warn, wantOK: true},
+		{text: " Err ", want: Error, wantOK: true},
+		{text: "E", want: Error, wantOK: true},
+		{text: "v", want: Trace, wantOK: true},
+		{text: "FINE", want: Debug, wantOK: true},
+		{text: "alert", want: Critical, wantOK: true},
+		{text: "emerg", want: Fatal, wantOK: true},
+		{text: "panic", want: Fatal, wantOK: true},
+		{text: "0", want: Fatal, wantOK: true},
+		{text: "3", want: Error, wantOK: true},
+		{text: "5", want: Notice, wantOK: true},
+		{text: "7", want: Debug, wantOK: true},
+		{text: "8"},
+		{text: "-1"},
+		{text: "loud"},
+		{text: "WARNINGS"},
+		{text: ""},
+	}
+
+	for _, tt := range tests {
+		got, ok := Parse(tt.text)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("Parse(%q) = %q, %v, want %q, %v", tt.text, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		want   model.Log
+		wantOK bool
+	}{
+		{name: "known level", text: " warning ", want: model.Log{Level: Warn, RawLevel: "warning"}, wantOK: true},
+		{name: "syslog severity", text: "2", want: model.Log{Level: Critical, RawLevel: "2"}, wantOK: true},
+		{name: "unknown level", text: "loud", want: model.Log{Level: Info}},
+		{name: "empty", text: "", want: model.Log{Level: Info}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := model.Log{Level: Info}
+			ok := Apply(&entry, tt.text)
+			if ok != tt.wantOK || entry.Level != tt.want.Level || entry.RawLevel != tt.want.RawLevel {
+				t.Errorf("Apply(%q) = %q %q, %v, want %q %q, %v",
+					tt.text, entry.Level, entry.RawLevel, ok, tt.want.Level, tt.want.RawLevel, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestFromSyslog(t *testing.T) {
+	want := []string{Fatal, Critical, Critical, Error, Warn, Notice, Info, Debug}
+	for severity, level := range want {
+		if got := FromSyslog(severity); got != level {
+			t.Errorf("FromSyslog(%d) = %q, want %q", severity, got, level)
+		}
+	}
+	for _, severity := range []int{-1, 8} {
+		if got := FromSyslog(severity); got != Info {
+			t.Errorf("FromSyslog(%d) = %q, want %q", severity, got, Info)
+		}
+	}
+}
+
+func TestSyslogName(t *testing.T) {
+	tests := []struct {
+		severity int
+		want     string
+	}{
+		{severity: 0, want: "emerg"},
+		{severity: 3, want: "err"},
+		{severity: 4, want: "warning"},
+		{severity: 7, want: "debug"},
+		{severity: -1, want: "-1"},
+		{severity: 8, want: "8"},
+	}
+
+	for _, tt := range tests {
+		if got := SyslogName(tt.severity); got != tt.want {
+			t.Errorf("SyslogName(%d) = %q, want %q", tt.severity, got, tt.want)
+		}
+	}
+}
+
+func TestFromOTel(t *testing.T) {
+	tests := []struct {
+		number int
+		want   string
+		wantOK bool
+	}{
+		{number: 0},
+		{number: 1, want: Trace, wantOK: true},
+		{number: 4, want: Trace, wantOK: true},
+		{number: 5, want: Debug, wantOK: true},
+		{number: 9, want: Info, wantOK: true},
+		{number: 12, want: Info, wantOK: true},
+		{number: 13, want: Warn, wantOK: true},
+		{number: 17, want: Error, wantOK: true},
+		{number: 21, want: Fatal, wantOK: true},
+		{number: 24, want: Fatal, wantOK: true},
+		{number: 25},
+		{number: -3},
+	}
+
+	for _, tt := range tests {
+		got, ok := FromOTel(tt.number)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("FromOTel(%d) = %q, %v, want %q, %v", tt.number, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
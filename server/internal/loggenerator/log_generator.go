@@ -7,16 +7,12 @@ import (
 	"math/rand"
 	"time"
 
+	"smart-log-viewer/server/internal/level"
 	"smart-log-viewer/server/internal/model"
 )
 
-// levels contains the available log severity levels for mock log generation.
-// These levels follow standard logging conventions and are randomly
-// selected when creating mock log entries.
-var levels = []string{"INFO", "WARN", "ERROR"}
-
-// GenerateMockLog creates a mock log entry with a random log level (INFO, WARN, or ERROR),
-// the provided message, and current timestamp. This function is used for testing and
+// GenerateMockLog creates a mock log entry with a random level from the
+// canonical scale, the provided message, and current timestamp. This function is used for testing and
 // demonstration purposes to simulate log generation.
 //
 // The function generates realistic log entries by randomly selecting
@@ -29,7 +25,7 @@ var levels = []string{"INFO", "WARN", "ERROR"}
 //   - model.Log: A mock log entry with random level, message, and current timestamp
 func GenerateMockLog(message string) model.Log {
 	return model.Log{
-		Level:     levels[rand.Intn(len(levels))],
+		Level:     level.Levels[rand.Intn(len(level.Levels))],
 		Message:   "This is a mock log message" + message,
 		Timestamp: time.Now(),
 	}
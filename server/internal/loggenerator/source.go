@@ -0,0 +1,110 @@
+package loggenerator
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"smart-log-viewer/server/internal/config"
+	"smart-log-viewer/server/internal/model"
+	"smart-log-viewer/server/internal/source"
+)
+
+// defaultInterval is how often the mock source emits a log entry
+// when no interval is configured.
+const defaultInterval = 1 * time.Second
+
+// Config holds the options of a mock log source.
+type Config struct {
+	// Interval is the delay between two generated log entries.
+	Interval config.Duration `json:"interval"`
+}
+
+// Source is a source.Source that emits a mock log entry at a fixed interval.
+// It replaces the generator loop that used to live in main.
+type Source struct {
+	source.Status
+
+	name     string
+	interval time.Duration
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewSource creates a mock log source.
+//
+// Parameters:
+//   - name: The unique name of the source
+//   - cfg: The source options; a zero interval falls back to one second
+//
+// Returns:
+//   - *Source: A new mock source instance
+func NewSource(name string, cfg Config) *Source {
+	interval := cfg.Interval.Duration
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	return &Source{
+		name:     name,
+		interval: interval,
+	}
+}
+
+// Name returns the unique name of the source.
+func (s *Source) Name() string {
+	return s.name
+}
+
+// Start launches the generator goroutine.
+//
+// Parameters:
+//   - ctx: Context that stops the generator when cancelled
+//   - out: Channel generated log entries are sent to
+//
+// Returns:
+//   - error: Always nil
+func (s *Source) Start(ctx context.Context, out chan<- model.Log) error {
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.SetState(source.StateRunning)
+
+	s.wg.Add(1)
+	go s.run(ctx, out)
+	return nil
+}
+
+// run emits one mock log entry per interval until ctx is cancelled.
+func (s *Source) run(ctx context.Context, out chan<- model.Log) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	count := 0
+	for {
+		select {
+		case <-ticker.C:
+			count++
+			entry := GenerateMockLog(" - Test message " + strconv.Itoa(count))
+			entry.Host = source.LocalHostname()
+			if !s.Emit(ctx, out, entry) {
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Stop stops the generator and waits for it to exit.
+//
+// Returns:
+//   - error: Always nil
+func (s *Source) Stop() error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+	s.SetState(source.StateStopped)
+	return nil
+}
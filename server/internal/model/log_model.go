@@ -2,22 +2,51 @@ package model
 
 import "time"
 
-// Log represents a log entry with a level, message, and timestamp.
+// Log represents a log entry with a level, message, and timestamp,
+// along with where it came from and any structured context.
 // This struct is used to represent log messages that are generated
 // by the server and sent to connected WebSocket clients.
 //
 // The struct includes JSON tags for serialization and follows
 // standard logging conventions with severity levels.
 type Log struct {
-	// Level represents the severity of the log entry.
-	// Common values include "INFO", "WARN", and "ERROR".
+	// Level represents the severity of the log entry on the canonical
+	// scale: "TRACE", "DEBUG", "INFO", "NOTICE", "WARN", "ERROR",
+	// "CRITICAL" or "FATAL".
 	Level string `json:"level"`
 
+	// RawLevel is the level as the producer wrote it, such as "warning",
+	// "E" or a syslog severity name, when Level was mapped from one.
+	RawLevel string `json:"rawLevel,omitempty"`
+
 	// Message contains the actual log message text.
 	// This field holds the descriptive information about the log event.
 	Message string `json:"message"`
 
-	// Timestamp records when the log entry was created.
-	// This field uses Go's time.Time type for precise timestamp handling.
+	// Timestamp records when the log entry was created, in UTC.
+	// Sources that cannot tell leave it zero for the timestamp stage,
+	// which falls back to ReceivedAt.
 	Timestamp time.Time `json:"timestamp"`
+
+	// ReceivedAt records when the server received the entry. Comparing it
+	// with Timestamp shows how long the entry took to arrive. Replayed
+	// entries have none.
+	ReceivedAt time.Time `json:"receivedAt,omitzero"`
+
+	// Source identifies where the log entry came from,
+	// for example the path of the file it was read from.
+	Source string `json:"source,omitempty"`
+
+	// Host names the machine that produced the log entry, such as the
+	// hostname of a syslog sender or the server itself for local files.
+	Host string `json:"host,omitempty"`
+
+	// Fields holds structured context extracted by the source,
+	// such as the syslog facility or the trace ID of a request.
+	Fields map[string]interface{} `json:"fields,omitempty"`
+
+	// ID is the sequence number the hub assigns when it broadcasts the entry.
+	// IDs increase monotonically in broadcast order, so clients can use them
+	// as stable keys and to tell which entries they have already seen.
+	ID uint64 `json:"id"`
 }
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestLogJSON(t *testing.T) {
+	at := time.Date(2024, time.March, 10, 11, 22, 33, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		log  Log
+		want string
+	}{
+		{
+			name: "bare entry",
+			log:  Log{Level: "INFO", Message: "hello", Timestamp: at},
+			want: `{"level":"INFO","message":"hello","timestamp":"2024-03-10T11:22:33Z","id":0}`,
+		},
+		{
+			name: "all fields",
+			log: Log{
+				Level: "WARN", RawLevel: "warning", Message: "slow", Timestamp: at, ReceivedAt: at.Add(time.Second),
+				Source: "/var/log/app.log", Host: "web01", Fields: map[string]interface{}{"took": 1.5}, ID: 7,
+			},
+			want: `{"level":"WARN","rawLevel":"warning","message":"slow","timestamp":"2024-03-10T11:22:33Z",` +
+				`"receivedAt":"2024-03-10T11:22:34Z","source":"/var/log/app.log","host":"web01","fields":{"took":1.5},"id":7}`,
+		},
+		{
+			name: "empty fields are left out",
+			log:  Log{Level: "INFO", Timestamp: at, Fields: map[string]interface{}{}, ID: 1},
+			want: `{"level":"INFO","message":"","timestamp":"2024-03-10T11:22:33Z","id":1}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.log)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("json.Marshal() = %s, want %s", data, tt.want)
+			}
+		})
+	}
+}
@@ -1,5 +1,71 @@
 package model
 
+import "time"
+
+// ProtocolVersion is the newest version of the WebSocket protocol the
+// server speaks. Clients announce the versions they support in a hello
+// message and the server answers with the version both sides will use.
+const ProtocolVersion = 1
+
+// Message types of the WebSocket protocol.
+const (
+	// MessageHello is sent by a client after connecting, with HelloData.
+	MessageHello = "hello"
+
+	// MessageWelcome answers a hello with WelcomeData.
+	MessageWelcome = "welcome"
+
+	// MessageLog carries a Log from the server.
+	MessageLog = "log"
+
+	// MessageHistory asks for recent logs with HistoryData. The server
+	// answers with the logs it kept, followed by MessageLive.
+	MessageHistory = "history"
+
+	// MessageLive ends the logs sent for a history request, with LiveData;
+	// the logs after it are live.
+	MessageLive = "live"
+
+	// MessageGap reports logs a resuming client missed that the server no
+	// longer keeps, with GapData.
+	MessageGap = "gap"
+
+	// MessageStatus reports the state of the connection with StatusData,
+	// in answer to a pause or resume.
+	MessageStatus = "status"
+
+	// MessageError reports a message the server could not handle, with ErrorData.
+	MessageError = "error"
+
+	// MessagePause and MessageResume stop and restart the delivery of logs.
+	MessagePause  = "pause"
+	MessageResume = "resume"
+
+	// MessagePing and MessagePong are heartbeats, sent by either side.
+	MessagePing = "ping"
+	MessagePong = "pong"
+)
+
+// Error codes of ErrorData.
+const (
+	// ErrorInvalidMessage means the message was not a JSON envelope.
+	ErrorInvalidMessage = "invalid_message"
+
+	// ErrorUnknownType means the server does not know the message type.
+	ErrorUnknownType = "unknown_type"
+
+	// ErrorInvalidData means the payload does not fit the message type.
+	ErrorInvalidData = "invalid_data"
+
+	// ErrorBusy means the connection was too backlogged to take the answer
+	// to a request; the client may send the request again later.
+	ErrorBusy = "busy"
+
+	// ErrorUnsupportedVersion means client and server share no protocol
+	// version; the server closes the connection after sending it.
+	ErrorUnsupportedVersion = "unsupported_version"
+)
+
 // WebSocketMessage represents a message sent over the WebSocket connection.
 // This struct provides a standardized format for all WebSocket communication
 // between the server and clients, including logs, control messages, and
@@ -8,13 +74,90 @@ package model
 // The message type system allows for different kinds of data to be
 // transmitted while maintaining a consistent structure.
 type WebSocketMessage struct {
-	// Type identifies the kind of message being sent.
-	// Common types include "log", "pause", "resume", "ping", and "pong".
+	// Type identifies the kind of message being sent, one of the
+	// Message constants.
 	Type string `json:"type"`
 
+	// ID is an optional request ID chosen by the client. The server
+	// copies it into its answer, so clients can match the two.
+	ID string `json:"id,omitempty"`
+
 	// Data contains the actual message payload.
-	// The type of data varies based on the message type.
-	// For log messages, this will be a Log struct.
-	// For control messages, this may be null or a simple string.
+	// The type of data varies based on the message type:
+	// a Log for log messages, HelloData, WelcomeData, HistoryData,
+	// LiveData, GapData, StatusData or ErrorData for the protocol messages, and
+	// null or a simple string
+	// for the control and heartbeat messages.
 	Data interface{} `json:"data"`
 }
+
+// HelloData is the payload of a hello message.
+type HelloData struct {
+	// Versions lists the protocol versions the client supports.
+	Versions []int `json:"versions"`
+
+	// Client optionally names the client, for the server's log.
+	Client string `json:"client,omitempty"`
+}
+
+// WelcomeData is the payload of a welcome message.
+type WelcomeData struct {
+	// Version is the protocol version used from now on.
+	Version int `json:"version"`
+
+	// Versions lists the protocol versions the server supports.
+	Versions []int `json:"versions"`
+
+	// Paused tells whether log delivery is paused for the connection.
+	Paused bool `json:"paused"`
+
+	// Run identifies this run of the server, whose log IDs start over at
+	// 1. A reconnecting client passes it back with the ID it resumes from.
+	Run string `json:"run"`
+}
+
+// StatusData is the payload of a status message.
+type StatusData struct {
+	// Paused tells whether log delivery is paused for the connection.
+	Paused bool `json:"paused"`
+}
+
+// HistoryData is the payload of a history message. Without a limit or a
+// time, every kept log is sent.
+type HistoryData struct {
+	// Limit asks for at most this many of the newest logs.
+	Limit int `json:"limit,omitempty"`
+
+	// Since asks for the logs whose timestamp is not before this time.
+	Since time.Time `json:"since,omitzero"`
+}
+
+// LiveData is the payload of a live message.
+type LiveData struct {
+	// Count is the number of logs sent for the history request.
+	Count int `json:"count"`
+}
+
+// GapData is the payload of a gap message.
+type GapData struct {
+	// From and To are the first and last ID of the missed logs.
+	From uint64 `json:"from,omitempty"`
+	To   uint64 `json:"to,omitempty"`
+
+	// Reset tells that the server restarted and its IDs started over, so
+	// the IDs the client has seen belong to an earlier run.
+	Reset bool `json:"reset,omitempty"`
+}
+
+// ErrorData is the payload of an error message.
+type ErrorData struct {
+	// Code identifies the error, one of the Error constants.
+	Code string `json:"code"`
+
+	// Message describes the error for people.
+	Message string `json:"message"`
+
+	// Versions lists the protocol versions the server supports, sent
+	// with ErrorUnsupportedVersion.
+	Versions []int `json:"versions,omitempty"`
+}
@@ -0,0 +1,209 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"smart-log-viewer/server/internal/level"
+	"smart-log-viewer/server/internal/model"
+)
+
+// accessLine matches the common and combined access log formats, followed
+// by anything a custom format appends:
+//
+//	host ident user [time] "request" status bytes ["referrer" "user agent"] [extra]
+var accessLine = regexp.MustCompile(`^(\S+) (\S+) (\S+) \[([^\]]+)\] "((?:[^"\\]|\\.)*)" (\d{3}) (\d+|-)(?: "((?:[^"\\]|\\.)*)" "((?:[^"\\]|\\.)*)")?(.*)$`)
+
+// accessLatencyKeys name the latency among key=value pairs appended to the
+// format, e.g. nginx's "rt=$request_time".
+var accessLatencyKeys = []string{"rt", "request_time", "duration"}
+
+// latencyUnits maps the accepted latency units onto their duration.
+var latencyUnits = map[string]time.Duration{
+	"s":  time.Second,
+	"ms": time.Millisecond,
+	"us": time.Microsecond,
+}
+
+// AccessConfig holds the options of an access log parser.
+type AccessConfig struct {
+	// LatencyUnit is the unit of the request latency appended to the line:
+	// "s", "ms" or "us". It defaults to "s" for nginx ($request_time) and
+	// "us" for Apache (%D).
+	LatencyUnit string `json:"latencyUnit"`
+}
+
+// Access parses web server access logs in the common or combined format,
+// as written by nginx and the Apache HTTP Server:
+//
+//	203.0.113.7 - - [02/Jan/2024:03:04:05 +0000] "GET /api HTTP/1.1" 503 162 "-" "curl/8.5.0" 0.012
+//
+// The client, method, path, protocol, status, size, referrer and user
+// agent become fields, and the level follows the status: 5xx responses
+// are errors and 4xx responses warnings. Formats that append the request
+// latency, as a bare number or as rt=, request_time= or duration=, also
+// get a latency_ms field; other appended key=value pairs and the quoted
+// X-Forwarded-For of nginx's default format become fields.
+type Access struct {
+	latencyUnit time.Duration
+}
+
+// NewNginx creates an access log parser whose latency defaults to
+// seconds, the unit of nginx's $request_time.
+//
+// Parameters:
+//   - cfg: The parser options
+//
+// Returns:
+//   - *Access: A new parser instance
+//   - error: nil on success, or an error for an unknown latency unit
+func NewNginx(cfg AccessConfig) (*Access, error) {
+	return newAccess(cfg, "s")
+}
+
+// NewApache creates an access log parser whose latency defaults to
+// microseconds, the unit of Apache's %D.
+//
+// Parameters:
+//   - cfg: The parser options
+//
+// Returns:
+//   - *Access: A new parser instance
+//   - error: nil on success, or an error for an unknown latency unit
+func NewApache(cfg AccessConfig) (*Access, error) {
+	return newAccess(cfg, "us")
+}
+
+// newAccess creates an access log parser with a default latency unit.
+func newAccess(cfg AccessConfig, defaultUnit string) (*Access, error) {
+	if cfg.LatencyUnit == "" {
+		cfg.LatencyUnit = defaultUnit
+	}
+	unit, ok := latencyUnits[cfg.LatencyUnit]
+	if !ok {
+		return nil, fmt.Errorf("unknown latency unit %q", cfg.LatencyUnit)
+	}
+	return &Access{latencyUnit: unit}, nil
+}
+
+// Parse parses an access log line. The raw line stays the message.
+//
+// Parameters:
+//   - entry: The entry whose message is the raw line
+//
+// Returns:
+//   - Result: Matched for an access log line, NoMatch for anything else
+func (a *Access) Parse(entry *model.Log) Result {
+	m := accessLine.FindStringSubmatch(entry.Message)
+	if m == nil {
+		return NoMatch
+	}
+	timestamp, err := time.Parse("02/Jan/2006:15:04:05 -0700", m[4])
+	if err != nil {
+		return NoMatch
+	}
+	status, _ := strconv.Atoi(m[6])
+
+	if entry.Fields == nil {
+		entry.Fields = make(map[string]interface{})
+	}
+	fields := entry.Fields
+	fields["client_ip"] = m[1]
+	setAccessField(fields, "user", m[3])
+	if parts := strings.Fields(unescapeAccess(m[5])); len(parts) == 3 {
+		fields["method"], fields["path"], fields["protocol"] = parts[0], parts[1], parts[2]
+	} else {
+		setAccessField(fields, "request", unescapeAccess(m[5]))
+	}
+	fields["status"] = status
+	if bytes, err := strconv.ParseInt(m[7], 10, 64); err == nil {
+		fields["bytes"] = bytes
+	}
+	setAccessField(fields, "referrer", unescapeAccess(m[8]))
+	setAccessField(fields, "user_agent", unescapeAccess(m[9]))
+	a.parseExtra(fields, m[10])
+
+	entry.Timestamp = timestamp
+	switch {
+	case status >= 500:
+		entry.Level = level.Error
+	case status >= 400:
+		entry.Level = level.Warn
+	default:
+		entry.Level = level.Info
+	}
+	return Matched
+}
+
+// parseExtra reads what a custom format appends to the combined format:
+// key=value pairs become fields, and the latency is taken from the first
+// bare number or a latency key.
+func (a *Access) parseExtra(fields map[string]interface{}, extra string) {
+	extra = strings.TrimSpace(extra)
+	// nginx's default "main" format appends "$http_x_forwarded_for".
+	if strings.HasPrefix(extra, `"`) {
+		if end := closingQuote(extra, 0); end > 0 {
+			setAccessField(fields, "forwarded_for", unescapeAccess(extra[1:end]))
+			extra = strings.TrimSpace(extra[end+1:])
+		}
+	}
+	if extra == "" {
+		return
+	}
+
+	if first, rest, _ := strings.Cut(extra, " "); !strings.Contains(first, "=") {
+		if latency, ok := a.latency(first); ok {
+			fields["latency_ms"] = latency
+		}
+		extra = strings.TrimSpace(rest)
+	}
+
+	pairs, ok := decodeLogfmt(extra)
+	if !ok {
+		return
+	}
+	for _, key := range accessLatencyKeys {
+		if text, ok := pairs[key].(string); ok {
+			if latency, ok := a.latency(text); ok {
+				fields["latency_ms"] = latency
+				delete(pairs, key)
+				break
+			}
+		}
+	}
+	for key, value := range pairs {
+		// Bare words carry no value, and "-" marks a missing one.
+		if text, ok := value.(string); ok && text != "-" {
+			fields[key] = value
+		}
+	}
+}
+
+// latency converts a latency in the configured unit to milliseconds.
+func (a *Access) latency(text string) (float64, bool) {
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil || value < 0 {
+		return 0, false
+	}
+	return value * float64(a.latencyUnit) / float64(time.Millisecond), true
+}
+
+// setAccessField stores a value unless it is empty or the "-" placeholder
+// access logs use for missing values.
+func setAccessField(fields map[string]interface{}, key, value string) {
+	if value != "" && value != "-" {
+		fields[key] = value
+	}
+}
+
+// unescapeAccess undoes the escaping of quoted access log values: Apache
+// writes \" and \\, nginx writes \x22 for a quote.
+func unescapeAccess(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+	return strings.NewReplacer(`\"`, `"`, `\\`, `\`, `\x22`, `"`).Replace(value)
+}
@@ -0,0 +1,161 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"smart-log-viewer/server/internal/model"
+)
+
+func TestAccess(t *testing.T) {
+	const prefix = `203.0.113.7 - - [02/Jan/2024:03:04:05 +0000] `
+	stamp := time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		apache     bool
+		cfg        AccessConfig
+		line       string
+		fields     map[string]interface{}
+		wantResult Result
+		wantLevel  string
+		wantTime   time.Time
+		want       map[string]interface{}
+	}{
+		{
+			name:       "nginx combined with latency",
+			line:       prefix + `"GET /api HTTP/1.1" 503 162 "-" "curl/8.5.0" 0.5`,
+			wantResult: Matched,
+			wantLevel:  "ERROR",
+			wantTime:   stamp,
+			want: map[string]interface{}{
+				"client_ip": "203.0.113.7", "method": "GET", "path": "/api", "protocol": "HTTP/1.1",
+				"status": 503, "bytes": int64(162), "user_agent": "curl/8.5.0", "latency_ms": 500.0,
+			},
+		},
+		{
+			name:       "nginx main with forwarded for",
+			line:       prefix + `"POST /login HTTP/2.0" 404 0 "https://example.com/" "Mozilla/5.0" "10.0.0.1, 10.0.0.2"`,
+			wantResult: Matched,
+			wantLevel:  "WARN",
+			wantTime:   stamp,
+			want: map[string]interface{}{
+				"client_ip": "203.0.113.7", "method": "POST", "path": "/login", "protocol": "HTTP/2.0",
+				"status": 404, "bytes": int64(0), "referrer": "https://example.com/", "user_agent": "Mozilla/5.0",
+				"forwarded_for": "10.0.0.1, 10.0.0.2",
+			},
+		},
+		{
+			name:       "nginx key=value extras",
+			line:       prefix + `"GET / HTTP/1.1" 200 5 "-" "ua" rt=0.250 upstream=10.0.0.3:80 cache=- gzip`,
+			wantResult: Matched,
+			wantLevel:  "INFO",
+			wantTime:   stamp,
+			want: map[string]interface{}{
+				"client_ip": "203.0.113.7", "method": "GET", "path": "/", "protocol": "HTTP/1.1",
+				"status": 200, "bytes": int64(5), "user_agent": "ua", "latency_ms": 250.0, "upstream": "10.0.0.3:80",
+			},
+		},
+		{
+			name:       "apache common with user and no body",
+			apache:     true,
+			line:       `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 304 -`,
+			wantResult: Matched,
+			wantLevel:  "INFO",
+			wantTime:   time.Date(2000, time.October, 10, 20, 55, 36, 0, time.UTC),
+			want: map[string]interface{}{
+				"client_ip": "127.0.0.1", "user": "frank", "method": "GET", "path": "/apache_pb.gif", "protocol": "HTTP/1.0",
+				"status": 304,
+			},
+		},
+		{
+			name:       "apache microsecond latency",
+			apache:     true,
+			line:       prefix + `"GET /a HTTP/1.1" 200 2326 "-" "-" 1500`,
+			wantResult: Matched,
+			wantLevel:  "INFO",
+			wantTime:   stamp,
+			want: map[string]interface{}{
+				"client_ip": "203.0.113.7", "method": "GET", "path": "/a", "protocol": "HTTP/1.1",
+				"status": 200, "bytes": int64(2326), "latency_ms": 1.5,
+			},
+		},
+		{
+			name:       "configured latency unit",
+			cfg:        AccessConfig{LatencyUnit: "ms"},
+			line:       prefix + `"GET /a HTTP/1.1" 200 1 "-" "-" duration=42`,
+			wantResult: Matched,
+			wantLevel:  "INFO",
+			wantTime:   stamp,
+			want: map[string]interface{}{
+				"client_ip": "203.0.113.7", "method": "GET", "path": "/a", "protocol": "HTTP/1.1",
+				"status": 200, "bytes": int64(1), "latency_ms": 42.0,
+			},
+		},
+		{
+			name:       "escaped quotes",
+			apache:     true,
+			line:       prefix + `"GET /a\"b HTTP/1.1" 200 1 "-" "say \x22hi\x22"`,
+			wantResult: Matched,
+			wantLevel:  "INFO",
+			wantTime:   stamp,
+			want: map[string]interface{}{
+				"client_ip": "203.0.113.7", "method": "GET", "path": `/a"b`, "protocol": "HTTP/1.1",
+				"status": 200, "bytes": int64(1), "user_agent": `say "hi"`,
+			},
+		},
+		{
+			name:       "malformed request",
+			line:       prefix + `"\x16\x03\x01" 400 157 "-" "-"`,
+			fields:     map[string]interface{}{"stream": "stdout"},
+			wantResult: Matched,
+			wantLevel:  "WARN",
+			wantTime:   stamp,
+			want: map[string]interface{}{
+				"stream": "stdout", "client_ip": "203.0.113.7", "request": `\x16\x03\x01`, "status": 400, "bytes": int64(157),
+			},
+		},
+		{name: "plain text", line: "server started", wantResult: NoMatch},
+		{name: "truncated line", line: prefix + `"GET / HTTP/1.1" 20`, wantResult: NoMatch},
+		{name: "unterminated request", line: prefix + `"GET / HTTP/1.1 200 5`, wantResult: NoMatch},
+		{name: "invalid timestamp", line: `203.0.113.7 - - [32/Jan/2024:03:04:05 +0000] "GET / HTTP/1.1" 200 5`, wantResult: NoMatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p *Access
+			var err error
+			if tt.apache {
+				p, err = NewApache(tt.cfg)
+			} else {
+				p, err = NewNginx(tt.cfg)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			entry := model.Log{Message: tt.line, Fields: tt.fields}
+			if result := p.Parse(&entry); result != tt.wantResult {
+				t.Fatalf("Parse() = %v, want %v", result, tt.wantResult)
+			}
+			if tt.wantResult == NoMatch {
+				tt.want = tt.fields
+			}
+			if entry.Message != tt.line || entry.Level != tt.wantLevel || !entry.Timestamp.Equal(tt.wantTime) {
+				t.Errorf("entry = %q %q %v, want the raw line, %q and %v", entry.Message, entry.Level, entry.Timestamp, tt.wantLevel, tt.wantTime)
+			}
+			if !reflect.DeepEqual(entry.Fields, tt.want) {
+				t.Errorf("fields = %v, want %v", entry.Fields, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewAccessRejectsUnknownUnit(t *testing.T) {
+	_, err := NewNginx(AccessConfig{LatencyUnit: "min"})
+	if err == nil || !strings.Contains(err.Error(), `unknown latency unit "min"`) {
+		t.Fatalf("NewNginx() error = %v, want an unknown unit error", err)
+	}
+}
@@ -0,0 +1,273 @@
+package parser
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"smart-log-viewer/server/internal/level"
+	"smart-log-viewer/server/internal/model"
+)
+
+const (
+	// partialTimeout is how long a partial line waits for its remainder
+	// before it is emitted on its own.
+	partialTimeout = 5 * time.Second
+
+	// maxPartialSize caps a reassembled line; longer lines are emitted early.
+	maxPartialSize = 1024 * 1024
+)
+
+var (
+	// kubeContainerLog matches /var/log/containers/<pod>_<namespace>_<container>-<id>.log.
+	kubeContainerLog = regexp.MustCompile(`^([^_]+)_([^_]+)_(.+)-([0-9a-f]{64})\.log$`)
+
+	// kubePodLog matches /var/log/pods/<namespace>_<pod>_<uid>/<container>/<n>.log.
+	kubePodLog = regexp.MustCompile(`/([^_/]+)_([^_/]+)_([0-9a-f-]+)/([^/]+)/[0-9]+\.log$`)
+
+	// dockerContainerLog matches /var/lib/docker/containers/<id>/<id>-json.log.
+	dockerContainerLog = regexp.MustCompile(`/([0-9a-f]{64})/[0-9a-f]{64}-json\.log$`)
+)
+
+// containerMetadata extracts pod, namespace, container and container ID
+// from the path of a container log file, caching the result per path.
+type containerMetadata map[string]map[string]string
+
+// lookup returns the metadata fields encoded in path.
+func (c containerMetadata) lookup(path string) map[string]string {
+	if fields, ok := c[path]; ok {
+		return fields
+	}
+
+	fields := make(map[string]string)
+	if m := kubeContainerLog.FindStringSubmatch(filepath.Base(path)); m != nil {
+		fields["pod"], fields["namespace"], fields["container"], fields["container_id"] = m[1], m[2], m[3], m[4]
+	} else if m := kubePodLog.FindStringSubmatch(path); m != nil {
+		fields["namespace"], fields["pod"], fields["pod_uid"], fields["container"] = m[1], m[2], m[3], m[4]
+	} else if m := dockerContainerLog.FindStringSubmatch(path); m != nil {
+		fields["container_id"] = m[1]
+	}
+	c[path] = fields
+	return fields
+}
+
+// apply adds the stream and container metadata to an entry. Lines written
+// to stderr are reported as errors, like the process source does.
+func (c containerMetadata) apply(entry *model.Log, stream string) {
+	if entry.Fields == nil {
+		entry.Fields = make(map[string]interface{})
+	}
+	entry.Fields["stream"] = stream
+	for key, value := range c.lookup(entry.Source) {
+		entry.Fields[key] = value
+	}
+
+	if stream == "stderr" {
+		entry.Level = level.Error
+	} else {
+		entry.Level = level.Info
+	}
+}
+
+// partialLine is a line whose remainder has not arrived yet.
+type partialLine struct {
+	entry model.Log
+	text  strings.Builder
+	since time.Time
+}
+
+// partials joins the pieces of lines that a container runtime split up,
+// keyed by source and stream. Parsers are only used from the goroutine
+// that runs their chain, so no locking is needed.
+type partials map[string]*partialLine
+
+// add buffers a piece of a line.
+// It reports false if the line grew too long and must be emitted now.
+func (p partials) add(key string, entry model.Log) bool {
+	line, ok := p[key]
+	if !ok {
+		line = &partialLine{entry: entry, since: time.Now()}
+		p[key] = line
+	}
+	line.text.WriteString(entry.Message)
+	return line.text.Len() < maxPartialSize
+}
+
+// complete prepends the buffered pieces of a line to its final piece.
+// The joined entry keeps the metadata of the first piece.
+func (p partials) complete(key string, entry *model.Log) {
+	line, ok := p[key]
+	if !ok {
+		return
+	}
+	delete(p, key)
+
+	line.text.WriteString(entry.Message)
+	*entry = line.entry
+	entry.Message = line.text.String()
+}
+
+// drain removes and returns buffered lines older than partialTimeout,
+// or all of them if force is true.
+func (p partials) drain(force bool) []model.Log {
+	var drained []model.Log
+	for key, line := range p {
+		if force || time.Since(line.since) >= partialTimeout {
+			entry := line.entry
+			entry.Message = line.text.String()
+			drained = append(drained, entry)
+			delete(p, key)
+		}
+	}
+	return drained
+}
+
+// Docker parses lines written by Docker's json-file logging driver:
+//
+//	{"log":"message\n","stream":"stdout","time":"2024-01-02T03:04:05.123456789Z"}
+//
+// Lines longer than 16 KiB are split by Docker into entries without a
+// trailing newline; those pieces are joined into a single entry.
+type Docker struct {
+	partials partials
+	metadata containerMetadata
+}
+
+// dockerLine is one line of a json-file log.
+type dockerLine struct {
+	Log    *string           `json:"log"`
+	Stream string            `json:"stream"`
+	Time   string            `json:"time"`
+	Attrs  map[string]string `json:"attrs"`
+}
+
+// NewDocker creates a Docker json-file parser.
+//
+// Returns:
+//   - *Docker: A new parser instance
+func NewDocker() *Docker {
+	return &Docker{
+		partials: make(partials),
+		metadata: make(containerMetadata),
+	}
+}
+
+// Parse unwraps a json-file line into the inner message.
+//
+// Parameters:
+//   - entry: The entry whose message is the raw line
+//
+// Returns:
+//   - Result: Unwrapped for a complete line, Pending for a partial one,
+//     NoMatch for anything else
+func (d *Docker) Parse(entry *model.Log) Result {
+	if !strings.HasPrefix(entry.Message, "{") {
+		return NoMatch
+	}
+	var line dockerLine
+	if err := json.Unmarshal([]byte(entry.Message), &line); err != nil || line.Log == nil {
+		return NoMatch
+	}
+
+	if timestamp, err := time.Parse(time.RFC3339Nano, line.Time); err == nil {
+		entry.Timestamp = timestamp
+	}
+	d.metadata.apply(entry, line.Stream)
+	for key, value := range line.Attrs {
+		entry.Fields[key] = value
+	}
+
+	key := entry.Source + "\x00" + line.Stream
+	if !strings.HasSuffix(*line.Log, "\n") {
+		entry.Message = *line.Log
+		if d.partials.add(key, *entry) {
+			return Pending
+		}
+		entry.Message = ""
+	} else {
+		entry.Message = strings.TrimSuffix(strings.TrimSuffix(*line.Log, "\n"), "\r")
+	}
+	d.partials.complete(key, entry)
+	return Unwrapped
+}
+
+// Drain returns partial lines whose remainder never arrived.
+func (d *Docker) Drain(force bool) []model.Log {
+	return d.partials.drain(force)
+}
+
+// CRI parses lines written by CRI container runtimes such as containerd
+// and CRI-O, the format of /var/log/containers on Kubernetes nodes:
+//
+//	2024-01-02T03:04:05.123456789Z stdout F message
+//
+// The tag is P for a partial line and F for the final piece; partial
+// pieces are joined into a single entry.
+type CRI struct {
+	partials partials
+	metadata containerMetadata
+}
+
+// NewCRI creates a CRI log parser.
+//
+// Returns:
+//   - *CRI: A new parser instance
+func NewCRI() *CRI {
+	return &CRI{
+		partials: make(partials),
+		metadata: make(containerMetadata),
+	}
+}
+
+// Parse unwraps a CRI line into the inner message.
+//
+// Parameters:
+//   - entry: The entry whose message is the raw line
+//
+// Returns:
+//   - Result: Unwrapped for a complete line, Pending for a partial one,
+//     NoMatch for anything else
+func (c *CRI) Parse(entry *model.Log) Result {
+	parts := strings.SplitN(entry.Message, " ", 4)
+	if len(parts) < 3 {
+		return NoMatch
+	}
+
+	timestamp, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return NoMatch
+	}
+	stream := parts[1]
+	if stream != "stdout" && stream != "stderr" {
+		return NoMatch
+	}
+	// The tag field may carry more tags after a colon; the first is P or F.
+	tag, _, _ := strings.Cut(parts[2], ":")
+	if tag != "P" && tag != "F" {
+		return NoMatch
+	}
+
+	entry.Timestamp = timestamp
+	entry.Message = ""
+	if len(parts) == 4 {
+		entry.Message = parts[3]
+	}
+	c.metadata.apply(entry, stream)
+
+	key := entry.Source + "\x00" + stream
+	if tag == "P" {
+		if c.partials.add(key, *entry) {
+			return Pending
+		}
+		entry.Message = ""
+	}
+	c.partials.complete(key, entry)
+	return Unwrapped
+}
+
+// Drain returns partial lines whose final piece never arrived.
+func (c *CRI) Drain(force bool) []model.Log {
+	return c.partials.drain(force)
+}
@@ -0,0 +1,290 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"smart-log-viewer/server/internal/model"
+)
+
+// parseLines runs lines of one source through p and returns the results
+// and the entries of the lines that were not pending.
+func parseLines(p Parser, source string, lines []string) ([]Result, []model.Log) {
+	var results []Result
+	var entries []model.Log
+	for _, line := range lines {
+		entry := model.Log{Message: line, Source: source}
+		result := p.Parse(&entry)
+		results = append(results, result)
+		if result != Pending {
+			entries = append(entries, entry)
+		}
+	}
+	return results, entries
+}
+
+func TestDocker(t *testing.T) {
+	stamp := time.Date(2024, time.January, 2, 3, 4, 5, 123456789, time.UTC)
+
+	tests := []struct {
+		name        string
+		source      string
+		lines       []string
+		wantResults []Result
+		want        []model.Log
+	}{
+		{
+			name:        "stdout line",
+			lines:       []string{`{"log":"hello\n","stream":"stdout","time":"2024-01-02T03:04:05.123456789Z"}`},
+			wantResults: []Result{Unwrapped},
+			want: []model.Log{{
+				Level: "INFO", Message: "hello", Timestamp: stamp,
+				Fields: map[string]interface{}{"stream": "stdout"},
+			}},
+		},
+		{
+			name:        "stderr line with attributes",
+			lines:       []string{`{"log":"boom\r\n","stream":"stderr","time":"2024-01-02T03:04:05.123456789Z","attrs":{"tag":"web"}}`},
+			wantResults: []Result{Unwrapped},
+			want: []model.Log{{
+				Level: "ERROR", Message: "boom", Timestamp: stamp,
+				Fields: map[string]interface{}{"stream": "stderr", "tag": "web"},
+			}},
+		},
+		{
+			name: "split line is joined",
+			lines: []string{
+				`{"log":"part one, ","stream":"stdout","time":"2024-01-02T03:04:05.123456789Z"}`,
+				`{"log":"part two\n","stream":"stdout","time":"2024-01-02T03:04:06Z"}`,
+			},
+			wantResults: []Result{Pending, Unwrapped},
+			want: []model.Log{{
+				Level: "INFO", Message: "part one, part two", Timestamp: stamp,
+				Fields: map[string]interface{}{"stream": "stdout"},
+			}},
+		},
+		{
+			name:        "kubernetes container metadata",
+			source:      "/var/log/containers/web-1_prod_nginx-" + strings.Repeat("a", 64) + ".log",
+			lines:       []string{`{"log":"x\n","stream":"stdout","time":"bad"}`},
+			wantResults: []Result{Unwrapped},
+			want: []model.Log{{
+				Level: "INFO", Message: "x",
+				Fields: map[string]interface{}{
+					"stream": "stdout", "pod": "web-1", "namespace": "prod",
+					"container": "nginx", "container_id": strings.Repeat("a", 64),
+				},
+			}},
+		},
+		{
+			name:        "not json",
+			lines:       []string{"plain text"},
+			wantResults: []Result{NoMatch},
+			want:        []model.Log{{Message: "plain text"}},
+		},
+		{
+			name:        "truncated json",
+			lines:       []string{`{"log":"hello\n","stream":"std`},
+			wantResults: []Result{NoMatch},
+			want:        []model.Log{{Message: `{"log":"hello\n","stream":"std`}},
+		},
+		{
+			name:        "json without log",
+			lines:       []string{`{"msg":"hello"}`},
+			wantResults: []Result{NoMatch},
+			want:        []model.Log{{Message: `{"msg":"hello"}`}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, entries := parseLines(NewDocker(), tt.source, tt.lines)
+			for i := range tt.want {
+				tt.want[i].Source = tt.source
+			}
+			if !reflect.DeepEqual(results, tt.wantResults) {
+				t.Errorf("results = %v, want %v", results, tt.wantResults)
+			}
+			if !reflect.DeepEqual(entries, tt.want) {
+				t.Errorf("entries = %+v, want %+v", entries, tt.want)
+			}
+		})
+	}
+}
+
+func TestCRI(t *testing.T) {
+	stamp := time.Date(2024, time.January, 2, 3, 4, 5, 123456789, time.UTC)
+	podPath := "/var/log/pods/prod_web-1_0f1e2d3c-aaaa-bbbb-cccc-1234567890ab/nginx/0.log"
+
+	tests := []struct {
+		name        string
+		source      string
+		lines       []string
+		wantResults []Result
+		want        []model.Log
+	}{
+		{
+			name:        "full line",
+			lines:       []string{"2024-01-02T03:04:05.123456789Z stdout F hello world"},
+			wantResults: []Result{Unwrapped},
+			want: []model.Log{{
+				Level: "INFO", Message: "hello world", Timestamp: stamp,
+				Fields: map[string]interface{}{"stream": "stdout"},
+			}},
+		},
+		{
+			name:        "empty stderr line",
+			lines:       []string{"2024-01-02T03:04:05.123456789Z stderr F"},
+			wantResults: []Result{Unwrapped},
+			want: []model.Log{{
+				Level: "ERROR", Timestamp: stamp,
+				Fields: map[string]interface{}{"stream": "stderr"},
+			}},
+		},
+		{
+			name:   "partial pieces are joined per stream",
+			source: podPath,
+			lines: []string{
+				"2024-01-02T03:04:05.123456789Z stdout P out one ",
+				"2024-01-02T03:04:05.2Z stderr F err",
+				"2024-01-02T03:04:05.3Z stdout P:more out two ",
+				"2024-01-02T03:04:05.4Z stdout F out three",
+			},
+			wantResults: []Result{Pending, Unwrapped, Pending, Unwrapped},
+			want: []model.Log{
+				{
+					Level: "ERROR", Message: "err", Timestamp: time.Date(2024, time.January, 2, 3, 4, 5, 2e8, time.UTC),
+					Fields: map[string]interface{}{
+						"stream": "stderr", "namespace": "prod", "pod": "web-1",
+						"pod_uid": "0f1e2d3c-aaaa-bbbb-cccc-1234567890ab", "container": "nginx",
+					},
+				},
+				{
+					Level: "INFO", Message: "out one out two out three", Timestamp: stamp,
+					Fields: map[string]interface{}{
+						"stream": "stdout", "namespace": "prod", "pod": "web-1",
+						"pod_uid": "0f1e2d3c-aaaa-bbbb-cccc-1234567890ab", "container": "nginx",
+					},
+				},
+			},
+		},
+		{
+			name:        "bad timestamp",
+			lines:       []string{"yesterday stdout F hello"},
+			wantResults: []Result{NoMatch},
+			want:        []model.Log{{Message: "yesterday stdout F hello"}},
+		},
+		{
+			name:        "unknown stream",
+			lines:       []string{"2024-01-02T03:04:05Z stdin F hello"},
+			wantResults: []Result{NoMatch},
+			want:        []model.Log{{Message: "2024-01-02T03:04:05Z stdin F hello"}},
+		},
+		{
+			name:        "unknown tag",
+			lines:       []string{"2024-01-02T03:04:05Z stdout X hello"},
+			wantResults: []Result{NoMatch},
+			want:        []model.Log{{Message: "2024-01-02T03:04:05Z stdout X hello"}},
+		},
+		{
+			name:        "truncated",
+			lines:       []string{"2024-01-02T03:04:05Z stdout"},
+			wantResults: []Result{NoMatch},
+			want:        []model.Log{{Message: "2024-01-02T03:04:05Z stdout"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, entries := parseLines(NewCRI(), tt.source, tt.lines)
+			for i := range tt.want {
+				tt.want[i].Source = tt.source
+			}
+			if !reflect.DeepEqual(results, tt.wantResults) {
+				t.Errorf("results = %v, want %v", results, tt.wantResults)
+			}
+			if !reflect.DeepEqual(entries, tt.want) {
+				t.Errorf("entries = %+v, want %+v", entries, tt.want)
+			}
+		})
+	}
+}
+
+func TestContainerMetadata(t *testing.T) {
+	id := strings.Repeat("0123456789abcdef", 4)
+
+	tests := []struct {
+		path string
+		want map[string]string
+	}{
+		{
+			path: "/var/log/containers/api-7d9f_default_app-" + id + ".log",
+			want: map[string]string{"pod": "api-7d9f", "namespace": "default", "container": "app", "container_id": id},
+		},
+		{
+			path: "/var/log/pods/kube-system_coredns-1_abc-123/coredns/2.log",
+			want: map[string]string{"namespace": "kube-system", "pod": "coredns-1", "pod_uid": "abc-123", "container": "coredns"},
+		},
+		{
+			path: "/var/lib/docker/containers/" + id + "/" + id + "-json.log",
+			want: map[string]string{"container_id": id},
+		},
+		{path: "/var/log/app.log", want: map[string]string{}},
+		{path: "", want: map[string]string{}},
+	}
+
+	metadata := make(containerMetadata)
+	for _, tt := range tests {
+		if got := metadata.lookup(tt.path); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("lookup(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestContainerPartialsFlush(t *testing.T) {
+	tests := []struct {
+		name   string
+		parser Parser
+		line   string
+	}{
+		{name: "docker", parser: NewDocker(), line: `{"log":"never finished","stream":"stdout","time":"2024-01-02T03:04:05Z"}`},
+		{name: "cri", parser: NewCRI(), line: "2024-01-02T03:04:05Z stdout P never finished"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := Chain{tt.parser}
+			var got []string
+			next := func(entry model.Log) { got = append(got, entry.Message) }
+
+			chain.Process(model.Log{Message: tt.line, Source: "app"}, next)
+			chain.Flush(next, false)
+			if len(got) != 0 {
+				t.Fatalf("fresh partial line was flushed: %q", got)
+			}
+			chain.Flush(next, true)
+			if !reflect.DeepEqual(got, []string{"never finished"}) {
+				t.Errorf("forced flush = %q, want the partial line", got)
+			}
+		})
+	}
+}
+
+func TestContainerPartialTooLong(t *testing.T) {
+	piece := strings.Repeat("x", maxPartialSize/2)
+	cri := NewCRI()
+
+	_, entries := parseLines(cri, "app", []string{
+		"2024-01-02T03:04:05Z stdout P " + piece,
+		"2024-01-02T03:04:05Z stdout P " + piece,
+		"2024-01-02T03:04:05Z stdout F tail",
+	})
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want the overlong line and the tail", len(entries))
+	}
+	if len(entries[0].Message) != maxPartialSize || entries[1].Message != "tail" {
+		t.Errorf("messages of %d and %d bytes, want %d and 4", len(entries[0].Message), len(entries[1].Message), maxPartialSize)
+	}
+}
@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"smart-log-viewer/server/internal/model"
+)
+
+// JSONConfig holds the options of a JSON parser. The keys default to
+// "level", "lvl", "severity"; "msg", "message"; and "ts", "time", "@timestamp".
+type JSONConfig struct {
+	Keys
+}
+
+// JSON parses lines that are JSON objects, as written by structured
+// loggers such as zap, zerolog and slog's JSONHandler:
+//
+//	{"level":"info","ts":1704164645.123,"msg":"request served","status":200}
+//
+// The level, message and timestamp are taken from configurable keys and
+// every other key becomes a field.
+type JSON struct {
+	keys Keys
+}
+
+// NewJSON creates a JSON line parser.
+//
+// Parameters:
+//   - cfg: The parser options; empty key lists fall back to the defaults
+//
+// Returns:
+//   - *JSON: A new parser instance
+func NewJSON(cfg JSONConfig) *JSON {
+	return &JSON{keys: cfg.withDefaults(structuredKeys)}
+}
+
+// Parse decodes a JSON object line into the entry. A line without a
+// message key keeps the raw line as its message.
+//
+// Parameters:
+//   - entry: The entry whose message is the raw line
+//
+// Returns:
+//   - Result: Matched for a JSON object, NoMatch for anything else
+func (j *JSON) Parse(entry *model.Log) Result {
+	line := strings.TrimSpace(entry.Message)
+	if !strings.HasPrefix(line, "{") || !strings.HasSuffix(line, "}") {
+		return NoMatch
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader([]byte(line)))
+	decoder.UseNumber()
+	var obj map[string]interface{}
+	if err := decoder.Decode(&obj); err != nil || decoder.More() {
+		return NoMatch
+	}
+
+	j.keys.apply(entry, obj)
+	return Matched
+}
@@ -0,0 +1,109 @@
+package parser
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"smart-log-viewer/server/internal/model"
+)
+
+func TestJSON(t *testing.T) {
+	stamp := time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		cfg        JSONConfig
+		line       string
+		fields     map[string]interface{}
+		wantResult Result
+		want       model.Log
+	}{
+		{
+			name:       "zap line",
+			line:       `{"level":"info","ts":1704164645.123,"msg":"request served","status":200}`,
+			wantResult: Matched,
+			want: model.Log{
+				Level: "INFO", RawLevel: "info", Message: "request served", Timestamp: time.UnixMilli(1704164645123),
+				Fields: map[string]interface{}{"status": json.Number("200")},
+			},
+		},
+		{
+			name:       "slog line with nested attributes",
+			line:       ` {"time":"2024-01-02T03:04:05Z","level":"WARN","msg":"slow","http":{"status":200,"path":"/"}} `,
+			wantResult: Matched,
+			want: model.Log{
+				Level: "WARN", RawLevel: "WARN", Message: "slow", Timestamp: stamp,
+				Fields: map[string]interface{}{"http": map[string]interface{}{"status": json.Number("200"), "path": "/"}},
+			},
+		},
+		{
+			name:       "numeric syslog severity",
+			line:       `{"severity":3,"message":"disk full"}`,
+			wantResult: Matched,
+			want:       model.Log{Level: "ERROR", RawLevel: "3", Message: "disk full"},
+		},
+		{
+			name:       "first usable key wins",
+			line:       `{"msg":5,"message":"text","ts":"soon","time":"2024-01-02T03:04:05Z"}`,
+			wantResult: Matched,
+			want: model.Log{
+				Message: "text", Timestamp: stamp,
+				Fields: map[string]interface{}{"msg": json.Number("5"), "ts": "soon"},
+			},
+		},
+		{
+			name:       "unknown level stays a field",
+			line:       `{"level":"loud","msg":"m"}`,
+			wantResult: Matched,
+			want:       model.Log{Message: "m", Fields: map[string]interface{}{"level": "loud"}},
+		},
+		{
+			name:       "without message keeps the line",
+			line:       `{"level":"error","err":"EOF"}`,
+			wantResult: Matched,
+			want: model.Log{
+				Level: "ERROR", RawLevel: "error", Message: `{"level":"error","err":"EOF"}`,
+				Fields: map[string]interface{}{"err": "EOF"},
+			},
+		},
+		{
+			name:       "configured keys",
+			cfg:        JSONConfig{Keys{LevelKeys: []string{"sev"}, MessageKeys: []string{"text"}}},
+			line:       `{"sev":"W","text":"t","level":"info","ts":"2024-01-02T03:04:05Z"}`,
+			wantResult: Matched,
+			want: model.Log{
+				Level: "WARN", RawLevel: "W", Message: "t", Timestamp: stamp,
+				Fields: map[string]interface{}{"level": "info"},
+			},
+		},
+		{
+			name:       "fields of an unwrapped envelope are kept",
+			line:       `{"msg":"m","user":"bob"}`,
+			fields:     map[string]interface{}{"stream": "stderr"},
+			wantResult: Matched,
+			want:       model.Log{Message: "m", Fields: map[string]interface{}{"stream": "stderr", "user": "bob"}},
+		},
+		{name: "plain text", line: "GET / 200", wantResult: NoMatch, want: model.Log{Message: "GET / 200"}},
+		{name: "array", line: `[1,2]`, wantResult: NoMatch, want: model.Log{Message: `[1,2]`}},
+		{name: "truncated object", line: `{"msg":"cut`, wantResult: NoMatch, want: model.Log{Message: `{"msg":"cut`}},
+		{name: "malformed object", line: `{"msg":}`, wantResult: NoMatch, want: model.Log{Message: `{"msg":}`}},
+		{name: "two objects", line: `{"msg":"a"} {"msg":"b"}`, wantResult: NoMatch, want: model.Log{Message: `{"msg":"a"} {"msg":"b"}`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := model.Log{Message: tt.line, Fields: tt.fields}
+			if result := NewJSON(tt.cfg).Parse(&entry); result != tt.wantResult {
+				t.Fatalf("Parse() = %v, want %v", result, tt.wantResult)
+			}
+			if tt.wantResult == NoMatch {
+				tt.want.Fields = tt.fields
+			}
+			if !reflect.DeepEqual(entry, tt.want) {
+				t.Errorf("entry = %+v, want %+v", entry, tt.want)
+			}
+		})
+	}
+}
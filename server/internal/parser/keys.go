@@ -0,0 +1,123 @@
+package parser
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"smart-log-viewer/server/internal/level"
+	"smart-log-viewer/server/internal/model"
+	"smart-log-viewer/server/internal/timestamp"
+)
+
+var (
+	// structuredKeys are the default keys of JSON lines, covering zap,
+	// zerolog, slog, logrus and ECS loggers.
+	structuredKeys = Keys{
+		LevelKeys:   []string{"level", "lvl", "severity"},
+		MessageKeys: []string{"msg", "message"},
+		TimeKeys:    []string{"ts", "time", "@timestamp"},
+	}
+
+	// logfmtKeys are the default keys of logfmt lines, which add Heroku's
+	// "at" to the level keys.
+	logfmtKeys = Keys{
+		LevelKeys:   []string{"level", "lvl", "severity", "at"},
+		MessageKeys: structuredKeys.MessageKeys,
+		TimeKeys:    structuredKeys.TimeKeys,
+	}
+)
+
+// Keys names the keys of a structured line that hold the level, message
+// and timestamp. Each list is tried in order; the first key present with
+// a usable value wins.
+type Keys struct {
+	// LevelKeys name the level.
+	LevelKeys []string `json:"levelKeys"`
+
+	// MessageKeys name the message.
+	MessageKeys []string `json:"messageKeys"`
+
+	// TimeKeys name the timestamp. Values may be RFC 3339 strings, Unix
+	// epoch numbers or any other timestamp format the viewer recognises.
+	TimeKeys []string `json:"timeKeys"`
+}
+
+// withDefaults fills the empty key lists from defaults.
+func (k Keys) withDefaults(defaults Keys) Keys {
+	if len(k.LevelKeys) == 0 {
+		k.LevelKeys = defaults.LevelKeys
+	}
+	if len(k.MessageKeys) == 0 {
+		k.MessageKeys = defaults.MessageKeys
+	}
+	if len(k.TimeKeys) == 0 {
+		k.TimeKeys = defaults.TimeKeys
+	}
+	return k
+}
+
+// apply moves the level, message and timestamp out of the decoded keys of
+// a line into the entry and adds the remaining keys as fields. Values that
+// cannot be used, such as an unknown level name, stay fields.
+//
+// Parameters:
+//   - entry: The entry to fill in
+//   - obj: The decoded keys of the line; it is modified
+func (k Keys) apply(entry *model.Log, obj map[string]interface{}) {
+	for _, key := range k.MessageKeys {
+		if text, ok := obj[key].(string); ok {
+			entry.Message = text
+			delete(obj, key)
+			break
+		}
+	}
+	for _, key := range k.LevelKeys {
+		if level.Apply(entry, levelText(obj[key])) {
+			delete(obj, key)
+			break
+		}
+	}
+	for _, key := range k.TimeKeys {
+		if t, ok := parseTime(obj[key]); ok {
+			entry.Timestamp = t
+			delete(obj, key)
+			break
+		}
+	}
+
+	if len(obj) > 0 && entry.Fields == nil {
+		entry.Fields = make(map[string]interface{}, len(obj))
+	}
+	for key, value := range obj {
+		entry.Fields[key] = value
+	}
+}
+
+// levelText returns a level value as text. Levels are usually names, but
+// some loggers write syslog severities as numbers.
+func levelText(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case int64:
+		return strconv.FormatInt(v, 10)
+	default:
+		return ""
+	}
+}
+
+// parseTime parses a timestamp value: an RFC 3339 string or epoch number,
+// or a string in one of the formats the timestamp package recognises, such
+// as the Common Log Format.
+func parseTime(value interface{}) (time.Time, bool) {
+	if t, ok := timestamp.Value(value); ok {
+		return t, true
+	}
+	if text, ok := value.(string); ok {
+		return timestamp.Find(text, time.Local, time.Now())
+	}
+	return time.Time{}, false
+}
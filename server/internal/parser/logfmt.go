@@ -0,0 +1,134 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+
+	"smart-log-viewer/server/internal/model"
+)
+
+// LogfmtConfig holds the options of a logfmt parser. The keys default to
+// those of the JSON parser, with "at" added to the level keys.
+type LogfmtConfig struct {
+	Keys
+}
+
+// Logfmt parses key=value lines in the logfmt format, as written by
+// Go kit, Heroku and logrus's TextFormatter:
+//
+//	time="2024-01-02T03:04:05Z" level=warning msg="disk \"/\" almost full" used=91% readonly
+//
+// Values may be quoted, with backslash escapes, and keys without a value
+// are recorded as true. The level, message and timestamp are taken from
+// configurable keys and every other key becomes a field.
+type Logfmt struct {
+	keys Keys
+}
+
+// NewLogfmt creates a logfmt parser.
+//
+// Parameters:
+//   - cfg: The parser options; empty key lists fall back to the defaults
+//
+// Returns:
+//   - *Logfmt: A new parser instance
+func NewLogfmt(cfg LogfmtConfig) *Logfmt {
+	return &Logfmt{keys: cfg.withDefaults(logfmtKeys)}
+}
+
+// Parse decodes a logfmt line into the entry. A line without a message
+// key keeps the raw line as its message.
+//
+// Parameters:
+//   - entry: The entry whose message is the raw line
+//
+// Returns:
+//   - Result: Matched for a logfmt line, NoMatch for anything else
+func (l *Logfmt) Parse(entry *model.Log) Result {
+	obj, ok := decodeLogfmt(entry.Message)
+	if !ok {
+		return NoMatch
+	}
+	l.keys.apply(entry, obj)
+	return Matched
+}
+
+// decodeLogfmt splits a logfmt line into its keys and values. Since any
+// run of words is valid logfmt made of bare keys, a line only counts as
+// logfmt if it starts with a key=value pair, which every logfmt logger
+// writes first.
+//
+// Parameters:
+//   - line: The raw line
+//
+// Returns:
+//   - map[string]interface{}: The values keyed by key; later duplicates win
+//   - bool: false if the line is not logfmt
+func decodeLogfmt(line string) (map[string]interface{}, bool) {
+	obj := make(map[string]interface{})
+	i, first := 0, true
+	for {
+		for i < len(line) && (line[i] == ' ' || line[i] == '\t') {
+			i++
+		}
+		if i >= len(line) {
+			break
+		}
+
+		start := i
+		for i < len(line) && line[i] > ' ' && line[i] != '=' && line[i] != '"' {
+			i++
+		}
+		key := line[start:i]
+		if key == "" {
+			return nil, false
+		}
+
+		if i >= len(line) || line[i] != '=' {
+			if first || (i < len(line) && line[i] == '"') {
+				return nil, false
+			}
+			obj[key] = true
+			continue
+		}
+		i++
+		first = false
+
+		if i < len(line) && line[i] == '"' {
+			end := closingQuote(line, i)
+			if end < 0 {
+				return nil, false
+			}
+			quoted := line[i : end+1]
+			value, err := strconv.Unquote(quoted)
+			if err != nil {
+				// Not every logger escapes like Go does; keep the raw text.
+				value = strings.ReplaceAll(quoted[1:len(quoted)-1], `\"`, `"`)
+			}
+			obj[key] = value
+			i = end + 1
+			continue
+		}
+
+		start = i
+		for i < len(line) && line[i] != ' ' && line[i] != '\t' {
+			i++
+		}
+		obj[key] = line[start:i]
+	}
+	return obj, !first
+}
+
+// closingQuote returns the index of the quote that ends the quoted value
+// starting at open, skipping escaped quotes, or -1 if it is unterminated.
+func closingQuote(line string, open int) int {
+	for i := open + 1; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
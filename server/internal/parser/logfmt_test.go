@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"smart-log-viewer/server/internal/model"
+)
+
+func TestLogfmt(t *testing.T) {
+	stamp := time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		cfg        LogfmtConfig
+		line       string
+		wantResult Result
+		want       model.Log
+	}{
+		{
+			name:       "logrus line",
+			line:       `time="2024-01-02T03:04:05Z" level=warning msg="disk \"/\" almost full" used=91% readonly`,
+			wantResult: Matched,
+			want: model.Log{
+				Level: "WARN", RawLevel: "warning", Message: `disk "/" almost full`, Timestamp: stamp,
+				Fields: map[string]interface{}{"used": "91%", "readonly": true},
+			},
+		},
+		{
+			name:       "heroku router line keeps the line",
+			line:       "at=info method=GET\tpath=/",
+			wantResult: Matched,
+			want: model.Log{
+				Level: "INFO", RawLevel: "info", Message: "at=info method=GET\tpath=/",
+				Fields: map[string]interface{}{"method": "GET", "path": "/"},
+			},
+		},
+		{
+			name:       "epoch timestamp",
+			line:       "ts=1704164645 msg=started",
+			wantResult: Matched,
+			want:       model.Log{Message: "started", Timestamp: time.Unix(1704164645, 0)},
+		},
+		{
+			name:       "empty values and duplicates",
+			line:       "a=1 msg= a=2 b=",
+			wantResult: Matched,
+			want:       model.Log{Fields: map[string]interface{}{"a": "2", "b": ""}},
+		},
+		{
+			name:       "escapes Go does not know are kept",
+			line:       `msg="C:\temp\x \"quoted\""`,
+			wantResult: Matched,
+			want:       model.Log{Message: `C:\temp\x "quoted"`},
+		},
+		{
+			name:       "configured keys",
+			cfg:        LogfmtConfig{Keys{MessageKeys: []string{"event"}}},
+			line:       "event=login msg=ignored lvl=E",
+			wantResult: Matched,
+			want: model.Log{
+				Level: "ERROR", RawLevel: "E", Message: "login",
+				Fields: map[string]interface{}{"msg": "ignored"},
+			},
+		},
+		{name: "empty", line: "", wantResult: NoMatch},
+		{name: "plain text", line: "starting server", wantResult: NoMatch},
+		{name: "bare key first", line: "ready level=info", wantResult: NoMatch},
+		{name: "missing key", line: "=value", wantResult: NoMatch},
+		{name: "unterminated quote", line: `level=info msg="cut`, wantResult: NoMatch},
+		{name: "quote inside key", line: `level=info key"x"=1`, wantResult: NoMatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := model.Log{Message: tt.line}
+			if result := NewLogfmt(tt.cfg).Parse(&entry); result != tt.wantResult {
+				t.Fatalf("Parse() = %v, want %v", result, tt.wantResult)
+			}
+			if tt.wantResult == NoMatch {
+				tt.want = model.Log{Message: tt.line}
+			}
+			if !reflect.DeepEqual(entry, tt.want) {
+				t.Errorf("entry = %+v, want %+v", entry, tt.want)
+			}
+		})
+	}
+}
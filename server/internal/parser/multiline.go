@@ -0,0 +1,244 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"smart-log-viewer/server/internal/config"
+	"smart-log-viewer/server/internal/model"
+)
+
+const (
+	// defaultMultilineTimeout is how long an event waits for another line
+	// before it is emitted.
+	defaultMultilineTimeout = time.Second
+
+	// defaultMaxLines caps the lines joined into one event.
+	defaultMaxLines = 500
+)
+
+var (
+	// javaContinuation matches the unindented lines of a Java stack trace
+	// that follow its first line.
+	javaContinuation = regexp.MustCompile(`^(?:Caused by: |Suppressed: |\.\.\. \d+ (?:more|common frames omitted))`)
+
+	// javaException matches the exception that loggers such as Logback and
+	// Log4j write on the line after the message, e.g.
+	// "java.lang.IllegalStateException: closed".
+	javaException = regexp.MustCompile(`^(?:[a-zA-Z_$][\w$]*\.)+[A-Z][\w$]*(?:Exception|Error|Throwable)(?::|$)`)
+
+	// pythonTraceback starts the traceback that Python's logging appends
+	// to a message.
+	pythonTraceback = regexp.MustCompile(`^Traceback \(most recent call last\):$`)
+
+	// pythonChained matches the lines that separate chained exceptions.
+	pythonChained = regexp.MustCompile(`^(?:During handling of the above exception|The above exception was the direct cause)`)
+
+	// pythonException matches the exception that ends a traceback, e.g.
+	// "ValueError: bad value" or "requests.exceptions.Timeout".
+	pythonException = regexp.MustCompile(`^[A-Za-z_][\w.]*(?::|$)`)
+
+	// goGoroutine starts the stack of a goroutine in a Go panic.
+	goGoroutine = regexp.MustCompile(`^goroutine \d+ \[[^\]]*\]:$`)
+
+	// goPanic matches the first line of a Go panic or runtime fatal error.
+	goPanic = regexp.MustCompile(`^(?:panic: |fatal error: )`)
+
+	// goStackLine matches the unindented lines of a goroutine stack: the
+	// function calls, "created by" and the status a crashed program exits with.
+	goStackLine = regexp.MustCompile(`^(?:[\w./*()\[\]{}-]+\(.*\)$|created by |exit status \d+$|\[signal )`)
+)
+
+// MultilineConfig holds the options of a multiline parser.
+type MultilineConfig struct {
+	// StartPattern is a regular expression matching the first line of an
+	// event; every other line continues the event before it. Without it,
+	// indented lines and Java, Python and Go stack traces are joined.
+	StartPattern string `json:"startPattern"`
+
+	// Timeout is how long an event waits for its next line before it is
+	// emitted. It defaults to one second.
+	Timeout config.Duration `json:"timeout"`
+
+	// MaxLines caps the lines of an event; it defaults to 500.
+	MaxLines int `json:"maxLines"`
+}
+
+// traceKind tells which kind of stack trace an event is in, which decides
+// the unindented lines that continue it.
+type traceKind int
+
+const (
+	noTrace traceKind = iota
+	pythonTrace
+	goTrace
+)
+
+// multilineEvent is an event whose lines are still being collected.
+type multilineEvent struct {
+	entry    model.Log
+	text     strings.Builder
+	lines    int
+	trace    traceKind
+	indented bool
+	updated  time.Time
+}
+
+// Multiline joins the lines of an event, such as a message followed by a
+// stack trace, into a single entry:
+//
+//	2024-01-02 03:04:05 ERROR request failed
+//	java.lang.IllegalStateException: closed
+//		at com.example.Handler.serve(Handler.java:42)
+//	Caused by: java.io.IOException: broken pipe
+//		... 3 more
+//
+// An event is emitted once the first line of the next one arrives, or
+// when no line arrived for the timeout. The joined entry keeps the level,
+// timestamp and fields of its first line. Parsers that follow see the
+// first line, so patterns written for single lines still apply.
+type Multiline struct {
+	start    *regexp.Regexp
+	timeout  time.Duration
+	maxLines int
+	events   map[string]*multilineEvent
+}
+
+// NewMultiline creates a multiline parser.
+//
+// Parameters:
+//   - cfg: The parser options
+//
+// Returns:
+//   - *Multiline: A new parser instance
+//   - error: nil on success, or an error for an invalid start pattern
+func NewMultiline(cfg MultilineConfig) (*Multiline, error) {
+	m := &Multiline{
+		timeout:  cfg.Timeout.Duration,
+		maxLines: cfg.MaxLines,
+		events:   make(map[string]*multilineEvent),
+	}
+	if m.timeout <= 0 {
+		m.timeout = defaultMultilineTimeout
+	}
+	if m.maxLines <= 0 {
+		m.maxLines = defaultMaxLines
+	}
+	if cfg.StartPattern != "" {
+		start, err := regexp.Compile(cfg.StartPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid multiline start pattern: %w", err)
+		}
+		m.start = start
+	}
+	return m, nil
+}
+
+// Parse adds a line to the event it belongs to. A line that starts a new
+// event takes the place of the previous event, which is passed on.
+//
+// Parameters:
+//   - entry: The entry whose message is the raw line
+//
+// Returns:
+//   - Result: Unwrapped if entry now holds a complete event, Pending otherwise
+func (m *Multiline) Parse(entry *model.Log) Result {
+	// Container runtimes interleave stdout and stderr in one file.
+	key := entry.Source
+	if stream, ok := entry.Fields["stream"].(string); ok {
+		key += "\x00" + stream
+	}
+
+	event, ok := m.events[key]
+	if ok && m.continues(event, entry.Message) {
+		if event.lines < m.maxLines && event.text.Len()+len(entry.Message) < maxPartialSize {
+			event.add(entry.Message, m.start == nil)
+			return Pending
+		}
+	}
+
+	m.events[key] = newMultilineEvent(*entry, m.start == nil)
+	if !ok {
+		return Pending
+	}
+	*entry = event.complete()
+	return Unwrapped
+}
+
+// Drain returns events that received no line for the timeout.
+func (m *Multiline) Drain(force bool) []model.Log {
+	var drained []model.Log
+	for key, event := range m.events {
+		if force || time.Since(event.updated) >= m.timeout {
+			drained = append(drained, event.complete())
+			delete(m.events, key)
+		}
+	}
+	return drained
+}
+
+// continues reports whether line belongs to event rather than starting
+// a new one.
+func (m *Multiline) continues(event *multilineEvent, line string) bool {
+	if m.start != nil {
+		return !m.start.MatchString(line)
+	}
+	if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+		return true
+	}
+
+	switch event.trace {
+	case pythonTrace:
+		if line == "" || pythonChained.MatchString(line) || pythonTraceback.MatchString(line) {
+			return true
+		}
+		// The exception follows the indented frames.
+		return event.indented && pythonException.MatchString(line)
+	case goTrace:
+		return line == "" || goGoroutine.MatchString(line) || goStackLine.MatchString(line)
+	}
+	if javaContinuation.MatchString(line) || javaException.MatchString(line) ||
+		pythonTraceback.MatchString(line) || goGoroutine.MatchString(line) {
+		return true
+	}
+	// A panic is separated from its goroutines by a blank line.
+	return line == "" && event.lines == 1 && goPanic.MatchString(event.entry.Message)
+}
+
+// newMultilineEvent starts an event with its first line.
+func newMultilineEvent(entry model.Log, traces bool) *multilineEvent {
+	event := &multilineEvent{entry: entry}
+	event.add(entry.Message, traces)
+	return event
+}
+
+// add appends a line to the event. When traces is set, it also tracks
+// which kind of stack trace the event is in.
+func (e *multilineEvent) add(line string, traces bool) {
+	if e.lines > 0 {
+		e.text.WriteByte('\n')
+	}
+	e.text.WriteString(line)
+	e.lines++
+	e.updated = time.Now()
+
+	if !traces {
+		return
+	}
+	switch {
+	case pythonTraceback.MatchString(line):
+		e.trace = pythonTrace
+	case goGoroutine.MatchString(line):
+		e.trace = goTrace
+	}
+	e.indented = strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")
+}
+
+// complete returns the joined entry.
+func (e *multilineEvent) complete() model.Log {
+	entry := e.entry
+	entry.Message = e.text.String()
+	return entry
+}
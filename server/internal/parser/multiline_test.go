@@ -0,0 +1,235 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"smart-log-viewer/server/internal/config"
+	"smart-log-viewer/server/internal/model"
+)
+
+func TestMultiline(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  MultilineConfig
+		// lines are parsed in order, then the parser is drained.
+		lines []string
+		want  []string
+	}{
+		{
+			name: "java stack trace",
+			lines: []string{
+				"2024-01-02 03:04:05 ERROR request failed",
+				"java.lang.IllegalStateException: closed",
+				"\tat com.example.Handler.serve(Handler.java:42)",
+				"Caused by: java.io.IOException: broken pipe",
+				"\t... 3 more",
+				"2024-01-02 03:04:06 INFO next",
+			},
+			want: []string{
+				"2024-01-02 03:04:05 ERROR request failed\njava.lang.IllegalStateException: closed\n" +
+					"\tat com.example.Handler.serve(Handler.java:42)\nCaused by: java.io.IOException: broken pipe\n\t... 3 more",
+				"2024-01-02 03:04:06 INFO next",
+			},
+		},
+		{
+			name: "python chained traceback",
+			lines: []string{
+				"ERROR handler failed",
+				"Traceback (most recent call last):",
+				`  File "app.py", line 3, in <module>`,
+				"    main()",
+				"ValueError: bad value",
+				"",
+				"During handling of the above exception, another exception occurred:",
+				"",
+				"Traceback (most recent call last):",
+				`  File "app.py", line 5, in <module>`,
+				"KeyError: 'x'",
+				"INFO next",
+			},
+			want: []string{
+				"ERROR handler failed\nTraceback (most recent call last):\n  File \"app.py\", line 3, in <module>\n    main()\n" +
+					"ValueError: bad value\n\nDuring handling of the above exception, another exception occurred:\n\n" +
+					"Traceback (most recent call last):\n  File \"app.py\", line 5, in <module>\nKeyError: 'x'",
+				"INFO next",
+			},
+		},
+		{
+			name: "go panic",
+			lines: []string{
+				"panic: boom",
+				"",
+				"goroutine 1 [running]:",
+				"main.main()",
+				"\t/app/main.go:5 +0x25",
+				"exit status 2",
+				"next line",
+			},
+			want: []string{
+				"panic: boom\n\ngoroutine 1 [running]:\nmain.main()\n\t/app/main.go:5 +0x25\nexit status 2",
+				"next line",
+			},
+		},
+		{
+			name:  "blank line after a plain message starts an event",
+			lines: []string{"first", "", "second"},
+			want:  []string{"first", "", "second"},
+		},
+		{
+			name:  "start pattern",
+			cfg:   MultilineConfig{StartPattern: `^\[`},
+			lines: []string{"[1] a", "b", "  c", "[2] d", "[3] e"},
+			want:  []string{"[1] a\nb\n  c", "[2] d", "[3] e"},
+		},
+		{
+			name:  "continuation before any event",
+			cfg:   MultilineConfig{StartPattern: `^\[`},
+			lines: []string{"orphan", "[1] a"},
+			want:  []string{"orphan", "[1] a"},
+		},
+		{
+			name:  "line cap",
+			cfg:   MultilineConfig{MaxLines: 2},
+			lines: []string{"a", " b", " c", " d"},
+			want:  []string{"a\n b", " c\n d"},
+		},
+		{
+			name:  "size cap",
+			lines: []string{"a", " " + strings.Repeat("b", maxPartialSize), " c"},
+			want:  []string{"a", " " + strings.Repeat("b", maxPartialSize), " c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewMultiline(tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, entries := parseLines(m, "app", tt.lines)
+			entries = append(entries, m.Drain(true)...)
+
+			var got []string
+			for _, entry := range entries {
+				got = append(got, entry.Message)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("events = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMultilineKeepsStreamsApart(t *testing.T) {
+	m, err := NewMultiline(MultilineConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := []struct{ source, stream, line string }{
+		{"a", "stdout", "request served"},
+		{"a", "stderr", "panic: boom"},
+		{"b", "", "other file"},
+		{"a", "stderr", ""},
+		{"a", "stdout", "  indented"},
+		{"a", "stderr", "goroutine 1 [running]:"},
+	}
+	for _, l := range lines {
+		entry := model.Log{Message: l.line, Source: l.source}
+		if l.stream != "" {
+			entry.Fields = map[string]interface{}{"stream": l.stream}
+		}
+		if result := m.Parse(&entry); result != Pending {
+			t.Fatalf("Parse(%q) = %v, want Pending", l.line, result)
+		}
+	}
+
+	got := make(map[string]string)
+	for _, entry := range m.Drain(true) {
+		stream, _ := entry.Fields["stream"].(string)
+		got[entry.Source+"/"+stream] = entry.Message
+	}
+	want := map[string]string{
+		"a/stdout": "request served\n  indented",
+		"a/stderr": "panic: boom\n\ngoroutine 1 [running]:",
+		"b/":       "other file",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+}
+
+func TestMultilineDrainsAfterTimeout(t *testing.T) {
+	m, err := NewMultiline(MultilineConfig{Timeout: config.Duration{Duration: 20 * time.Millisecond}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parseLines(m, "app", []string{"first", "  second"})
+
+	if drained := m.Drain(false); len(drained) != 0 {
+		t.Fatalf("drained %d events before the timeout", len(drained))
+	}
+	time.Sleep(30 * time.Millisecond)
+	drained := m.Drain(false)
+	if len(drained) != 1 || drained[0].Message != "first\n  second" {
+		t.Fatalf("drained %+v, want the joined event", drained)
+	}
+	if drained = m.Drain(true); len(drained) != 0 {
+		t.Errorf("drained %d events twice", len(drained))
+	}
+}
+
+func TestNewMultilineRejectsInvalidPattern(t *testing.T) {
+	_, err := NewMultiline(MultilineConfig{StartPattern: `^[`})
+	if err == nil || !strings.Contains(err.Error(), "invalid multiline start pattern") {
+		t.Fatalf("NewMultiline() error = %v, want an invalid pattern error", err)
+	}
+}
+
+func TestChainParsesFirstLineOfJoinedEvents(t *testing.T) {
+	multiline, err := NewMultiline(MultilineConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	grok, err := NewGrok(GrokConfig{Pattern: `^%{TIMESTAMP_ISO8601:timestamp} %{LOGLEVEL:level} %{GREEDYDATA:message}$`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := Chain{multiline, grok}
+
+	var got []model.Log
+	next := func(entry model.Log) { got = append(got, entry) }
+	for _, line := range []string{
+		"2024-01-02T03:04:05Z ERROR request failed",
+		"java.io.IOException: broken pipe",
+		"\tat com.example.Handler.serve(Handler.java:42)",
+		"2024-01-02T03:04:06Z INFO next",
+		"garbage",
+	} {
+		chain.Process(model.Log{Message: line, Source: "app"}, next)
+	}
+	chain.Flush(next, true)
+
+	want := []struct {
+		level, message string
+		unparsed       bool
+	}{
+		{"ERROR", "request failed\njava.io.IOException: broken pipe\n\tat com.example.Handler.serve(Handler.java:42)", false},
+		{"INFO", "next", false},
+		{"", "garbage", true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		unparsed := got[i].Fields[unparsedField] == true
+		if got[i].Level != w.level || got[i].Message != w.message || unparsed != w.unparsed {
+			t.Errorf("entry %d = %q %q unparsed=%v, want %q %q unparsed=%v",
+				i, got[i].Level, got[i].Message, unparsed, w.level, w.message, w.unparsed)
+		}
+	}
+}
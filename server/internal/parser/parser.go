@@ -0,0 +1,209 @@
+// Package parser turns raw log lines into structured log entries.
+// Parsers are combined into a Chain, which the source registry runs as a
+// processing stage between a source and the connection hub.
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"smart-log-viewer/server/internal/config"
+	"smart-log-viewer/server/internal/model"
+)
+
+// Result tells the chain what a parser did with an entry.
+type Result int
+
+const (
+	// NoMatch means the line is not in the parser's format; the entry is
+	// left untouched and the next parser is tried.
+	NoMatch Result = iota
+
+	// Matched means the entry was parsed; the remaining parsers are skipped.
+	Matched
+
+	// Unwrapped means an envelope such as a container runtime format was
+	// removed, or the lines of an event were joined; the remaining parsers
+	// see the new message.
+	Unwrapped
+
+	// Pending means the parser buffered the entry, e.g. a partial line,
+	// and nothing is emitted for it yet.
+	Pending
+)
+
+// Parser parses the raw line in entry.Message and fills in the entry.
+// Parsers that buffer entries must key their state by entry.Source,
+// since one source can interleave lines from several streams.
+type Parser interface {
+	Parse(entry *model.Log) Result
+}
+
+// strict is implemented by parsers that every line is expected to match.
+// A chain that contains one flags the lines no parser matched.
+type strict interface {
+	strict()
+}
+
+// Drainer is implemented by parsers that buffer entries.
+type Drainer interface {
+	// Drain returns buffered entries that have waited too long,
+	// or all buffered entries if force is true.
+	Drain(force bool) []model.Log
+}
+
+// Chain runs parsers in order. It implements source.Stage and source.Flusher.
+type Chain []Parser
+
+// Process parses one entry and passes it on unless a parser buffered it.
+//
+// Parameters:
+//   - entry: The entry to parse
+//   - next: Receives the parsed entry
+func (c Chain) Process(entry model.Log, next func(model.Log)) {
+	c.run(0, entry, next)
+}
+
+// run applies the parsers starting at index start.
+func (c Chain) run(start int, entry model.Log, next func(model.Log)) {
+	for _, p := range c[start:] {
+		switch parse(p, &entry) {
+		case Matched:
+			next(entry)
+			return
+		case Pending:
+			return
+		}
+	}
+	if c.strict() {
+		if entry.Fields == nil {
+			entry.Fields = make(map[string]interface{})
+		}
+		entry.Fields[unparsedField] = true
+	}
+	next(entry)
+}
+
+// parse runs one parser. Parsers that do not buffer entries see only the
+// first line of a joined multi-line entry, and the remaining lines are
+// appended to the message they produce, so a pattern written for single
+// lines still matches the line that heads a stack trace.
+func parse(p Parser, entry *model.Log) Result {
+	if _, buffers := p.(Drainer); buffers {
+		return p.Parse(entry)
+	}
+	first, rest, joined := strings.Cut(entry.Message, "\n")
+	if !joined {
+		return p.Parse(entry)
+	}
+
+	original := entry.Message
+	entry.Message = first
+	result := p.Parse(entry)
+	if result == NoMatch {
+		entry.Message = original
+		return result
+	}
+	entry.Message += "\n" + rest
+	return result
+}
+
+// strict reports whether the chain contains a parser that every line is
+// expected to match.
+func (c Chain) strict() bool {
+	for _, p := range c {
+		if _, ok := p.(strict); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Flush passes on entries buffered by parsers. Drained entries continue
+// through the parsers that follow the one that buffered them.
+//
+// Parameters:
+//   - next: Receives the drained entries
+//   - force: Drain everything regardless of age, used at shutdown
+func (c Chain) Flush(next func(model.Log), force bool) {
+	for i, p := range c {
+		drainer, ok := p.(Drainer)
+		if !ok {
+			continue
+		}
+		for _, entry := range drainer.Drain(force) {
+			c.run(i+1, entry, next)
+		}
+	}
+}
+
+// Build creates the parser chain described by a source's configuration.
+//
+// Parameters:
+//   - specs: The parser configurations in order
+//
+// Returns:
+//   - Chain: The parser chain, empty if specs is empty
+//   - error: nil on success, or an error for unknown types and invalid options
+func Build(specs []config.ParserConfig) (Chain, error) {
+	chain := make(Chain, 0, len(specs))
+	for _, spec := range specs {
+		p, err := build(spec)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, p)
+	}
+	return chain, nil
+}
+
+// build creates a single parser.
+func build(spec config.ParserConfig) (Parser, error) {
+	switch spec.Type {
+	case "docker":
+		return NewDocker(), nil
+	case "cri":
+		return NewCRI(), nil
+	case "json":
+		var opts JSONConfig
+		if err := spec.Decode(&opts); err != nil {
+			return nil, err
+		}
+		return NewJSON(opts), nil
+	case "logfmt":
+		var opts LogfmtConfig
+		if err := spec.Decode(&opts); err != nil {
+			return nil, err
+		}
+		return NewLogfmt(opts), nil
+	case "regex":
+		var opts RegexConfig
+		if err := spec.Decode(&opts); err != nil {
+			return nil, err
+		}
+		return NewRegex(opts)
+	case "grok":
+		var opts GrokConfig
+		if err := spec.Decode(&opts); err != nil {
+			return nil, err
+		}
+		return NewGrok(opts)
+	case "nginx", "apache":
+		var opts AccessConfig
+		if err := spec.Decode(&opts); err != nil {
+			return nil, err
+		}
+		if spec.Type == "apache" {
+			return NewApache(opts)
+		}
+		return NewNginx(opts)
+	case "multiline":
+		var opts MultilineConfig
+		if err := spec.Decode(&opts); err != nil {
+			return nil, err
+		}
+		return NewMultiline(opts)
+	default:
+		return nil, fmt.Errorf("unknown parser type %q", spec.Type)
+	}
+}
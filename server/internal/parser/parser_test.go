@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"smart-log-viewer/server/internal/config"
+	"smart-log-viewer/server/internal/model"
+)
+
+func TestBuild(t *testing.T) {
+	tests := []struct {
+		name    string
+		specs   string
+		want    []string
+		wantErr string
+	}{
+		{name: "empty", specs: `[]`},
+		{name: "short form", specs: `["docker", "cri"]`, want: []string{"*parser.Docker", "*parser.CRI"}},
+		{name: "options", specs: `[{"type":"json","options":{"levelKeys":["severity"]}}]`, want: []string{"*parser.JSON"}},
+		{name: "unknown type", specs: `["xml"]`, wantErr: `unknown parser type "xml"`},
+		{name: "malformed options", specs: `[{"type":"json","options":{"levelKeys":"severity"}}]`, wantErr: "invalid options"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var specs []config.ParserConfig
+			if err := json.Unmarshal([]byte(tt.specs), &specs); err != nil {
+				t.Fatal(err)
+			}
+
+			chain, err := Build(specs)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Build() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, p := range chain {
+				got = append(got, reflect.TypeOf(p).String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Build() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChainUnwrapsBeforeParsing(t *testing.T) {
+	chain := Chain{NewCRI(), NewDocker()}
+
+	var got []model.Log
+	next := func(entry model.Log) { got = append(got, entry) }
+	// An unwrapped line is offered to the following parsers, and a line
+	// no parser matches is passed on unchanged.
+	chain.Process(model.Log{Message: `2024-01-02T03:04:05Z stdout F {"log":"inner\n","stream":"stderr"}`}, next)
+	chain.Process(model.Log{Message: "plain"}, next)
+
+	if len(got) != 2 {
+		t.Fatalf("got %d entries, want 2", len(got))
+	}
+	if got[0].Message != "inner" || got[0].Fields["stream"] != "stderr" {
+		t.Errorf("unwrapped entry = %+v, want the inner docker line", got[0])
+	}
+	if got[1].Message != "plain" || got[1].Fields != nil {
+		t.Errorf("unmatched entry = %+v, want it unchanged", got[1])
+	}
+}
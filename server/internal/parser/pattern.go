@@ -0,0 +1,158 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"smart-log-viewer/server/internal/model"
+)
+
+// unparsedField flags entries that passed a chain with a pattern parser
+// without any parser matching them.
+const unparsedField = "unparsed"
+
+// patternKeys are the default capture names of pattern parsers, following
+// the Logstash convention.
+var patternKeys = Keys{
+	LevelKeys:   []string{"level", "loglevel", "severity"},
+	MessageKeys: []string{"message", "msg"},
+	TimeKeys:    []string{"timestamp", "time", "ts"},
+}
+
+// RegexConfig holds the options of a regex parser. The keys default to
+// "level", "loglevel", "severity"; "message", "msg"; and "timestamp",
+// "time", "ts".
+type RegexConfig struct {
+	// Pattern is a regular expression in Go syntax whose named groups,
+	// e.g. (?P<status>\d+), become fields.
+	Pattern string `json:"pattern"`
+
+	Keys
+}
+
+// GrokConfig holds the options of a grok parser. The keys default to
+// those of the regex parser.
+type GrokConfig struct {
+	// Pattern is a grok pattern such as `%{IP:client} %{WORD:method}`.
+	// A reference may name a type, %{NUMBER:bytes:int}, to convert the
+	// captured text to an int or float.
+	Pattern string `json:"pattern"`
+
+	// Definitions adds named patterns that Pattern may refer to. They take
+	// precedence over the built-in library.
+	Definitions map[string]string `json:"definitions"`
+
+	Keys
+}
+
+// Pattern parses lines with an operator-supplied regular expression or
+// grok pattern. Captures become fields, except those named by the keys,
+// which set the level, message and timestamp; a line without a message
+// capture keeps the raw line as its message.
+//
+// Operators write patterns for formats they expect every line to be in,
+// so a chain with a pattern parser flags the lines that no parser matched
+// with the "unparsed" field instead of dropping them.
+type Pattern struct {
+	re       *regexp.Regexp
+	captures map[string]grokCapture
+	keys     Keys
+}
+
+// NewRegex creates a parser for a regular expression with named groups.
+//
+// Parameters:
+//   - cfg: The parser options
+//
+// Returns:
+//   - *Pattern: A new parser instance
+//   - error: nil on success, or an error for an invalid expression
+func NewRegex(cfg RegexConfig) (*Pattern, error) {
+	if cfg.Pattern == "" {
+		return nil, errors.New("regex parser needs a pattern")
+	}
+	re, err := regexp.Compile(cfg.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex pattern: %w", err)
+	}
+
+	captures := make(map[string]grokCapture)
+	for _, name := range re.SubexpNames() {
+		if name != "" {
+			captures[name] = grokCapture{field: name}
+		}
+	}
+	if len(captures) == 0 {
+		return nil, errors.New("regex pattern has no named groups")
+	}
+	return &Pattern{re: re, captures: captures, keys: cfg.withDefaults(patternKeys)}, nil
+}
+
+// NewGrok creates a parser for a grok pattern.
+//
+// Parameters:
+//   - cfg: The parser options
+//
+// Returns:
+//   - *Pattern: A new parser instance
+//   - error: nil on success, or an error for unknown patterns and invalid expressions
+func NewGrok(cfg GrokConfig) (*Pattern, error) {
+	if cfg.Pattern == "" {
+		return nil, errors.New("grok parser needs a pattern")
+	}
+	re, captures, err := compileGrok(cfg.Pattern, cfg.Definitions)
+	if err != nil {
+		return nil, err
+	}
+	return &Pattern{re: re, captures: captures, keys: cfg.withDefaults(patternKeys)}, nil
+}
+
+// Parse matches the pattern against the raw line.
+//
+// Parameters:
+//   - entry: The entry whose message is the raw line
+//
+// Returns:
+//   - Result: Matched if the pattern matches, NoMatch otherwise
+func (p *Pattern) Parse(entry *model.Log) Result {
+	m := p.re.FindStringSubmatchIndex(entry.Message)
+	if m == nil {
+		return NoMatch
+	}
+
+	obj := make(map[string]interface{})
+	for i, name := range p.re.SubexpNames() {
+		capture, ok := p.captures[name]
+		if !ok || m[2*i] < 0 {
+			continue
+		}
+		// A field captured twice keeps its first value.
+		if _, taken := obj[capture.field]; taken {
+			continue
+		}
+		obj[capture.field] = convert(entry.Message[m[2*i]:m[2*i+1]], capture.typ)
+	}
+	p.keys.apply(entry, obj)
+	return Matched
+}
+
+// strict marks pattern parsers for Chain, which then flags unmatched lines.
+func (p *Pattern) strict() {}
+
+// convert converts captured text to the type named in a grok reference,
+// keeping the text if it does not parse.
+func convert(text, typ string) interface{} {
+	switch typ {
+	case "int":
+		if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return n
+		}
+	case "float":
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			return f
+		}
+	}
+	return text
+}
@@ -0,0 +1,220 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"smart-log-viewer/server/internal/model"
+)
+
+func TestNewPattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		regex   *RegexConfig
+		grok    *GrokConfig
+		wantErr string
+	}{
+		{name: "regex", regex: &RegexConfig{Pattern: `^(?P<level>\w+) (?P<message>.*)$`}},
+		{name: "regex without pattern", regex: &RegexConfig{}, wantErr: "needs a pattern"},
+		{name: "malformed regex", regex: &RegexConfig{Pattern: `(?P<level>\w+`}, wantErr: "invalid regex pattern"},
+		{name: "regex without named groups", regex: &RegexConfig{Pattern: `^(\w+) (.*)$`}, wantErr: "no named groups"},
+		{name: "grok", grok: &GrokConfig{Pattern: `%{COMBINEDAPACHELOG}`}},
+		{name: "grok with definitions", grok: &GrokConfig{Pattern: `%{APP:app}`, Definitions: map[string]string{"APP": `%{WORD}-%{INT}`}}},
+		{name: "grok without pattern", grok: &GrokConfig{}, wantErr: "needs a pattern"},
+		{name: "unknown grok pattern", grok: &GrokConfig{Pattern: `%{NOPE:x}`}, wantErr: `unknown grok pattern "NOPE"`},
+		{name: "unknown pattern in definition", grok: &GrokConfig{Pattern: `%{APP}`, Definitions: map[string]string{"APP": `%{NOPE}`}}, wantErr: `unknown grok pattern "NOPE"`},
+		{name: "recursive grok pattern", grok: &GrokConfig{Pattern: `%{LOOP}`, Definitions: map[string]string{"LOOP": `a%{LOOP}`}}, wantErr: "nested deeper"},
+		{name: "malformed grok expression", grok: &GrokConfig{Pattern: `%{WORD:w}(`}, wantErr: "invalid grok pattern"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if tt.regex != nil {
+				_, err = NewRegex(*tt.regex)
+			} else {
+				_, err = NewGrok(*tt.grok)
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPatternParse(t *testing.T) {
+	stamp := time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		regex      *RegexConfig
+		grok       *GrokConfig
+		line       string
+		wantResult Result
+		want       model.Log
+	}{
+		{
+			name:       "regex with the default keys",
+			regex:      &RegexConfig{Pattern: `^(?P<time>\S+) (?P<level>\w+) \[(?P<thread>[^\]]+)\] (?P<msg>.*)$`},
+			line:       "2024-01-02T03:04:05Z warn [main] disk full",
+			wantResult: Matched,
+			want: model.Log{
+				Level: "WARN", RawLevel: "warn", Message: "disk full", Timestamp: stamp,
+				Fields: map[string]interface{}{"thread": "main"},
+			},
+		},
+		{
+			name:       "regex group that did not participate",
+			regex:      &RegexConfig{Pattern: `^(?P<level>\w+)(?: code=(?P<code>\d+))?`},
+			line:       "info started",
+			wantResult: Matched,
+			want:       model.Log{Level: "INFO", RawLevel: "info", Message: "info started"},
+		},
+		{
+			name:       "regex with configured keys",
+			regex:      &RegexConfig{Pattern: `^(?P<sev>\w) (?P<text>.*)$`, Keys: Keys{LevelKeys: []string{"sev"}, MessageKeys: []string{"text"}}},
+			line:       "E crashed",
+			wantResult: Matched,
+			want:       model.Log{Level: "ERROR", RawLevel: "E", Message: "crashed"},
+		},
+		{
+			name:       "grok with typed captures",
+			grok:       &GrokConfig{Pattern: `%{IP:client} %{WORD:method} %{URIPATHPARAM:path} %{NUMBER:bytes:int} %{NUMBER:took:float}`},
+			line:       "10.0.0.1 GET /a?b=1 512 0.25",
+			wantResult: Matched,
+			want: model.Log{
+				Message: "10.0.0.1 GET /a?b=1 512 0.25",
+				Fields:  map[string]interface{}{"client": "10.0.0.1", "method": "GET", "path": "/a?b=1", "bytes": int64(512), "took": 0.25},
+			},
+		},
+		{
+			name:       "grok log line",
+			grok:       &GrokConfig{Pattern: `^%{TIMESTAMP_ISO8601:timestamp} +%{LOGLEVEL:level} %{GREEDYDATA:message}`},
+			line:       "2024-01-02T03:04:05Z  ERROR connection refused",
+			wantResult: Matched,
+			want:       model.Log{Level: "ERROR", RawLevel: "ERROR", Message: "connection refused", Timestamp: stamp},
+		},
+		{
+			name:       "conversion failure keeps the text",
+			grok:       &GrokConfig{Pattern: `^%{NOTSPACE:n:int} %{NOTSPACE:f:float}$`},
+			line:       "abc 1,5",
+			wantResult: Matched,
+			want:       model.Log{Message: "abc 1,5", Fields: map[string]interface{}{"n": "abc", "f": "1,5"}},
+		},
+		{
+			name:       "field captured twice keeps the first value",
+			grok:       &GrokConfig{Pattern: `^%{WORD:w} %{WORD:w}$`},
+			line:       "first second",
+			wantResult: Matched,
+			want:       model.Log{Message: "first second", Fields: map[string]interface{}{"w": "first"}},
+		},
+		{
+			name:       "definitions override the library",
+			grok:       &GrokConfig{Pattern: `%{WORD:n}`, Definitions: map[string]string{"WORD": `\d+`}},
+			line:       "abc 123",
+			wantResult: Matched,
+			want:       model.Log{Message: "abc 123", Fields: map[string]interface{}{"n": "123"}},
+		},
+		{
+			name:       "regex does not match",
+			regex:      &RegexConfig{Pattern: `^(?P<level>[A-Z]+):`},
+			line:       "no level here",
+			wantResult: NoMatch,
+			want:       model.Log{Message: "no level here"},
+		},
+		{
+			name:       "grok does not match a truncated line",
+			grok:       &GrokConfig{Pattern: `%{COMMONAPACHELOG}`},
+			line:       `127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET /a.gif HTTP/1.0" 200`,
+			wantResult: NoMatch,
+			want:       model.Log{Message: `127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET /a.gif HTTP/1.0" 200`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p *Pattern
+			var err error
+			if tt.regex != nil {
+				p, err = NewRegex(*tt.regex)
+			} else {
+				p, err = NewGrok(*tt.grok)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			entry := model.Log{Message: tt.line}
+			if result := p.Parse(&entry); result != tt.wantResult {
+				t.Fatalf("Parse() = %v, want %v", result, tt.wantResult)
+			}
+			if !reflect.DeepEqual(entry, tt.want) {
+				t.Errorf("entry = %+v, want %+v", entry, tt.want)
+			}
+		})
+	}
+}
+
+func TestChainFlagsUnparsedLines(t *testing.T) {
+	grok, err := NewGrok(GrokConfig{Pattern: `^%{LOGLEVEL:level}: %{GREEDYDATA:message}$`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		chain Chain
+		entry model.Log
+		want  map[string]interface{}
+	}{
+		{
+			name:  "matched by the pattern",
+			chain: Chain{grok},
+			entry: model.Log{Message: "INFO: up"},
+		},
+		{
+			name:  "matched by another parser",
+			chain: Chain{NewJSON(JSONConfig{}), grok},
+			entry: model.Log{Message: `{"msg":"up","user":"bob"}`},
+			want:  map[string]interface{}{"user": "bob"},
+		},
+		{
+			name:  "unmatched",
+			chain: Chain{grok},
+			entry: model.Log{Message: "garbage"},
+			want:  map[string]interface{}{"unparsed": true},
+		},
+		{
+			name:  "unmatched keeps its fields",
+			chain: Chain{NewCRI(), grok},
+			entry: model.Log{Message: "2024-01-02T03:04:05Z stderr F garbage"},
+			want:  map[string]interface{}{"stream": "stderr", "unparsed": true},
+		},
+		{
+			name:  "not flagged without a pattern parser",
+			chain: Chain{NewJSON(JSONConfig{})},
+			entry: model.Log{Message: "garbage"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []model.Log
+			tt.chain.Process(tt.entry, func(entry model.Log) { got = append(got, entry) })
+			if len(got) != 1 {
+				t.Fatalf("got %d entries, want 1", len(got))
+			}
+			if !reflect.DeepEqual(got[0].Fields, tt.want) {
+				t.Errorf("fields = %v, want %v", got[0].Fields, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,311 @@
+// Package process provides a log source that runs a child command and streams
+// its stdout and stderr. The command is restarted with exponential backoff
+// whenever it exits, and every exit is recorded as a log entry.
+package process
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"smart-log-viewer/server/internal/config"
+	"smart-log-viewer/server/internal/level"
+	"smart-log-viewer/server/internal/model"
+	"smart-log-viewer/server/internal/source"
+)
+
+const (
+	// defaultMinBackoff is the first restart delay after the command exits.
+	defaultMinBackoff = 1 * time.Second
+
+	// defaultMaxBackoff caps the restart delay.
+	defaultMaxBackoff = 30 * time.Second
+
+	// stopGracePeriod is how long the command gets to exit after being
+	// interrupted before it is killed.
+	stopGracePeriod = 5 * time.Second
+)
+
+// Restart policies.
+const (
+	// RestartAlways restarts the command whenever it exits.
+	RestartAlways = "always"
+	// RestartOnFailure restarts the command only when it exits with an error.
+	RestartOnFailure = "on-failure"
+	// RestartNever runs the command once.
+	RestartNever = "never"
+)
+
+// Config holds the options of a process source.
+type Config struct {
+	// Command is the program and its arguments.
+	Command []string `json:"command"`
+
+	// Dir is the working directory of the command; empty uses the server's.
+	Dir string `json:"dir"`
+
+	// Env lists extra "KEY=value" environment variables for the command.
+	Env []string `json:"env"`
+
+	// StdoutLevel is the level of lines written to stdout (default INFO).
+	// Any level name the viewer understands is accepted, e.g. "warning".
+	StdoutLevel string `json:"stdoutLevel"`
+
+	// StderrLevel is the level of lines written to stderr (default ERROR).
+	StderrLevel string `json:"stderrLevel"`
+
+	// Restart is the restart policy: "always" (default), "on-failure" or "never".
+	Restart string `json:"restart"`
+
+	// MinBackoff is the first restart delay (default 1s). It doubles after
+	// every quick exit up to MaxBackoff (default 30s).
+	MinBackoff config.Duration `json:"minBackoff"`
+
+	// MaxBackoff caps the restart delay.
+	MaxBackoff config.Duration `json:"maxBackoff"`
+}
+
+// Source is a source.Source that runs a child command.
+type Source struct {
+	source.Status
+
+	name   string
+	cfg    Config
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewSource creates a process source.
+//
+// Parameters:
+//   - name: The unique name of the source
+//   - cfg: The source options
+//
+// Returns:
+//   - *Source: A new process source instance
+//   - error: nil on success, or an error for a missing command or invalid policy
+func NewSource(name string, cfg Config) (*Source, error) {
+	if len(cfg.Command) == 0 || cfg.Command[0] == "" {
+		return nil, errors.New("process source needs a command")
+	}
+
+	switch cfg.Restart {
+	case "":
+		cfg.Restart = RestartAlways
+	case RestartAlways, RestartOnFailure, RestartNever:
+	default:
+		return nil, fmt.Errorf("unknown restart policy %q", cfg.Restart)
+	}
+
+	var err error
+	if cfg.StdoutLevel, err = streamLevel(cfg.StdoutLevel, level.Info); err != nil {
+		return nil, err
+	}
+	if cfg.StderrLevel, err = streamLevel(cfg.StderrLevel, level.Error); err != nil {
+		return nil, err
+	}
+	if cfg.MinBackoff.Duration <= 0 {
+		cfg.MinBackoff.Duration = defaultMinBackoff
+	}
+	if cfg.MaxBackoff.Duration < cfg.MinBackoff.Duration {
+		cfg.MaxBackoff.Duration = max(defaultMaxBackoff, cfg.MinBackoff.Duration)
+	}
+
+	return &Source{
+		name: name,
+		cfg:  cfg,
+	}, nil
+}
+
+// streamLevel maps the configured level of a stream onto the canonical
+// scale, using fallback when none is configured.
+func streamLevel(text, fallback string) (string, error) {
+	if text == "" {
+		return fallback, nil
+	}
+	canonical, ok := level.Parse(text)
+	if !ok {
+		return "", fmt.Errorf("unknown level %q", text)
+	}
+	return canonical, nil
+}
+
+// Name returns the unique name of the source.
+func (s *Source) Name() string {
+	return s.name
+}
+
+// Start launches the supervisor goroutine that runs and restarts the command.
+//
+// Parameters:
+//   - ctx: Context that stops the command when cancelled
+//   - out: Channel log entries are sent to
+//
+// Returns:
+//   - error: Always nil; failures to launch the command are retried and logged
+func (s *Source) Start(ctx context.Context, out chan<- model.Log) error {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	s.wg.Add(1)
+	go s.supervise(ctx, out)
+	return nil
+}
+
+// supervise runs the command until the restart policy says to stop or ctx
+// is cancelled, waiting with exponential backoff between runs.
+func (s *Source) supervise(ctx context.Context, out chan<- model.Log) {
+	defer s.wg.Done()
+
+	backoff := s.cfg.MinBackoff.Duration
+	for {
+		started := time.Now()
+		exitCode, err := s.runOnce(ctx, out)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err != nil {
+			s.SetError(source.StateDegraded, err)
+		} else {
+			s.SetState(source.StateStopped)
+		}
+		s.emitExit(ctx, out, exitCode, err, time.Since(started))
+
+		if s.cfg.Restart == RestartNever || (s.cfg.Restart == RestartOnFailure && err == nil) {
+			log.Printf("Process source %q: command finished, not restarting", s.name)
+			return
+		}
+
+		// A run that lasted longer than the maximum backoff counts as healthy.
+		if time.Since(started) > s.cfg.MaxBackoff.Duration {
+			backoff = s.cfg.MinBackoff.Duration
+		}
+		log.Printf("Process source %q: restarting in %s", s.name, backoff)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+		backoff = min(backoff*2, s.cfg.MaxBackoff.Duration)
+	}
+}
+
+// runOnce starts the command, streams its output and waits for it to exit.
+//
+// Returns:
+//   - int: The exit code, or -1 if the command could not be run
+//   - error: nil if the command exited successfully
+func (s *Source) runOnce(ctx context.Context, out chan<- model.Log) (int, error) {
+	cmd := exec.CommandContext(ctx, s.cfg.Command[0], s.cfg.Command[1:]...)
+	cmd.Dir = s.cfg.Dir
+	cmd.Env = append(os.Environ(), s.cfg.Env...)
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(os.Interrupt)
+	}
+	cmd.WaitDelay = stopGracePeriod
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return -1, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return -1, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return -1, err
+	}
+	log.Printf("Process source %q: started %q (pid %d)", s.name, strings.Join(s.cfg.Command, " "), cmd.Process.Pid)
+	s.SetState(source.StateRunning)
+
+	// The pipes must be drained before Wait closes them.
+	var streams sync.WaitGroup
+	streams.Add(2)
+	go s.stream(ctx, out, &streams, stdout, "stdout", s.cfg.StdoutLevel)
+	go s.stream(ctx, out, &streams, stderr, "stderr", s.cfg.StderrLevel)
+	streams.Wait()
+
+	err = cmd.Wait()
+	if err == nil {
+		return 0, nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), err
+	}
+	return -1, err
+}
+
+// stream emits every line of one output stream of the command.
+func (s *Source) stream(ctx context.Context, out chan<- model.Log, wg *sync.WaitGroup, r io.Reader, name, level string) {
+	defer wg.Done()
+
+	err := source.ReadLines(r, func(line string) bool {
+		return s.Emit(ctx, out, model.Log{
+			Level:      level,
+			Message:    line,
+			ReceivedAt: time.Now(),
+			Source:     s.name,
+			Host:       source.LocalHostname(),
+			Fields:     map[string]interface{}{"stream": name},
+		})
+	})
+	if err != nil && !errors.Is(err, os.ErrClosed) {
+		log.Printf("Process source %q: error reading %s: %v", s.name, name, err)
+	}
+	// Keep draining after emitting stopped so the child never blocks on a full pipe.
+	_, _ = io.Copy(io.Discard, r)
+}
+
+// emitExit records the exit of the command as a log entry.
+func (s *Source) emitExit(ctx context.Context, out chan<- model.Log, exitCode int, err error, ran time.Duration) {
+	entry := model.Log{
+		Level:     level.Info,
+		Timestamp: time.Now(),
+		Source:    s.name,
+		Host:      source.LocalHostname(),
+		Fields: map[string]interface{}{
+			"exit_code": exitCode,
+			"command":   strings.Join(s.cfg.Command, " "),
+			"runtime":   ran.Round(time.Millisecond).String(),
+		},
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		entry.Message = "Process exited with code 0"
+	case errors.As(err, &exitErr):
+		entry.Level = level.Error
+		entry.Message = fmt.Sprintf("Process exited with code %d (%s)", exitCode, exitErr.ProcessState)
+	default:
+		entry.Level = level.Error
+		entry.Message = fmt.Sprintf("Process could not be run: %v", err)
+	}
+
+	log.Printf("Process source %q: %s", s.name, entry.Message)
+	s.Emit(ctx, out, entry)
+}
+
+// Stop interrupts the command, kills it if it does not exit within the
+// grace period, and waits for the supervisor to finish.
+//
+// Returns:
+//   - error: Always nil
+func (s *Source) Stop() error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+	s.SetState(source.StateStopped)
+	return nil
+}
@@ -0,0 +1,188 @@
+package process
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"smart-log-viewer/server/internal/config"
+	"smart-log-viewer/server/internal/model"
+)
+
+func TestNewSource(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         Config
+		wantRestart string
+		wantStdout  string
+		wantStderr  string
+		wantErr     string
+	}{
+		{
+			name:        "defaults",
+			cfg:         Config{Command: []string{"true"}},
+			wantRestart: RestartAlways,
+			wantStdout:  "INFO",
+			wantStderr:  "ERROR",
+		},
+		{
+			name:        "configured levels are canonicalised",
+			cfg:         Config{Command: []string{"true"}, StdoutLevel: "debug", StderrLevel: "warning", Restart: RestartNever},
+			wantRestart: RestartNever,
+			wantStdout:  "DEBUG",
+			wantStderr:  "WARN",
+		},
+		{name: "no command", cfg: Config{}, wantErr: "needs a command"},
+		{name: "empty program", cfg: Config{Command: []string{""}}, wantErr: "needs a command"},
+		{name: "unknown policy", cfg: Config{Command: []string{"true"}, Restart: "sometimes"}, wantErr: "unknown restart policy"},
+		{name: "unknown stdout level", cfg: Config{Command: []string{"true"}, StdoutLevel: "loud"}, wantErr: `unknown level "loud"`},
+		{name: "unknown stderr level", cfg: Config{Command: []string{"true"}, StderrLevel: "quiet"}, wantErr: `unknown level "quiet"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, err := NewSource("proc", tt.cfg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("NewSource() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if src.cfg.Restart != tt.wantRestart || src.cfg.StdoutLevel != tt.wantStdout || src.cfg.StderrLevel != tt.wantStderr {
+				t.Errorf("restart %q, stdout %q, stderr %q, want %q, %q, %q",
+					src.cfg.Restart, src.cfg.StdoutLevel, src.cfg.StderrLevel, tt.wantRestart, tt.wantStdout, tt.wantStderr)
+			}
+		})
+	}
+}
+
+func TestSourceRunsCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		command []string
+		want    []string
+	}{
+		{
+			name:    "streams and exit",
+			command: []string{"sh", "-c", "echo out; echo err >&2"},
+			want:    []string{"INFO stdout out", "ERROR stderr err", "INFO exit Process exited with code 0"},
+		},
+		{
+			name:    "last line without newline",
+			command: []string{"sh", "-c", "printf partial"},
+			want:    []string{"INFO stdout partial", "INFO exit Process exited with code 0"},
+		},
+		{
+			name:    "failing command",
+			command: []string{"sh", "-c", "exit 3"},
+			want:    []string{"ERROR exit Process exited with code 3 (exit status 3)"},
+		},
+		{
+			name:    "missing program",
+			command: []string{"/nonexistent/program"},
+			want:    []string{"ERROR exit Process could not be run"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, err := NewSource("proc", Config{Command: tt.command, Restart: RestartNever})
+			if err != nil {
+				t.Fatal(err)
+			}
+			out := make(chan model.Log, 10)
+			if err := src.Start(context.Background(), out); err != nil {
+				t.Fatal(err)
+			}
+			// With RestartNever the supervisor exits after one run.
+			src.wg.Wait()
+			src.Stop()
+			close(out)
+
+			var stdout, stderr, exit []string
+			for entry := range out {
+				stream, _ := entry.Fields["stream"].(string)
+				if stream == "" {
+					stream = "exit"
+				}
+				line := entry.Level + " " + stream + " " + entry.Message
+				switch stream {
+				case "stdout":
+					stdout = append(stdout, line)
+				case "stderr":
+					stderr = append(stderr, line)
+				default:
+					exit = append(exit, line)
+				}
+				if entry.Source != "proc" {
+					t.Errorf("Source = %q, want proc", entry.Source)
+				}
+			}
+			// The streams are read concurrently, so only their own order is kept.
+			got := append(append(stdout, stderr...), exit...)
+			if len(got) != len(tt.want) {
+				t.Fatalf("entries = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if !strings.HasPrefix(got[i], tt.want[i]) {
+					t.Errorf("entry %d = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSourceRestartsWithBackoff(t *testing.T) {
+	src, err := NewSource("proc", Config{
+		Command:    []string{"sh", "-c", "echo run"},
+		Restart:    RestartAlways,
+		MinBackoff: config.Duration{Duration: 10 * time.Millisecond},
+		MaxBackoff: config.Duration{Duration: 20 * time.Millisecond},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := make(chan model.Log, 100)
+	if err := src.Start(context.Background(), out); err != nil {
+		t.Fatal(err)
+	}
+	defer src.Stop()
+
+	runs := 0
+	deadline := time.After(5 * time.Second)
+	for runs < 3 {
+		select {
+		case entry := <-out:
+			if entry.Message == "run" {
+				runs++
+			}
+		case <-deadline:
+			t.Fatalf("command ran %d times, want 3", runs)
+		}
+	}
+}
+
+func TestStopInterruptsCommand(t *testing.T) {
+	src, err := NewSource("proc", Config{Command: []string{"sleep", "60"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := src.Start(context.Background(), make(chan model.Log, 10)); err != nil {
+		t.Fatal(err)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		src.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(stopGracePeriod + 2*time.Second):
+		t.Fatal("Stop did not end the command")
+	}
+}
@@ -0,0 +1,308 @@
+// Package replay provides a log source that replays archived log files,
+// plain or compressed with gzip or zstd, either as fast as possible or with
+// their original timing scaled by a speed factor. The timing comes from the
+// timestamps found in the lines themselves.
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+
+	"smart-log-viewer/server/internal/config"
+	"smart-log-viewer/server/internal/level"
+	"smart-log-viewer/server/internal/model"
+	"smart-log-viewer/server/internal/source"
+	"smart-log-viewer/server/internal/timestamp"
+)
+
+// defaultSpeed replays files at their original pace.
+const defaultSpeed = 1
+
+var (
+	// gzipMagic starts every gzip stream.
+	gzipMagic = []byte{0x1f, 0x8b}
+
+	// zstdMagic starts every zstd frame.
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// Config holds the options of a replay source.
+type Config struct {
+	// Paths lists the files to replay; glob patterns are expanded. The
+	// files are replayed one after another in sorted order.
+	Paths []string `json:"paths"`
+
+	// Speed scales the original timing: 2 replays twice as fast, 0.5 at
+	// half speed (default 1).
+	Speed float64 `json:"speed"`
+
+	// Fast replays the files as fast as possible, ignoring their timing.
+	Fast bool `json:"fast"`
+
+	// MaxDelay caps the wait between two lines so that quiet periods in the
+	// archive do not stall the replay; zero means no cap.
+	MaxDelay config.Duration `json:"maxDelay"`
+
+	// Timezone is the IANA zone of timestamps that carry none (default local time).
+	Timezone string `json:"timezone"`
+}
+
+// Source is a source.Source that replays archived log files.
+type Source struct {
+	source.Status
+
+	name   string
+	cfg    Config
+	loc    *time.Location
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewSource creates a replay source.
+//
+// Parameters:
+//   - name: The unique name of the source
+//   - cfg: The source options
+//
+// Returns:
+//   - *Source: A new replay source instance
+//   - error: nil on success, or an error for missing paths, an invalid
+//     speed or an unknown timezone
+func NewSource(name string, cfg Config) (*Source, error) {
+	if len(cfg.Paths) == 0 {
+		return nil, errors.New("replay source needs at least one path")
+	}
+	for _, pattern := range cfg.Paths {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid path pattern %q: %w", pattern, err)
+		}
+	}
+	if cfg.Speed < 0 {
+		return nil, fmt.Errorf("invalid replay speed %v", cfg.Speed)
+	}
+	if cfg.Speed == 0 {
+		cfg.Speed = defaultSpeed
+	}
+
+	loc := time.Local
+	if cfg.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(cfg.Timezone); err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", cfg.Timezone, err)
+		}
+	}
+
+	return &Source{
+		name: name,
+		cfg:  cfg,
+		loc:  loc,
+	}, nil
+}
+
+// Name returns the unique name of the source.
+func (s *Source) Name() string {
+	return s.name
+}
+
+// Replays reports that the source replays recorded logs, so that they are
+// not stamped with the time they were read.
+//
+// Returns:
+//   - bool: Always true
+func (s *Source) Replays() bool {
+	return true
+}
+
+// Start launches the replay goroutine.
+//
+// Parameters:
+//   - ctx: Context that stops the replay when cancelled
+//   - out: Channel replayed log entries are sent to
+//
+// Returns:
+//   - error: Always nil; unreadable files are logged and skipped
+func (s *Source) Start(ctx context.Context, out chan<- model.Log) error {
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.SetState(source.StateRunning)
+
+	s.wg.Add(1)
+	go s.run(ctx, out)
+	return nil
+}
+
+// run replays every file in turn and marks the source stopped when done.
+func (s *Source) run(ctx context.Context, out chan<- model.Log) {
+	defer s.wg.Done()
+
+	paths := s.expand()
+	if len(paths) == 0 {
+		err := errors.New("no files match the configured paths")
+		log.Printf("Replay source %q: %v", s.name, err)
+		s.SetError(source.StateFailed, err)
+		return
+	}
+
+	failed := 0
+	for _, path := range paths {
+		if err := s.replayFile(ctx, out, path); err != nil {
+			log.Printf("Replay source %q: %s: %v", s.name, path, err)
+			s.SetError(source.StateDegraded, err)
+			failed++
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+
+	log.Printf("Replay source %q: finished replaying %d file(s)", s.name, len(paths))
+	if failed == 0 {
+		s.SetState(source.StateStopped)
+	}
+}
+
+// expand resolves the configured glob patterns into a sorted list of files
+// without duplicates.
+func (s *Source) expand() []string {
+	seen := make(map[string]bool)
+	var paths []string
+	for _, pattern := range s.cfg.Paths {
+		matches, _ := filepath.Glob(pattern)
+		sort.Strings(matches)
+		for _, path := range matches {
+			if info, err := os.Stat(path); err != nil || info.IsDir() || seen[path] {
+				continue
+			}
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// replayFile emits the lines of one file, waiting between them according
+// to their timestamps. Lines without a timestamp inherit the previous
+// line's and are emitted without delay.
+//
+// Returns:
+//   - error: nil when the file was replayed or ctx was cancelled, or an
+//     error if it could not be opened or decompressed
+func (s *Source) replayFile(ctx context.Context, out chan<- model.Log, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader, err := decompress(file)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	var previous time.Time
+	return source.ReadLines(reader, func(line string) bool {
+		now := time.Now()
+		t, ok := timestamp.Find(line, s.loc, now)
+		switch {
+		case ok:
+			if !s.wait(ctx, previous, t) {
+				return false
+			}
+			previous = t
+		case !previous.IsZero():
+			t = previous
+		default:
+			t = now
+		}
+
+		return s.Emit(ctx, out, model.Log{
+			Level:     level.Info,
+			Message:   line,
+			Timestamp: t,
+			Source:    path,
+			Host:      source.LocalHostname(),
+		})
+	})
+}
+
+// wait sleeps for the scaled time between two consecutive timestamps.
+//
+// Returns:
+//   - bool: false if ctx was cancelled while waiting
+func (s *Source) wait(ctx context.Context, previous, next time.Time) bool {
+	if s.cfg.Fast || previous.IsZero() || !next.After(previous) {
+		return ctx.Err() == nil
+	}
+
+	delay := time.Duration(float64(next.Sub(previous)) / s.cfg.Speed)
+	if s.cfg.MaxDelay.Duration > 0 && delay > s.cfg.MaxDelay.Duration {
+		delay = s.cfg.MaxDelay.Duration
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// decompress wraps r in a gzip or zstd reader if it starts with the
+// respective magic number, so that compression is detected regardless of
+// the file name.
+//
+// Parameters:
+//   - r: The raw file
+//
+// Returns:
+//   - io.ReadCloser: The decompressed stream; closing it does not close r
+//   - error: nil on success, or an error for a corrupt compression header
+func decompress(r io.Reader) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(r)
+	magic, _ := buffered.Peek(len(zstdMagic))
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip file: %w", err)
+		}
+		return gz, nil
+	case bytes.HasPrefix(magic, zstdMagic):
+		zr, err := zstd.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("invalid zstd file: %w", err)
+		}
+		return zr.IOReadCloser(), nil
+	default:
+		return io.NopCloser(buffered), nil
+	}
+}
+
+// Stop stops the replay and waits for it to exit.
+//
+// Returns:
+//   - error: Always nil
+func (s *Source) Stop() error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+	s.SetState(source.StateStopped)
+	return nil
+}
@@ -0,0 +1,230 @@
+package replay
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+
+	"smart-log-viewer/server/internal/config"
+	"smart-log-viewer/server/internal/model"
+	"smart-log-viewer/server/internal/source"
+)
+
+const archive = `2024-03-10T11:00:00Z first
+continued
+2024-03-10T11:00:01Z second
+`
+
+// gzipData compresses data with gzip.
+func gzipData(t *testing.T, data string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// zstdData compresses data with zstd.
+func zstdData(t *testing.T, data string) []byte {
+	t.Helper()
+
+	enc, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer enc.Close()
+	return enc.EncodeAll([]byte(data), nil)
+}
+
+// replay runs a replay source over files until it finishes and returns
+// the entries and the final health.
+func replay(t *testing.T, cfg Config) ([]model.Log, source.Health) {
+	t.Helper()
+
+	src, err := NewSource("replay", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := make(chan model.Log, 100)
+	if err := src.Start(context.Background(), out); err != nil {
+		t.Fatal(err)
+	}
+	src.wg.Wait()
+	health := src.Health()
+	src.Stop()
+	close(out)
+
+	var entries []model.Log
+	for entry := range out {
+		entries = append(entries, entry)
+	}
+	return entries, health
+}
+
+func TestNewSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr string
+	}{
+		{name: "valid", cfg: Config{Paths: []string{"/var/log/*.gz"}, Speed: 2, Timezone: "Europe/Berlin"}},
+		{name: "no paths", cfg: Config{}, wantErr: "at least one path"},
+		{name: "malformed pattern", cfg: Config{Paths: []string{"/var/log/[.gz"}}, wantErr: "invalid path pattern"},
+		{name: "negative speed", cfg: Config{Paths: []string{"a.log"}, Speed: -1}, wantErr: "invalid replay speed"},
+		{name: "unknown timezone", cfg: Config{Paths: []string{"a.log"}, Timezone: "Mars/Olympus"}, wantErr: "invalid timezone"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSource("replay", tt.cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("NewSource() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("NewSource() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReplayFiles(t *testing.T) {
+	first := time.Date(2024, time.March, 10, 11, 0, 0, 0, time.UTC)
+	second := first.Add(time.Second)
+
+	tests := []struct {
+		name      string
+		files     map[string][]byte
+		want      []string
+		wantTimes []time.Time
+		wantState source.State
+	}{
+		{
+			name:      "plain",
+			files:     map[string][]byte{"app.log": []byte(archive)},
+			want:      []string{"2024-03-10T11:00:00Z first", "continued", "2024-03-10T11:00:01Z second"},
+			wantTimes: []time.Time{first, first, second},
+			wantState: source.StateStopped,
+		},
+		{
+			name:      "gzip",
+			files:     map[string][]byte{"app.log.1": gzipData(t, archive)},
+			want:      []string{"2024-03-10T11:00:00Z first", "continued", "2024-03-10T11:00:01Z second"},
+			wantTimes: []time.Time{first, first, second},
+			wantState: source.StateStopped,
+		},
+		{
+			name:      "zstd",
+			files:     map[string][]byte{"app.log.zst": zstdData(t, archive)},
+			want:      []string{"2024-03-10T11:00:00Z first", "continued", "2024-03-10T11:00:01Z second"},
+			wantTimes: []time.Time{first, first, second},
+			wantState: source.StateStopped,
+		},
+		{
+			name:      "files in sorted order",
+			files:     map[string][]byte{"b.log": []byte("2024-03-10T11:00:01Z b\n"), "a.log": []byte("2024-03-10T11:00:00Z a\n")},
+			want:      []string{"2024-03-10T11:00:00Z a", "2024-03-10T11:00:01Z b"},
+			wantTimes: []time.Time{first, second},
+			wantState: source.StateStopped,
+		},
+		{
+			name:      "corrupt gzip header",
+			files:     map[string][]byte{"a.log": gzipData(t, archive)[:5], "b.log": []byte("2024-03-10T11:00:00Z ok\n")},
+			want:      []string{"2024-03-10T11:00:00Z ok"},
+			wantTimes: []time.Time{first},
+			wantState: source.StateDegraded,
+		},
+		{
+			name:      "truncated gzip",
+			files:     map[string][]byte{"a.log": gzipData(t, archive)[:40]},
+			wantState: source.StateDegraded,
+		},
+		{
+			name:      "no matching files",
+			wantState: source.StateFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, data := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			entries, health := replay(t, Config{Paths: []string{filepath.Join(dir, "*")}, Fast: true, Timezone: "UTC"})
+
+			var got []string
+			for i, entry := range entries {
+				got = append(got, entry.Message)
+				if i < len(tt.wantTimes) && !entry.Timestamp.Equal(tt.wantTimes[i]) {
+					t.Errorf("entry %d: Timestamp = %v, want %v", i, entry.Timestamp, tt.wantTimes[i])
+				}
+				if !strings.HasPrefix(entry.Source, dir) || entry.Host == "" || !entry.ReceivedAt.IsZero() {
+					t.Errorf("entry %d: source %q, host %q, receivedAt %v, want the file, the local host and no receive time",
+						i, entry.Source, entry.Host, entry.ReceivedAt)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("messages = %q, want %q", got, tt.want)
+			}
+			if health.State != tt.wantState {
+				t.Errorf("state = %q (%s), want %q", health.State, health.LastError, tt.wantState)
+			}
+		})
+	}
+}
+
+func TestReplayTiming(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      Config
+		minDelay time.Duration
+		maxDelay time.Duration
+	}{
+		{name: "scaled by speed", cfg: Config{Speed: 20}, minDelay: 50 * time.Millisecond, maxDelay: time.Second},
+		{name: "capped by max delay", cfg: Config{MaxDelay: config.Duration{Duration: 20 * time.Millisecond}}, minDelay: 20 * time.Millisecond, maxDelay: 500 * time.Millisecond},
+		{name: "fast", cfg: Config{Fast: true}, maxDelay: 500 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			// Two lines 1s apart, then one going back in time.
+			data := "2024-03-10T11:00:00Z a\n2024-03-10T11:00:01Z b\n2024-03-10T10:00:00Z c\n"
+			if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			tt.cfg.Paths = []string{path}
+			started := time.Now()
+			entries, _ := replay(t, tt.cfg)
+			took := time.Since(started)
+
+			if len(entries) != 3 {
+				t.Fatalf("replayed %d lines, want 3", len(entries))
+			}
+			if took < tt.minDelay || took > tt.maxDelay {
+				t.Errorf("replay took %v, want between %v and %v", took, tt.minDelay, tt.maxDelay)
+			}
+		})
+	}
+}
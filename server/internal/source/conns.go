@@ -0,0 +1,76 @@
+package source
+
+import (
+	"net"
+	"sync"
+)
+
+// Conns tracks the open connections of a stream listener, so that a source
+// can close them on shutdown instead of waiting for idle peers.
+//
+// The zero value is ready to use.
+type Conns struct {
+	mu     sync.Mutex
+	conns  map[net.Conn]bool
+	closed bool
+}
+
+// Accept accepts connections on ln and serves each in its own goroutine
+// until ln is closed. Every connection is tracked while serve runs and is
+// closed when serve returns.
+//
+// Parameters:
+//   - ln: The listener to accept connections on
+//   - wg: Wait group that counts the serving goroutines
+//   - serve: Reads from one connection until it ends or must be dropped
+//
+// Returns:
+//   - error: The error that ended accepting, usually from closing ln
+func (c *Conns) Accept(ln net.Listener, wg *sync.WaitGroup, serve func(conn net.Conn)) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+
+		// A connection accepted while shutting down may come after the open
+		// connections were closed, so close it here instead.
+		c.mu.Lock()
+		if c.closed {
+			c.mu.Unlock()
+			conn.Close()
+			continue
+		}
+		if c.conns == nil {
+			c.conns = make(map[net.Conn]bool)
+		}
+		c.conns[conn] = true
+		c.mu.Unlock()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer c.remove(conn)
+			serve(conn)
+		}()
+	}
+}
+
+// remove stops tracking a connection and closes it.
+func (c *Conns) remove(conn net.Conn) {
+	c.mu.Lock()
+	delete(c.conns, conn)
+	c.mu.Unlock()
+	conn.Close()
+}
+
+// CloseAll closes every open connection and any accepted later.
+func (c *Conns) CloseAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	for conn := range c.conns {
+		conn.Close()
+	}
+}
@@ -0,0 +1,121 @@
+package source
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// startAccepting runs Conns.Accept on a fresh loopback listener and echoes
+// one line per connection back to the peer before waiting for it to close.
+func startAccepting(t *testing.T, c *Conns, wg *sync.WaitGroup) (net.Listener, <-chan error) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Accept(ln, wg, func(conn net.Conn) {
+			reader := bufio.NewReader(conn)
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			conn.Write([]byte(line))
+			io.Copy(io.Discard, reader)
+		})
+	}()
+	return ln, done
+}
+
+func TestConnsAccept(t *testing.T) {
+	tests := []struct {
+		name      string
+		closeAll  bool
+		wantReply bool
+	}{
+		{name: "serves connections", wantReply: true},
+		{name: "closes connections accepted after CloseAll", closeAll: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c Conns
+			var wg sync.WaitGroup
+			ln, done := startAccepting(t, &c, &wg)
+			if tt.closeAll {
+				c.CloseAll()
+			}
+
+			conn, err := net.Dial("tcp", ln.Addr().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			conn.SetDeadline(time.Now().Add(2 * time.Second))
+			conn.Write([]byte("ping\n"))
+
+			reply, err := bufio.NewReader(conn).ReadString('\n')
+			if tt.wantReply && (err != nil || reply != "ping\n") {
+				t.Errorf("reply = %q, %v, want %q", reply, err, "ping\n")
+			}
+			if !tt.wantReply && err == nil {
+				t.Errorf("got reply %q from a connection accepted after CloseAll", reply)
+			}
+
+			ln.Close()
+			select {
+			case err := <-done:
+				if err == nil {
+					t.Error("Accept() returned nil after the listener closed")
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("Accept() did not return after the listener closed")
+			}
+			c.CloseAll()
+			wg.Wait()
+		})
+	}
+}
+
+func TestConnsCloseAll(t *testing.T) {
+	var c Conns
+	var wg sync.WaitGroup
+	ln, done := startAccepting(t, &c, &wg)
+	defer ln.Close()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(2 * time.Second))
+	conn.Write([]byte("ping\n"))
+	if _, err := bufio.NewReader(conn).ReadString('\n'); err != nil {
+		t.Fatalf("no reply before CloseAll: %v", err)
+	}
+
+	c.CloseAll()
+	served := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(served)
+	}()
+	select {
+	case <-served:
+	case <-time.After(2 * time.Second):
+		t.Fatal("CloseAll() did not end the open connection")
+	}
+
+	if _, err := conn.Read(make([]byte, 1)); !errors.Is(err, io.EOF) {
+		t.Errorf("read after CloseAll = %v, want EOF", err)
+	}
+	ln.Close()
+	<-done
+}
@@ -0,0 +1,55 @@
+package source
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+)
+
+// MaxLineSize caps the length of a line read by ReadLines or from a
+// followed file; longer lines are delivered in pieces so that a stream
+// without newlines cannot exhaust memory.
+const MaxLineSize = 1024 * 1024
+
+// ReadLines reads r line by line and calls emit for every line, without the
+// trailing "\n" or "\r\n". A final line without a newline is delivered too.
+//
+// Parameters:
+//   - r: The stream to read, e.g. a pipe from a child process or stdin
+//   - emit: Called for every line; returning false stops reading
+//
+// Returns:
+//   - error: nil at the end of the stream or when emit stops reading,
+//     otherwise the read error
+func ReadLines(r io.Reader, emit func(line string) bool) error {
+	reader := bufio.NewReaderSize(r, 64*1024)
+
+	var line []byte
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		line = append(line, chunk...)
+
+		switch {
+		case err == nil:
+			if !emit(string(bytes.TrimSuffix(bytes.TrimSuffix(line, []byte{'\n'}), []byte{'\r'}))) {
+				return nil
+			}
+			line = line[:0]
+		case errors.Is(err, bufio.ErrBufferFull):
+			if len(line) >= MaxLineSize {
+				if !emit(string(line)) {
+					return nil
+				}
+				line = line[:0]
+			}
+		case errors.Is(err, io.EOF):
+			if len(line) > 0 {
+				emit(string(bytes.TrimSuffix(line, []byte{'\r'})))
+			}
+			return nil
+		default:
+			return err
+		}
+	}
+}
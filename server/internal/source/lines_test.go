@@ -0,0 +1,75 @@
+package source
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReadLines(t *testing.T) {
+	long := strings.Repeat("x", MaxLineSize+10)
+
+	tests := []struct {
+		name    string
+		input   string
+		stopAt  int
+		want    []string
+		wantErr bool
+	}{
+		{name: "empty", input: ""},
+		{name: "lines", input: "a\nb\n", want: []string{"a", "b"}},
+		{name: "crlf", input: "a\r\nb\r\n", want: []string{"a", "b"}},
+		{name: "final line without newline", input: "a\nb\r", want: []string{"a", "b"}},
+		{name: "empty lines", input: "\n\na", want: []string{"", "", "a"}},
+		{name: "stop early", input: "a\nb\nc\n", stopAt: 2, want: []string{"a", "b"}},
+		{
+			name:  "long line in pieces",
+			input: long + "\n",
+			want:  []string{long[:MaxLineSize], long[MaxLineSize:]},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			err := ReadLines(strings.NewReader(tt.input), func(line string) bool {
+				got = append(got, line)
+				return tt.stopAt == 0 || len(got) < tt.stopAt
+			})
+			if err != nil {
+				t.Fatalf("ReadLines() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lines = %q, want %q", truncate(got), truncate(tt.want))
+			}
+		})
+	}
+}
+
+func TestReadLinesError(t *testing.T) {
+	var got []string
+	err := ReadLines(iotest.TimeoutReader(strings.NewReader("a\nb")), func(line string) bool {
+		got = append(got, line)
+		return true
+	})
+	if !errors.Is(err, iotest.ErrTimeout) {
+		t.Fatalf("ReadLines() error = %v, want %v", err, iotest.ErrTimeout)
+	}
+	if !reflect.DeepEqual(got, []string{"a"}) {
+		t.Errorf("lines = %q, want the lines before the error", got)
+	}
+}
+
+// truncate shortens lines for error messages.
+func truncate(lines []string) []string {
+	short := make([]string, len(lines))
+	for i, line := range lines {
+		if len(line) > 20 {
+			line = line[:20] + "..."
+		}
+		short[i] = line
+	}
+	return short
+}
@@ -0,0 +1,79 @@
+package source
+
+import (
+	"smart-log-viewer/server/internal/model"
+)
+
+// Stage transforms log entries on their way from a source to the hub,
+// for example by parsing the raw line into structured fields.
+type Stage interface {
+	// Process handles one entry and passes zero or more entries to next.
+	// A stage may hold entries back, e.g. to join partial lines.
+	Process(entry model.Log, next func(model.Log))
+}
+
+// Flusher is implemented by stages that hold entries back. The registry
+// calls Flush periodically so that held entries are not delayed forever,
+// and with force set when the source stops.
+type Flusher interface {
+	// Flush passes on held entries that have waited long enough,
+	// or all held entries if force is true.
+	Flush(next func(model.Log), force bool)
+}
+
+// pipeline chains the stages of one source in front of a sink.
+type pipeline struct {
+	stages []Stage
+	// nexts[i] is the function that receives the output of stages[i].
+	nexts []func(model.Log)
+	head  func(model.Log)
+}
+
+// newPipeline builds a pipeline that runs entries through stages in order
+// and hands the result to sink.
+//
+// Parameters:
+//   - stages: The stages in processing order
+//   - sink: Receives the entries that leave the last stage
+//
+// Returns:
+//   - *pipeline: The assembled pipeline
+func newPipeline(stages []Stage, sink func(model.Log)) *pipeline {
+	p := &pipeline{
+		stages: stages,
+		nexts:  make([]func(model.Log), len(stages)),
+		head:   sink,
+	}
+	for i := len(stages) - 1; i >= 0; i-- {
+		stage, next := stages[i], p.head
+		p.nexts[i] = next
+		p.head = func(entry model.Log) {
+			stage.Process(entry, next)
+		}
+	}
+	return p
+}
+
+// process runs one entry through the pipeline.
+func (p *pipeline) process(entry model.Log) {
+	p.head(entry)
+}
+
+// flush asks every buffering stage to pass on its held entries.
+func (p *pipeline) flush(force bool) {
+	for i, stage := range p.stages {
+		if flusher, ok := stage.(Flusher); ok {
+			flusher.Flush(p.nexts[i], force)
+		}
+	}
+}
+
+// buffering reports whether any stage may hold entries back.
+func (p *pipeline) buffering() bool {
+	for _, stage := range p.stages {
+		if _, ok := stage.(Flusher); ok {
+			return true
+		}
+	}
+	return false
+}
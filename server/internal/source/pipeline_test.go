@@ -0,0 +1,109 @@
+package source
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"smart-log-viewer/server/internal/model"
+)
+
+// stageFunc adapts a function to the Stage interface.
+type stageFunc func(entry model.Log, next func(model.Log))
+
+func (f stageFunc) Process(entry model.Log, next func(model.Log)) { f(entry, next) }
+
+// holdingStage holds every entry back until it is flushed.
+type holdingStage struct {
+	held []model.Log
+}
+
+func (s *holdingStage) Process(entry model.Log, _ func(model.Log)) {
+	s.held = append(s.held, entry)
+}
+
+func (s *holdingStage) Flush(next func(model.Log), force bool) {
+	if !force {
+		return
+	}
+	for _, entry := range s.held {
+		next(entry)
+	}
+	s.held = nil
+}
+
+func TestPipeline(t *testing.T) {
+	upper := stageFunc(func(entry model.Log, next func(model.Log)) {
+		entry.Message = strings.ToUpper(entry.Message)
+		next(entry)
+	})
+	split := stageFunc(func(entry model.Log, next func(model.Log)) {
+		for _, part := range strings.Fields(entry.Message) {
+			next(model.Log{Message: part})
+		}
+	})
+
+	tests := []struct {
+		name       string
+		stages     []Stage
+		input      []string
+		flushes    []bool
+		want       []string
+		wantBuffer bool
+	}{
+		{
+			name:  "no stages",
+			input: []string{"a b"},
+			want:  []string{"a b"},
+		},
+		{
+			name:   "stages run in order",
+			stages: []Stage{split, upper},
+			input:  []string{"a b", "c"},
+			want:   []string{"A", "B", "C"},
+		},
+		{
+			name:   "stage drops entries",
+			stages: []Stage{split},
+			input:  []string{"", "x"},
+			want:   []string{"x"},
+		},
+		{
+			name:       "held entries pass the later stages on forced flush",
+			stages:     []Stage{&holdingStage{}, upper},
+			input:      []string{"a", "b"},
+			flushes:    []bool{false, true},
+			want:       []string{"A", "B"},
+			wantBuffer: true,
+		},
+		{
+			name:       "held entries stay without forced flush",
+			stages:     []Stage{upper, &holdingStage{}},
+			input:      []string{"a"},
+			flushes:    []bool{false},
+			wantBuffer: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			p := newPipeline(tt.stages, func(entry model.Log) {
+				got = append(got, entry.Message)
+			})
+			for _, message := range tt.input {
+				p.process(model.Log{Message: message})
+			}
+			for _, force := range tt.flushes {
+				p.flush(force)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("messages = %q, want %q", got, tt.want)
+			}
+			if p.buffering() != tt.wantBuffer {
+				t.Errorf("buffering() = %v, want %v", p.buffering(), tt.wantBuffer)
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"smart-log-viewer/server/internal/model"
+)
+
+const (
+	// sourceBufferSize is the number of log entries buffered between a source
+	// and the registry before the source is blocked.
+	sourceBufferSize = 64
+
+	// flushInterval is how often stages that hold entries back are flushed.
+	flushInterval = 250 * time.Millisecond
+)
+
+// Registry owns a set of named sources and forwards everything they produce
+// to the connection hub's broadcast channel.
+//
+// Sources can be registered before or after Start; late registrations are
+// started immediately so that sources can be added while the server runs.
+type Registry struct {
+	mu        sync.Mutex
+	sources   []Source
+	byName    map[string]Source
+	stages    map[string][]Stage
+	channels  map[string]chan model.Log
+	broadcast chan<- model.WebSocketMessage
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	started   bool
+}
+
+// NewRegistry creates an empty registry that forwards logs to broadcast.
+//
+// Parameters:
+//   - broadcast: The channel log messages are delivered to, usually hub.Broadcast
+//
+// Returns:
+//   - *Registry: A new registry instance
+func NewRegistry(broadcast chan<- model.WebSocketMessage) *Registry {
+	return &Registry{
+		byName:    make(map[string]Source),
+		stages:    make(map[string][]Stage),
+		channels:  make(map[string]chan model.Log),
+		broadcast: broadcast,
+	}
+}
+
+// Register adds a source to the registry.
+// If the registry is already running the source is started right away.
+//
+// Parameters:
+//   - src: The source to add
+//   - stages: Processing stages applied, in order, to every entry of the source
+//
+// Returns:
+//   - error: nil on success, or an error if the name is taken or the source fails to start
+func (r *Registry) Register(src Source, stages ...Stage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	name := src.Name()
+	if _, exists := r.byName[name]; exists {
+		return fmt.Errorf("source %q already registered", name)
+	}
+
+	r.sources = append(r.sources, src)
+	r.byName[name] = src
+	r.stages[name] = stages
+	log.Printf("Registered source %q", name)
+
+	if r.started {
+		return r.startSource(src)
+	}
+	return nil
+}
+
+// Start starts every registered source.
+// A source that fails to start does not prevent the others from running;
+// all start errors are joined and returned.
+//
+// Parameters:
+//   - ctx: Parent context for all sources; cancelling it stops the sources
+//
+// Returns:
+//   - error: nil if every source started, otherwise the joined start errors
+func (r *Registry) Start(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.started {
+		return errors.New("registry already started")
+	}
+	r.ctx, r.cancel = context.WithCancel(ctx)
+	r.started = true
+
+	var errs []error
+	for _, src := range r.sources {
+		if err := r.startSource(src); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// startSource starts a single source and its forwarding goroutine.
+// The caller must hold r.mu.
+func (r *Registry) startSource(src Source) error {
+	logs := make(chan model.Log, sourceBufferSize)
+	if err := src.Start(r.ctx, logs); err != nil {
+		log.Printf("Source %q failed to start: %v", src.Name(), err)
+		return fmt.Errorf("start source %q: %w", src.Name(), err)
+	}
+	r.channels[src.Name()] = logs
+
+	replayer, ok := src.(Replayer)
+	live := !ok || !replayer.Replays()
+
+	r.wg.Add(1)
+	go r.forward(src.Name(), logs, r.stages[src.Name()], live)
+	log.Printf("Started source %q", src.Name())
+	return nil
+}
+
+// forward runs the entries of one source through its stages and moves
+// them to the broadcast channel. Entries that do not name their own
+// source are attributed to the source that produced them, and entries
+// of live sources get the time they were received, which also stands in
+// for a missing event time; all timestamps leave in UTC. It exits once
+// the source is stopped and its channel has been drained, so entries
+// buffered at shutdown still reach the hub.
+func (r *Registry) forward(name string, logs <-chan model.Log, stages []Stage, live bool) {
+	defer r.wg.Done()
+
+	p := newPipeline(stages, func(entry model.Log) {
+		if entry.Source == "" {
+			entry.Source = name
+		}
+		if entry.Timestamp.IsZero() {
+			entry.Timestamp = entry.ReceivedAt
+		}
+		entry.Timestamp = entry.Timestamp.UTC()
+		entry.ReceivedAt = entry.ReceivedAt.UTC()
+		r.broadcast <- model.WebSocketMessage{
+			Type: model.MessageLog,
+			Data: entry,
+		}
+	})
+
+	// A nil channel never fires, so sources without buffering stages
+	// do not pay for the ticker.
+	var flush <-chan time.Time
+	if p.buffering() {
+		ticker := time.NewTicker(flushInterval)
+		defer ticker.Stop()
+		flush = ticker.C
+	}
+
+	for {
+		select {
+		case entry, ok := <-logs:
+			if !ok {
+				p.flush(true)
+				log.Printf("Stopped forwarding logs from source %q", name)
+				return
+			}
+			if live && entry.ReceivedAt.IsZero() {
+				entry.ReceivedAt = time.Now()
+			}
+			p.process(entry)
+		case <-flush:
+			p.flush(false)
+		}
+	}
+}
+
+// Stop stops all sources in reverse registration order and waits
+// for the forwarding goroutines to deliver what the sources left buffered.
+//
+// Returns:
+//   - error: The joined errors returned by the sources' Stop methods
+func (r *Registry) Stop() error {
+	r.mu.Lock()
+	if !r.started {
+		r.mu.Unlock()
+		return nil
+	}
+	r.started = false
+	r.cancel()
+	sources := append([]Source(nil), r.sources...)
+	channels := r.channels
+	r.channels = make(map[string]chan model.Log)
+	r.mu.Unlock()
+
+	var errs []error
+	for i := len(sources) - 1; i >= 0; i-- {
+		if err := sources[i].Stop(); err != nil {
+			errs = append(errs, fmt.Errorf("stop source %q: %w", sources[i].Name(), err))
+		}
+		// Sources never send after Stop returns, so the channel can be closed.
+		if logs, ok := channels[sources[i].Name()]; ok {
+			close(logs)
+		}
+	}
+
+	r.wg.Wait()
+	log.Printf("All sources stopped")
+	return errors.Join(errs...)
+}
+
+// Health returns the health of every registered source keyed by name.
+//
+// Returns:
+//   - map[string]Health: Health snapshots keyed by source name
+func (r *Registry) Health() map[string]Health {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	health := make(map[string]Health, len(r.sources))
+	for _, src := range r.sources {
+		health[src.Name()] = src.Health()
+	}
+	return health
+}
+
+// Names returns the names of all registered sources in registration order.
+//
+// Returns:
+//   - []string: The registered source names
+func (r *Registry) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.sources))
+	for _, src := range r.sources {
+		names = append(names, src.Name())
+	}
+	return names
+}
@@ -0,0 +1,253 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"smart-log-viewer/server/internal/model"
+)
+
+// fakeSource emits a fixed set of entries once started.
+type fakeSource struct {
+	Status
+
+	name     string
+	entries  []model.Log
+	replays  bool
+	startErr error
+	wg       sync.WaitGroup
+}
+
+func (s *fakeSource) Name() string { return s.name }
+
+func (s *fakeSource) Start(ctx context.Context, out chan<- model.Log) error {
+	if s.startErr != nil {
+		return s.startErr
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for _, entry := range s.entries {
+			if !s.Emit(ctx, out, entry) {
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (s *fakeSource) Stop() error {
+	s.wg.Wait()
+	return nil
+}
+
+// replayingSource is a fakeSource that reports replayed entries.
+type replayingSource struct {
+	*fakeSource
+}
+
+func (s replayingSource) Replays() bool { return true }
+
+// receive reads count log messages from broadcast.
+func receive(t *testing.T, broadcast <-chan model.WebSocketMessage, count int) []model.Log {
+	t.Helper()
+
+	var logs []model.Log
+	for len(logs) < count {
+		select {
+		case message := <-broadcast:
+			logs = append(logs, message.Data.(model.Log))
+		case <-time.After(2 * time.Second):
+			t.Fatalf("received %d of %d logs", len(logs), count)
+		}
+	}
+	return logs
+}
+
+func TestRegistryForward(t *testing.T) {
+	eventTime := time.Date(2024, time.March, 10, 11, 22, 33, 0, time.FixedZone("CET", 3600))
+	receivedAt := time.Date(2024, time.March, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		src   Source
+		entry model.Log
+		check func(t *testing.T, entry model.Log)
+	}{
+		{
+			name:  "fills source and receive time",
+			src:   &fakeSource{name: "app"},
+			entry: model.Log{Message: "hello", Timestamp: eventTime},
+			check: func(t *testing.T, entry model.Log) {
+				if entry.Source != "app" {
+					t.Errorf("Source = %q, want app", entry.Source)
+				}
+				if entry.ReceivedAt.IsZero() || entry.ReceivedAt.Location() != time.UTC {
+					t.Errorf("ReceivedAt = %v, want the current time in UTC", entry.ReceivedAt)
+				}
+				if !entry.Timestamp.Equal(eventTime) || entry.Timestamp.Location() != time.UTC {
+					t.Errorf("Timestamp = %v, want %v in UTC", entry.Timestamp, eventTime)
+				}
+			},
+		},
+		{
+			name:  "keeps source and receive time of the entry",
+			src:   &fakeSource{name: "ingest"},
+			entry: model.Log{Message: "pushed", Source: "shipper", ReceivedAt: receivedAt},
+			check: func(t *testing.T, entry model.Log) {
+				if entry.Source != "shipper" {
+					t.Errorf("Source = %q, want shipper", entry.Source)
+				}
+				if !entry.ReceivedAt.Equal(receivedAt) {
+					t.Errorf("ReceivedAt = %v, want %v", entry.ReceivedAt, receivedAt)
+				}
+				if !entry.Timestamp.Equal(receivedAt) {
+					t.Errorf("Timestamp = %v, want the receive time %v", entry.Timestamp, receivedAt)
+				}
+			},
+		},
+		{
+			name:  "replayed entries get no receive time",
+			src:   replayingSource{&fakeSource{name: "archive"}},
+			entry: model.Log{Message: "old", Timestamp: eventTime},
+			check: func(t *testing.T, entry model.Log) {
+				if !entry.ReceivedAt.IsZero() {
+					t.Errorf("ReceivedAt = %v, want zero", entry.ReceivedAt)
+				}
+				if !entry.Timestamp.Equal(eventTime) {
+					t.Errorf("Timestamp = %v, want %v", entry.Timestamp, eventTime)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			switch src := tt.src.(type) {
+			case *fakeSource:
+				src.entries = []model.Log{tt.entry}
+			case replayingSource:
+				src.entries = []model.Log{tt.entry}
+			}
+
+			broadcast := make(chan model.WebSocketMessage, 1)
+			registry := NewRegistry(broadcast)
+			if err := registry.Register(tt.src); err != nil {
+				t.Fatal(err)
+			}
+			if err := registry.Start(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			defer registry.Stop()
+
+			tt.check(t, receive(t, broadcast, 1)[0])
+		})
+	}
+}
+
+func TestRegistryRegister(t *testing.T) {
+	broadcast := make(chan model.WebSocketMessage, 4)
+	registry := NewRegistry(broadcast)
+
+	if err := registry.Register(&fakeSource{name: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Register(&fakeSource{name: "a"}); err == nil || !strings.Contains(err.Error(), "already registered") {
+		t.Fatalf("Register() duplicate error = %v, want already registered", err)
+	}
+
+	failing := &fakeSource{name: "broken", startErr: errors.New("no such file")}
+	if err := registry.Register(failing); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Start(context.Background()); err == nil || !strings.Contains(err.Error(), "no such file") {
+		t.Fatalf("Start() error = %v, want the failed source's error", err)
+	}
+	if err := registry.Start(context.Background()); err == nil {
+		t.Fatal("second Start() succeeded")
+	}
+
+	// Sources registered while running start right away.
+	late := &fakeSource{name: "late", entries: []model.Log{{Message: "late entry"}}}
+	if err := registry.Register(late); err != nil {
+		t.Fatal(err)
+	}
+	if entry := receive(t, broadcast, 1)[0]; entry.Source != "late" {
+		t.Errorf("Source = %q, want late", entry.Source)
+	}
+
+	if got, want := strings.Join(registry.Names(), ","), "a,broken,late"; got != want {
+		t.Errorf("Names() = %q, want %q", got, want)
+	}
+	if err := registry.Stop(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		apply     func(s *Status)
+		wantState State
+		wantError string
+	}{
+		{name: "zero value", apply: func(s *Status) {}, wantState: StateStarting},
+		{name: "running", apply: func(s *Status) { s.SetState(StateRunning) }, wantState: StateRunning},
+		{
+			name:      "error",
+			apply:     func(s *Status) { s.SetError(StateDegraded, errors.New("disk full")) },
+			wantState: StateDegraded,
+			wantError: "disk full",
+		},
+		{
+			name: "running again clears the error",
+			apply: func(s *Status) {
+				s.SetError(StateDegraded, errors.New("disk full"))
+				s.SetState(StateRunning)
+			},
+			wantState: StateRunning,
+		},
+		{
+			name: "stopping keeps the error",
+			apply: func(s *Status) {
+				s.SetError(StateFailed, errors.New("gone"))
+				s.SetState(StateStopped)
+			},
+			wantState: StateStopped,
+			wantError: "gone",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s Status
+			tt.apply(&s)
+			if health := s.Health(); health.State != tt.wantState || health.LastError != tt.wantError {
+				t.Errorf("Health() = %+v, want state %q and error %q", health, tt.wantState, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestStatusEmit(t *testing.T) {
+	var s Status
+	out := make(chan model.Log, 1)
+	if !s.Emit(context.Background(), out, model.Log{Message: "a"}) {
+		t.Fatal("Emit() to a free channel failed")
+	}
+
+	// A full channel gives up once the context is cancelled.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if s.Emit(ctx, out, model.Log{Message: "b"}) {
+		t.Fatal("Emit() to a full channel succeeded after cancellation")
+	}
+
+	if health := s.Health(); health.Emitted != 1 || health.LastEmit.IsZero() {
+		t.Errorf("Health() = %+v, want one emitted entry", health)
+	}
+}
@@ -0,0 +1,170 @@
+// Package source defines the pluggable log source abstraction used by the server.
+// A source produces model.Log entries from somewhere (a mock generator, a file,
+// a network listener, ...) and the Registry forwards them to the connection hub.
+package source
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"smart-log-viewer/server/internal/model"
+)
+
+// Source is a producer of log entries.
+// Implementations must be safe to Stop concurrently with their own
+// producing goroutines and must stop sending on out once Stop returns.
+type Source interface {
+	// Name returns the unique name of this source instance.
+	Name() string
+
+	// Start begins producing log entries on out. It must not block;
+	// long-running work belongs in goroutines owned by the source.
+	// The context is cancelled when the registry shuts down.
+	Start(ctx context.Context, out chan<- model.Log) error
+
+	// Stop terminates the source and waits for its goroutines to exit.
+	Stop() error
+
+	// Health reports the current state of the source.
+	Health() Health
+}
+
+// Replayer is implemented by sources that emit logs recorded earlier
+// rather than as they happen, such as archive replays. Their entries get
+// no receive time, which would only measure the age of the archive.
+type Replayer interface {
+	// Replays reports whether the source replays recorded logs.
+	Replays() bool
+}
+
+// State describes the lifecycle state of a source.
+type State string
+
+const (
+	// StateStarting means the source has been created but is not producing yet.
+	StateStarting State = "starting"
+	// StateRunning means the source is producing log entries normally.
+	StateRunning State = "running"
+	// StateDegraded means the source is running but hit a recoverable error.
+	StateDegraded State = "degraded"
+	// StateStopped means the source was stopped deliberately.
+	StateStopped State = "stopped"
+	// StateFailed means the source hit an unrecoverable error.
+	StateFailed State = "failed"
+)
+
+// Health is a point-in-time snapshot of a source's state.
+type Health struct {
+	// State is the lifecycle state of the source.
+	State State `json:"state"`
+
+	// Emitted counts the log entries the source has produced.
+	Emitted uint64 `json:"emitted"`
+
+	// LastEmit records when the source last produced a log entry.
+	LastEmit time.Time `json:"lastEmit,omitempty"`
+
+	// LastError holds the most recent error reported by the source.
+	LastError string `json:"lastError,omitempty"`
+}
+
+// Status is a thread-safe health tracker that source implementations embed
+// to satisfy the Health method of the Source interface.
+//
+// The zero value is ready to use and reports StateStarting.
+type Status struct {
+	mu     sync.RWMutex
+	health Health
+}
+
+// Health returns a snapshot of the tracked health.
+//
+// Returns:
+//   - Health: The current health of the source
+func (s *Status) Health() Health {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	health := s.health
+	if health.State == "" {
+		health.State = StateStarting
+	}
+	return health
+}
+
+// SetState changes the lifecycle state.
+// Moving back to StateRunning clears the last recorded error.
+//
+// Parameters:
+//   - state: The new lifecycle state
+func (s *Status) SetState(state State) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.health.State = state
+	if state == StateRunning {
+		s.health.LastError = ""
+	}
+}
+
+// SetError records an error and moves the source to the given state.
+//
+// Parameters:
+//   - state: The new lifecycle state, usually StateDegraded or StateFailed
+//   - err: The error that caused the state change
+func (s *Status) SetError(state State, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.health.State = state
+	if err != nil {
+		s.health.LastError = err.Error()
+	}
+}
+
+// Emit sends a log entry on out and records it in the health counters.
+// It gives up when ctx is cancelled so that producers never block shutdown,
+// and never sends once ctx is cancelled, since out is closed after Stop.
+//
+// Parameters:
+//   - ctx: Context that aborts the send when cancelled
+//   - out: Channel the entry is sent to
+//   - entry: The log entry to send
+//
+// Returns:
+//   - bool: true if the entry was sent, false if ctx was cancelled first
+func (s *Status) Emit(ctx context.Context, out chan<- model.Log, entry model.Log) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	select {
+	case out <- entry:
+	case <-ctx.Done():
+		return false
+	}
+
+	s.mu.Lock()
+	s.health.Emitted++
+	s.health.LastEmit = time.Now()
+	s.mu.Unlock()
+	return true
+}
+
+// localHostname caches the hostname of the machine the server runs on.
+var localHostname = sync.OnceValue(func() string {
+	name, _ := os.Hostname()
+	return name
+})
+
+// LocalHostname returns the hostname of the machine the server runs on.
+// Sources that read local files or run local processes use it as the
+// host of their entries.
+//
+// Returns:
+//   - string: The hostname, or "" if it cannot be determined
+func LocalHostname() string {
+	return localHostname()
+}
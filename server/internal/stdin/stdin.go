@@ -0,0 +1,115 @@
+// Package stdin provides a log source that reads lines from a stream such as
+// the server's standard input, so the viewer can sit at the end of any shell
+// pipeline: `kubectl logs -f pod | smart-log-viewer -stdin`.
+package stdin
+
+import (
+	"context"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"smart-log-viewer/server/internal/level"
+	"smart-log-viewer/server/internal/model"
+	"smart-log-viewer/server/internal/source"
+)
+
+// Source is a source.Source that emits every line read from a stream.
+type Source struct {
+	source.Status
+
+	name   string
+	reader io.Reader
+	onEOF  func()
+	cancel context.CancelFunc
+
+	// emitMu lets Stop wait for an in-flight Emit without waiting for a
+	// blocked read.
+	emitMu sync.Mutex
+}
+
+// NewSource creates a stream source.
+//
+// Parameters:
+//   - name: The unique name of the source
+//   - reader: The stream to read, usually os.Stdin
+//   - onEOF: Called once the stream is exhausted; may be nil
+//
+// Returns:
+//   - *Source: A new stream source instance
+func NewSource(name string, reader io.Reader, onEOF func()) *Source {
+	return &Source{
+		name:   name,
+		reader: reader,
+		onEOF:  onEOF,
+	}
+}
+
+// Name returns the unique name of the source.
+func (s *Source) Name() string {
+	return s.name
+}
+
+// Start launches the reader goroutine.
+//
+// Parameters:
+//   - ctx: Context that stops emitting when cancelled
+//   - out: Channel log entries are sent to
+//
+// Returns:
+//   - error: Always nil
+func (s *Source) Start(ctx context.Context, out chan<- model.Log) error {
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.SetState(source.StateRunning)
+
+	go s.run(ctx, out)
+	return nil
+}
+
+// run reads the stream until it ends or ctx is cancelled.
+func (s *Source) run(ctx context.Context, out chan<- model.Log) {
+	err := source.ReadLines(s.reader, func(line string) bool {
+		s.emitMu.Lock()
+		defer s.emitMu.Unlock()
+
+		return s.Emit(ctx, out, model.Log{
+			Level:      level.Info,
+			Message:    line,
+			ReceivedAt: time.Now(),
+			Source:     s.name,
+			Host:       source.LocalHostname(),
+		})
+	})
+	if ctx.Err() != nil {
+		return
+	}
+
+	if err != nil {
+		log.Printf("Stream source %q: read error: %v", s.name, err)
+		s.SetError(source.StateFailed, err)
+	} else {
+		log.Printf("Stream source %q: reached end of input", s.name)
+		s.SetState(source.StateStopped)
+	}
+	if s.onEOF != nil {
+		s.onEOF()
+	}
+}
+
+// Stop stops emitting. A read that is blocked on the stream cannot be
+// interrupted, so Stop does not wait for it; whatever it reads afterwards
+// is discarded.
+//
+// Returns:
+//   - error: Always nil
+func (s *Source) Stop() error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.emitMu.Lock()
+	defer s.emitMu.Unlock()
+
+	s.SetState(source.StateStopped)
+	return nil
+}
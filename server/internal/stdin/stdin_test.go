@@ -0,0 +1,107 @@
+package stdin
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"smart-log-viewer/server/internal/model"
+	"smart-log-viewer/server/internal/source"
+)
+
+func TestSourceReadsStream(t *testing.T) {
+	tests := []struct {
+		name      string
+		reader    io.Reader
+		want      []string
+		wantState source.State
+		wantError string
+	}{
+		{
+			name:      "lines until end of input",
+			reader:    strings.NewReader("first\r\nsecond\nlast"),
+			want:      []string{"first", "second", "last"},
+			wantState: source.StateStopped,
+		},
+		{
+			name:      "empty input",
+			reader:    strings.NewReader(""),
+			wantState: source.StateStopped,
+		},
+		{
+			name:      "read error",
+			reader:    io.MultiReader(strings.NewReader("before\n"), iotest.ErrReader(errors.New("broken pipe"))),
+			want:      []string{"before"},
+			wantState: source.StateFailed,
+			wantError: "broken pipe",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			done := make(chan struct{})
+			src := NewSource("stdin", tt.reader, func() { close(done) })
+			out := make(chan model.Log, 10)
+			if err := src.Start(context.Background(), out); err != nil {
+				t.Fatal(err)
+			}
+
+			select {
+			case <-done:
+			case <-time.After(2 * time.Second):
+				t.Fatal("onEOF was not called")
+			}
+			if health := src.Health(); health.State != tt.wantState || health.LastError != tt.wantError {
+				t.Errorf("health = %+v, want state %q and error %q", health, tt.wantState, tt.wantError)
+			}
+			src.Stop()
+			close(out)
+
+			var got []string
+			for entry := range out {
+				if entry.Source != "stdin" || entry.Level != "INFO" || entry.ReceivedAt.IsZero() {
+					t.Errorf("entry = %+v, want an INFO entry from stdin with a receive time", entry)
+				}
+				got = append(got, entry.Message)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("messages = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStopDoesNotWaitForBlockedRead(t *testing.T) {
+	reader, writer := io.Pipe()
+	defer writer.Close()
+
+	src := NewSource("stdin", reader, nil)
+	out := make(chan model.Log)
+	if err := src.Start(context.Background(), out); err != nil {
+		t.Fatal(err)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		src.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Stop waited for a blocked read")
+	}
+
+	// Lines read after Stop are discarded rather than sent.
+	go writer.Write([]byte("late\n"))
+	select {
+	case entry := <-out:
+		t.Fatalf("received %q after Stop", entry.Message)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,274 @@
+package syslog
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"smart-log-viewer/server/internal/level"
+	"smart-log-viewer/server/internal/model"
+)
+
+// defaultPriority is used for messages without a PRI part,
+// as RFC 3164 section 4.3.3 prescribes (user.notice).
+const defaultPriority = 13
+
+// nilValue marks an absent header field in RFC 5424 messages.
+const nilValue = "-"
+
+// facilityNames maps syslog facility codes to their conventional names.
+var facilityNames = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+// errEmptyMessage is returned for frames that contain no message at all.
+var errEmptyMessage = errors.New("empty syslog message")
+
+// parseMessage parses an RFC 5424 or RFC 3164 message into a log entry.
+// The format is detected from the version field that follows the PRI part.
+//
+// Parameters:
+//   - data: The raw message without transport framing
+//   - received: When the message was received, used when it carries no timestamp
+//
+// Returns:
+//   - model.Log: The parsed log entry
+//   - error: nil on success, or an error if the message is malformed
+func parseMessage(data string, received time.Time) (model.Log, error) {
+	data = strings.TrimRight(data, "\r\n\x00")
+	if data == "" {
+		return model.Log{}, errEmptyMessage
+	}
+
+	priority, rest, err := parsePriority(data)
+	if err != nil {
+		return model.Log{}, err
+	}
+
+	var entry model.Log
+	if strings.HasPrefix(rest, "1 ") {
+		entry, err = parseRFC5424(rest[2:], received)
+	} else {
+		entry = parseRFC3164(rest, received)
+	}
+	if err != nil {
+		return model.Log{}, err
+	}
+
+	facility, severity := priority/8, priority%8
+	entry.Level = level.FromSyslog(severity)
+	entry.RawLevel = level.SyslogName(severity)
+	if facility < len(facilityNames) {
+		entry.Fields["facility"] = facilityNames[facility]
+	} else {
+		entry.Fields["facility"] = strconv.Itoa(facility)
+	}
+	return entry, nil
+}
+
+// parsePriority splits off the "<PRI>" prefix.
+// Messages without one get the default priority.
+func parsePriority(data string) (int, string, error) {
+	if !strings.HasPrefix(data, "<") {
+		return defaultPriority, data, nil
+	}
+
+	end := strings.IndexByte(data, '>')
+	if end < 2 || end > 4 {
+		return 0, "", fmt.Errorf("invalid syslog priority in %q", truncate(data))
+	}
+	priority, err := strconv.Atoi(data[1:end])
+	if err != nil || priority < 0 || priority > 191 {
+		return 0, "", fmt.Errorf("invalid syslog priority %q", data[1:end])
+	}
+	return priority, data[end+1:], nil
+}
+
+// parseRFC5424 parses the part of an RFC 5424 message after "<PRI>1 ":
+// TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA [MSG]
+func parseRFC5424(data string, received time.Time) (model.Log, error) {
+	parts := strings.SplitN(data, " ", 6)
+	if len(parts) < 6 {
+		return model.Log{}, fmt.Errorf("truncated RFC 5424 header in %q", truncate(data))
+	}
+
+	entry := model.Log{
+		Timestamp: received,
+		Fields:    make(map[string]interface{}),
+	}
+
+	if parts[0] != nilValue {
+		timestamp, err := time.Parse(time.RFC3339Nano, parts[0])
+		if err != nil {
+			return model.Log{}, fmt.Errorf("invalid RFC 5424 timestamp %q", parts[0])
+		}
+		entry.Timestamp = timestamp
+	}
+	if parts[1] != nilValue {
+		entry.Host = parts[1]
+	}
+	setField(entry.Fields, "app_name", parts[2])
+	setField(entry.Fields, "proc_id", parts[3])
+	setField(entry.Fields, "msg_id", parts[4])
+
+	structured, message, err := parseStructuredData(parts[5])
+	if err != nil {
+		return model.Log{}, err
+	}
+	if len(structured) > 0 {
+		entry.Fields["structured_data"] = structured
+	}
+	entry.Message = strings.TrimPrefix(message, "\ufeff")
+	return entry, nil
+}
+
+// parseStructuredData parses the STRUCTURED-DATA part of an RFC 5424
+// message and returns the elements and the remaining MSG part.
+func parseStructuredData(data string) (map[string]map[string]string, string, error) {
+	if data == nilValue {
+		return nil, "", nil
+	}
+	if strings.HasPrefix(data, nilValue+" ") {
+		return nil, data[2:], nil
+	}
+	if !strings.HasPrefix(data, "[") {
+		return nil, "", fmt.Errorf("invalid RFC 5424 structured data in %q", truncate(data))
+	}
+
+	elements := make(map[string]map[string]string)
+	i := 0
+	for i < len(data) && data[i] == '[' {
+		i++
+		start := i
+		for i < len(data) && data[i] != ' ' && data[i] != ']' {
+			i++
+		}
+		id := data[start:i]
+		params := make(map[string]string)
+
+		for i < len(data) && data[i] == ' ' {
+			i++
+			start = i
+			for i < len(data) && data[i] != '=' {
+				i++
+			}
+			if i+1 >= len(data) || data[i+1] != '"' {
+				return nil, "", fmt.Errorf("invalid structured data parameter in element %q", id)
+			}
+			name := data[start:i]
+			i += 2
+
+			var value strings.Builder
+			for i < len(data) && data[i] != '"' {
+				if data[i] == '\\' && i+1 < len(data) && strings.IndexByte(`"\]`, data[i+1]) >= 0 {
+					i++
+				}
+				value.WriteByte(data[i])
+				i++
+			}
+			if i >= len(data) {
+				return nil, "", fmt.Errorf("unterminated structured data value in element %q", id)
+			}
+			i++
+			params[name] = value.String()
+		}
+
+		if i >= len(data) || data[i] != ']' {
+			return nil, "", fmt.Errorf("unterminated structured data element %q", id)
+		}
+		i++
+		elements[id] = params
+	}
+
+	return elements, strings.TrimPrefix(data[i:], " "), nil
+}
+
+// parseRFC3164 parses the part of a BSD syslog message after "<PRI>":
+// [TIMESTAMP HOSTNAME] TAG[PID]: MSG
+// BSD syslog is loosely specified, so every part is optional and anything
+// that cannot be recognised ends up in the message.
+func parseRFC3164(data string, received time.Time) model.Log {
+	entry := model.Log{
+		Timestamp: received,
+		Fields:    make(map[string]interface{}),
+	}
+
+	rest, timestamp, ok := parseBSDTimestamp(data, received)
+	if ok {
+		entry.Timestamp = timestamp
+		// A hostname follows the timestamp unless the next token is already the tag.
+		if space := strings.IndexByte(rest, ' '); space > 0 {
+			token := rest[:space]
+			if !strings.HasSuffix(token, ":") && !strings.Contains(token, "[") {
+				entry.Host = token
+				rest = rest[space+1:]
+			}
+		}
+	}
+
+	rest = parseTag(rest, entry.Fields)
+	entry.Message = rest
+	return entry
+}
+
+// parseBSDTimestamp recognises the "Mmm dd hh:mm:ss" timestamp of RFC 3164,
+// as well as RFC 3339 timestamps sent by newer BSD-style senders.
+// BSD timestamps carry no year, so the year closest to received is used.
+func parseBSDTimestamp(data string, received time.Time) (string, time.Time, bool) {
+	if len(data) >= len(time.Stamp) {
+		if t, err := time.ParseInLocation(time.Stamp, data[:len(time.Stamp)], received.Location()); err == nil {
+			t = t.AddDate(received.Year(), 0, 0)
+			if t.After(received.Add(24 * time.Hour)) {
+				t = t.AddDate(-1, 0, 0)
+			}
+			return strings.TrimPrefix(data[len(time.Stamp):], " "), t, true
+		}
+	}
+
+	if space := strings.IndexByte(data, ' '); space > 0 {
+		if t, err := time.Parse(time.RFC3339Nano, data[:space]); err == nil {
+			return data[space+1:], t, true
+		}
+	}
+	return data, time.Time{}, false
+}
+
+// parseTag extracts the "TAG[PID]:" prefix into app_name and proc_id
+// and returns the remaining message.
+func parseTag(data string, fields map[string]interface{}) string {
+	colon := strings.IndexByte(data, ':')
+	if colon <= 0 || colon > 48 {
+		return data
+	}
+
+	tag := data[:colon]
+	if strings.ContainsAny(tag, " \t") {
+		return data
+	}
+	if open := strings.IndexByte(tag, '['); open > 0 && strings.HasSuffix(tag, "]") {
+		setField(fields, "proc_id", tag[open+1:len(tag)-1])
+		tag = tag[:open]
+	}
+	setField(fields, "app_name", tag)
+	return strings.TrimPrefix(data[colon+1:], " ")
+}
+
+// setField stores a header value unless it is empty or the RFC 5424 nil value.
+func setField(fields map[string]interface{}, key, value string) {
+	if value != "" && value != nilValue {
+		fields[key] = value
+	}
+}
+
+// truncate shortens data for use in error messages.
+func truncate(data string) string {
+	const max = 64
+	if len(data) > max {
+		return data[:max] + "..."
+	}
+	return data
+}
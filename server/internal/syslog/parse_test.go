@@ -0,0 +1,227 @@
+package syslog
+
+import (
+	"bufio"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"smart-log-viewer/server/internal/model"
+)
+
+func TestParseMessage(t *testing.T) {
+	received := time.Date(2024, time.March, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		data    string
+		want    model.Log
+		wantErr string
+	}{
+		{
+			name: "rfc5424 full header",
+			data: `<165>1 2024-03-10T11:22:33.456Z web01 nginx 1234 ID47 - request done`,
+			want: model.Log{
+				Level: "NOTICE", RawLevel: "notice", Message: "request done", Host: "web01",
+				Timestamp: time.Date(2024, time.March, 10, 11, 22, 33, 456e6, time.UTC),
+				Fields:    map[string]interface{}{"facility": "local4", "app_name": "nginx", "proc_id": "1234", "msg_id": "ID47"},
+			},
+		},
+		{
+			name: "rfc5424 nil values and no message",
+			data: `<11>1 - - - - - -`,
+			want: model.Log{
+				Level: "ERROR", RawLevel: "err", Timestamp: received,
+				Fields: map[string]interface{}{"facility": "user"},
+			},
+		},
+		{
+			name: "rfc5424 structured data with escapes and BOM",
+			data: "<14>1 2024-03-10T11:22:33Z host app - - [exampleSDID@32473 iut=\"3\" eventSource=\"App\\\"lication\\]\"][meta x=\"\"] \ufeffhello",
+			want: model.Log{
+				Level: "INFO", RawLevel: "info", Message: "hello", Host: "host",
+				Timestamp: time.Date(2024, time.March, 10, 11, 22, 33, 0, time.UTC),
+				Fields: map[string]interface{}{
+					"facility": "user", "app_name": "app",
+					"structured_data": map[string]map[string]string{
+						"exampleSDID@32473": {"iut": "3", "eventSource": `App"lication]`},
+						"meta":              {"x": ""},
+					},
+				},
+			},
+		},
+		{
+			name: "rfc3164 with timestamp, host and tag",
+			data: "<34>Mar  9 22:14:15 mymachine su[42]: 'su root' failed",
+			want: model.Log{
+				Level: "CRITICAL", RawLevel: "crit", Message: "'su root' failed", Host: "mymachine",
+				Timestamp: time.Date(2024, time.March, 9, 22, 14, 15, 0, time.UTC),
+				Fields:    map[string]interface{}{"facility": "auth", "app_name": "su", "proc_id": "42"},
+			},
+		},
+		{
+			name: "rfc3164 timestamp from december is last year",
+			data: "<13>Dec 31 23:59:59 box cron: tick",
+			want: model.Log{
+				Level: "NOTICE", RawLevel: "notice", Message: "tick", Host: "box",
+				Timestamp: time.Date(2023, time.December, 31, 23, 59, 59, 0, time.UTC),
+				Fields:    map[string]interface{}{"facility": "user", "app_name": "cron"},
+			},
+		},
+		{
+			name: "rfc3164 tag right after timestamp",
+			data: "<30>Mar 10 11:00:00 sshd[7]: accepted",
+			want: model.Log{
+				Level: "INFO", RawLevel: "info", Message: "accepted",
+				Timestamp: time.Date(2024, time.March, 10, 11, 0, 0, 0, time.UTC),
+				Fields:    map[string]interface{}{"facility": "daemon", "app_name": "sshd", "proc_id": "7"},
+			},
+		},
+		{
+			name: "without priority",
+			data: "just a line\r\n",
+			want: model.Log{
+				Level: "NOTICE", RawLevel: "notice", Message: "just a line", Timestamp: received,
+				Fields: map[string]interface{}{"facility": "user"},
+			},
+		},
+		{
+			name: "highest priority",
+			data: "<191>message",
+			want: model.Log{
+				Level: "DEBUG", RawLevel: "debug", Message: "message", Timestamp: received,
+				Fields: map[string]interface{}{"facility": "local7"},
+			},
+		},
+		{name: "empty", data: "\r\n", wantErr: "empty syslog message"},
+		{name: "unterminated priority", data: "<13 message", wantErr: "invalid syslog priority"},
+		{name: "priority too large", data: "<192>message", wantErr: "invalid syslog priority"},
+		{name: "priority not a number", data: "<x1>message", wantErr: "invalid syslog priority"},
+		{name: "truncated rfc5424 header", data: "<13>1 2024-03-10T11:22:33Z host app", wantErr: "truncated RFC 5424 header"},
+		{name: "invalid rfc5424 timestamp", data: "<13>1 yesterday host app - - - msg", wantErr: "invalid RFC 5424 timestamp"},
+		{name: "structured data not bracketed", data: "<13>1 - host app - - x msg", wantErr: "invalid RFC 5424 structured data"},
+		{name: "unterminated structured data value", data: `<13>1 - host app - - [id a="1`, wantErr: "unterminated structured data value"},
+		{name: "unterminated structured data element", data: `<13>1 - host app - - [id a="1"`, wantErr: "unterminated structured data element"},
+		{name: "unquoted structured data value", data: `<13>1 - host app - - [id a=1]`, wantErr: "invalid structured data parameter"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := parseMessage(tt.data, received)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseMessage() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseMessage() error = %v", err)
+			}
+			if !reflect.DeepEqual(entry, tt.want) {
+				t.Errorf("parseMessage() = %+v, want %+v", entry, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadFrame(t *testing.T) {
+	tests := []struct {
+		name    string
+		stream  string
+		maxSize int
+		want    []string
+		wantErr string
+	}{
+		{
+			name:   "newline delimited",
+			stream: "<13>first\n<13>second\n",
+			want:   []string{"<13>first\n", "<13>second\n"},
+		},
+		{
+			name:   "last line without newline",
+			stream: "<13>first\n<13>last",
+			want:   []string{"<13>first\n", "<13>last"},
+		},
+		{
+			name:   "octet counted",
+			stream: "9 <13>a\nbcd11 <13>message",
+			want:   []string{"<13>a\nbcd", "<13>message"},
+		},
+		{
+			name:    "truncated octet counted",
+			stream:  "20 <13>short",
+			wantErr: "read octet-counted message",
+		},
+		{
+			name:   "ten digit octet count",
+			stream: "0000000003 <1>",
+			want:   []string{"<1>"},
+		},
+		{
+			name:   "line starting with a number",
+			stream: "1x <13>a\n12\n",
+			want:   []string{"1x <13>a\n", "12\n"},
+		},
+		{
+			name:   "digit run too long for an octet count",
+			stream: "12345678901 <13>a\n",
+			want:   []string{"12345678901 <13>a\n"},
+		},
+		{
+			name:   "digits at the end of the stream",
+			stream: "12",
+			want:   []string{"12"},
+		},
+		{
+			name:    "zero octet count",
+			stream:  "0 <13>a",
+			wantErr: "invalid octet count",
+		},
+		{
+			name:    "octet count over limit",
+			stream:  "100 <13>a",
+			maxSize: 50,
+			wantErr: "exceeds limit",
+		},
+		{
+			name:    "line over limit",
+			stream:  strings.Repeat("a", 60) + "\n",
+			maxSize: 50,
+			wantErr: "exceeds limit",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxSize := tt.maxSize
+			if maxSize == 0 {
+				maxSize = defaultMaxMessageSize
+			}
+			reader := bufio.NewReaderSize(strings.NewReader(tt.stream), 16)
+
+			var got []string
+			var err error
+			for {
+				var frame string
+				if frame, err = readFrame(reader, maxSize); err != nil {
+					break
+				}
+				got = append(got, frame)
+			}
+
+			if tt.wantErr != "" {
+				if !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("readFrame() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err.Error() != "EOF" {
+				t.Fatalf("readFrame() error = %v, want EOF", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("frames = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
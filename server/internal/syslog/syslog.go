@@ -0,0 +1,309 @@
+// Package syslog provides a log source that receives syslog messages over
+// UDP and TCP. Both RFC 5424 and BSD (RFC 3164) messages are understood, and
+// TCP streams may use octet-counted or newline-delimited framing (RFC 6587).
+package syslog
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"smart-log-viewer/server/internal/model"
+	"smart-log-viewer/server/internal/source"
+)
+
+const (
+	// defaultMaxMessageSize is the largest message accepted when none is configured.
+	defaultMaxMessageSize = 64 * 1024
+
+	// maxOctetCountDigits is the longest octet count accepted on TCP; ten
+	// digits cover any message size a sender could mean.
+	maxOctetCountDigits = 10
+)
+
+// Config holds the options of a syslog source.
+type Config struct {
+	// UDPAddr is the UDP address to listen on, e.g. ":5514". Empty disables UDP.
+	UDPAddr string `json:"udp"`
+
+	// TCPAddr is the TCP address to listen on, e.g. ":5514". Empty disables TCP.
+	TCPAddr string `json:"tcp"`
+
+	// MaxMessageSize is the largest accepted message in bytes (default 64 KiB).
+	MaxMessageSize int `json:"maxMessageSize"`
+}
+
+// Source is a source.Source that listens for syslog messages.
+type Source struct {
+	source.Status
+
+	name      string
+	cfg       Config
+	udpConn   net.PacketConn
+	tcpLn     net.Listener
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	conns     source.Conns
+	closeOnce sync.Once
+}
+
+// NewSource creates a syslog source.
+//
+// Parameters:
+//   - name: The unique name of the source
+//   - cfg: The source options
+//
+// Returns:
+//   - *Source: A new syslog source instance
+//   - error: nil on success, or an error if no listen address is configured
+func NewSource(name string, cfg Config) (*Source, error) {
+	if cfg.UDPAddr == "" && cfg.TCPAddr == "" {
+		return nil, errors.New("syslog source needs a udp or tcp address")
+	}
+	if cfg.MaxMessageSize <= 0 {
+		cfg.MaxMessageSize = defaultMaxMessageSize
+	}
+	return &Source{name: name, cfg: cfg}, nil
+}
+
+// Name returns the unique name of the source.
+func (s *Source) Name() string {
+	return s.name
+}
+
+// Start opens the configured listeners.
+//
+// Parameters:
+//   - ctx: Context that stops the listeners when cancelled
+//   - out: Channel parsed log entries are sent to
+//
+// Returns:
+//   - error: nil on success, or an error if a listener cannot be opened
+func (s *Source) Start(ctx context.Context, out chan<- model.Log) error {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	if s.cfg.UDPAddr != "" {
+		conn, err := net.ListenPacket("udp", s.cfg.UDPAddr)
+		if err != nil {
+			s.cancel()
+			s.SetError(source.StateFailed, err)
+			return fmt.Errorf("listen syslog udp: %w", err)
+		}
+		s.udpConn = conn
+		log.Printf("Syslog source %q listening on udp %s", s.name, conn.LocalAddr())
+	}
+
+	if s.cfg.TCPAddr != "" {
+		ln, err := net.Listen("tcp", s.cfg.TCPAddr)
+		if err != nil {
+			s.cancel()
+			s.closeListeners()
+			s.SetError(source.StateFailed, err)
+			return fmt.Errorf("listen syslog tcp: %w", err)
+		}
+		s.tcpLn = ln
+		log.Printf("Syslog source %q listening on tcp %s", s.name, ln.Addr())
+	}
+
+	s.SetState(source.StateRunning)
+
+	if s.udpConn != nil {
+		s.wg.Add(1)
+		go s.serveUDP(ctx, out)
+	}
+	if s.tcpLn != nil {
+		s.wg.Add(1)
+		go s.serveTCP(ctx, out)
+	}
+
+	// Closing the listeners unblocks the serving goroutines on shutdown.
+	go func() {
+		<-ctx.Done()
+		s.closeListeners()
+	}()
+	return nil
+}
+
+// serveUDP reads one syslog message per datagram.
+func (s *Source) serveUDP(ctx context.Context, out chan<- model.Log) {
+	defer s.wg.Done()
+
+	buf := make([]byte, s.cfg.MaxMessageSize)
+	for {
+		n, addr, err := s.udpConn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.reportError(err)
+			}
+			return
+		}
+		if !s.handle(ctx, out, string(buf[:n]), addr) {
+			return
+		}
+	}
+}
+
+// serveTCP accepts TCP connections and serves each in its own goroutine.
+func (s *Source) serveTCP(ctx context.Context, out chan<- model.Log) {
+	defer s.wg.Done()
+
+	err := s.conns.Accept(s.tcpLn, &s.wg, func(conn net.Conn) {
+		s.serveConn(ctx, out, conn)
+	})
+	if ctx.Err() == nil {
+		s.reportError(err)
+	}
+}
+
+// serveConn reads framed syslog messages from one TCP connection.
+func (s *Source) serveConn(ctx context.Context, out chan<- model.Log, conn net.Conn) {
+	reader := bufio.NewReaderSize(conn, 4096)
+	for {
+		frame, err := readFrame(reader, s.cfg.MaxMessageSize)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && ctx.Err() == nil {
+				log.Printf("Syslog source %q: closing connection from %s: %v", s.name, conn.RemoteAddr(), err)
+			}
+			return
+		}
+		if !s.handle(ctx, out, frame, conn.RemoteAddr()) {
+			return
+		}
+	}
+}
+
+// readFrame reads one message from a TCP stream. A frame that starts with
+// at most maxOctetCountDigits digits and a space is octet-counted
+// ("LEN SP MSG"); anything else is newline-delimited, so that a line that
+// merely starts with a number is not taken for a count.
+//
+// Parameters:
+//   - reader: The buffered connection reader
+//   - maxSize: The largest accepted frame in bytes
+//
+// Returns:
+//   - string: The message without framing
+//   - error: io.EOF at the end of the stream, or a framing error
+func readFrame(reader *bufio.Reader, maxSize int) (string, error) {
+	if _, err := reader.Peek(1); err != nil {
+		return "", err
+	}
+
+	if digits := octetCountDigits(reader); digits > 0 {
+		lengthText, _ := reader.Peek(digits)
+		length, err := strconv.Atoi(string(lengthText))
+		if err != nil || length <= 0 {
+			return "", fmt.Errorf("invalid octet count %q", lengthText)
+		}
+		if length > maxSize {
+			return "", fmt.Errorf("message of %d bytes exceeds limit of %d", length, maxSize)
+		}
+		reader.Discard(digits + 1)
+		frame := make([]byte, length)
+		if _, err := io.ReadFull(reader, frame); err != nil {
+			return "", fmt.Errorf("read octet-counted message: %w", err)
+		}
+		return string(frame), nil
+	}
+
+	var frame []byte
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		frame = append(frame, chunk...)
+		if len(frame) > maxSize {
+			return "", fmt.Errorf("message exceeds limit of %d bytes", maxSize)
+		}
+		if err == nil {
+			return string(frame), nil
+		}
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		if errors.Is(err, io.EOF) && len(frame) > 0 {
+			return string(frame), nil
+		}
+		return "", err
+	}
+}
+
+// octetCountDigits returns the number of digits of the octet count that
+// starts the next frame, or 0 if the frame does not start with at most
+// maxOctetCountDigits digits followed by a space. It only peeks, so that a
+// newline-delimited frame is left whole.
+func octetCountDigits(reader *bufio.Reader) int {
+	for n := 1; n <= maxOctetCountDigits+1; n++ {
+		peeked, err := reader.Peek(n)
+		if err != nil {
+			return 0
+		}
+		switch c := peeked[n-1]; {
+		case c == ' ' && n > 1:
+			return n - 1
+		case c < '0' || c > '9' || n > maxOctetCountDigits:
+			return 0
+		}
+	}
+	return 0
+}
+
+// handle parses a message and emits it.
+//
+// Returns:
+//   - bool: false if the source is shutting down
+func (s *Source) handle(ctx context.Context, out chan<- model.Log, data string, addr net.Addr) bool {
+	entry, err := parseMessage(data, time.Now())
+	if err != nil {
+		if !errors.Is(err, errEmptyMessage) {
+			log.Printf("Syslog source %q: dropping message from %s: %v", s.name, addr, err)
+		}
+		return true
+	}
+
+	entry.Source = s.name
+	if entry.Host == "" {
+		if host, _, err := net.SplitHostPort(addr.String()); err == nil {
+			entry.Host = host
+		}
+	}
+	return s.Emit(ctx, out, entry)
+}
+
+// reportError marks the source as degraded after a listener error.
+func (s *Source) reportError(err error) {
+	log.Printf("Syslog source %q: %v", s.name, err)
+	s.SetError(source.StateDegraded, err)
+}
+
+// closeListeners closes the listeners and all open TCP connections.
+func (s *Source) closeListeners() {
+	s.closeOnce.Do(func() {
+		if s.udpConn != nil {
+			s.udpConn.Close()
+		}
+		if s.tcpLn != nil {
+			s.tcpLn.Close()
+		}
+	})
+	s.conns.CloseAll()
+}
+
+// Stop closes the listeners and waits for all connections to finish.
+//
+// Returns:
+//   - error: Always nil
+func (s *Source) Stop() error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.closeListeners()
+	s.wg.Wait()
+	s.SetState(source.StateStopped)
+	return nil
+}
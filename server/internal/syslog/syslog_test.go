@@ -0,0 +1,96 @@
+package syslog
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"smart-log-viewer/server/internal/model"
+)
+
+func TestSourceReceivesTCPAndUDP(t *testing.T) {
+	src, err := NewSource("syslog", Config{UDPAddr: "127.0.0.1:0", TCPAddr: "127.0.0.1:0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := make(chan model.Log, 10)
+	if err := src.Start(context.Background(), out); err != nil {
+		t.Fatal(err)
+	}
+	defer src.Stop()
+
+	tcp, err := net.Dial("tcp", src.tcpLn.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tcp.Close()
+	if _, err := tcp.Write([]byte("<11>1 - - app - - - over tcp\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	udp, err := net.Dial("udp", src.udpConn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer udp.Close()
+	if _, err := udp.Write([]byte("<14>over udp")); err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]model.Log)
+	for len(got) < 2 {
+		select {
+		case entry := <-out:
+			got[entry.Message] = entry
+		case <-time.After(2 * time.Second):
+			t.Fatalf("received %d of 2 messages", len(got))
+		}
+	}
+	for _, message := range []string{"over tcp", "over udp"} {
+		entry, ok := got[message]
+		if !ok {
+			t.Errorf("missing message %q", message)
+			continue
+		}
+		if entry.Source != "syslog" || entry.Host != "127.0.0.1" {
+			t.Errorf("%q: source %q and host %q, want syslog and the sender's address", message, entry.Source, entry.Host)
+		}
+	}
+}
+
+func TestStopClosesOpenConnections(t *testing.T) {
+	src, err := NewSource("syslog", Config{TCPAddr: "127.0.0.1:0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := src.Start(context.Background(), make(chan model.Log, 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Idle connections must not keep Stop waiting.
+	for i := 0; i < 5; i++ {
+		conn, err := net.Dial("tcp", src.tcpLn.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		src.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Stop did not return with connections open")
+	}
+}
+
+func TestNewSourceNeedsAddress(t *testing.T) {
+	if _, err := NewSource("syslog", Config{}); err == nil {
+		t.Fatal("NewSource() without addresses succeeded")
+	}
+}
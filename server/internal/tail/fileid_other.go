@@ -0,0 +1,11 @@
+//go:build !unix
+
+package tail
+
+import "os"
+
+// fileKey returns the identity of a file. Platforms without inodes fall
+// back to the path, so offsets do not follow renamed files there.
+func fileKey(path string, info os.FileInfo) string {
+	return path
+}
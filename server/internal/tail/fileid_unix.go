@@ -0,0 +1,18 @@
+//go:build unix
+
+package tail
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// fileKey returns the identity of a file as "device:inode", which survives
+// renames so that a rotated file keeps its saved offset.
+func fileKey(path string, info os.FileInfo) string {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return fmt.Sprintf("%d:%d", stat.Dev, stat.Ino)
+	}
+	return path
+}
@@ -0,0 +1,306 @@
+package tail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"smart-log-viewer/server/internal/source"
+)
+
+// readBufferSize is the size of a single read from a followed file.
+const readBufferSize = 32 * 1024
+
+// follower tails a single path the way `tail -F` does: it waits for the file
+// to exist, reopens it when it is replaced by rotation, and starts over when
+// it is truncated in place (copytruncate).
+type follower struct {
+	path       string
+	store      *OffsetStore
+	poll       time.Duration
+	startAtEnd bool
+	emit       func(line string) bool
+	onError    func(err error)
+
+	file    *os.File
+	key     string
+	readPos int64  // bytes read from the current file
+	offset  int64  // bytes of complete lines emitted from the current file
+	partial []byte // trailing bytes without a newline yet
+	buf     []byte
+	missing bool // whether the missing file has already been reported
+
+	retire     chan struct{}
+	retireOnce sync.Once
+}
+
+// newFollower creates a follower for path.
+//
+// Parameters:
+//   - path: The file to follow
+//   - store: Offset store used to resume and record positions
+//   - poll: Interval between checks for new data and rotation
+//   - startAtEnd: Skip existing content if the file has no saved offset on the first check
+//   - emit: Called for every complete line; returning false stops the follower
+//   - onError: Called when the file cannot be read
+//
+// Returns:
+//   - *follower: A new follower, not yet running
+func newFollower(path string, store *OffsetStore, poll time.Duration, startAtEnd bool, emit func(string) bool, onError func(error)) *follower {
+	return &follower{
+		path:       path,
+		store:      store,
+		poll:       poll,
+		startAtEnd: startAtEnd,
+		emit:       emit,
+		onError:    onError,
+		buf:        make([]byte, readBufferSize),
+		retire:     make(chan struct{}),
+	}
+}
+
+// stop asks a running follower to read whatever is left in its file and exit.
+// It is used when the followed path is no longer wanted, e.g. it was deleted.
+func (f *follower) stop() {
+	f.retireOnce.Do(func() {
+		close(f.retire)
+	})
+}
+
+// run follows the file until ctx is cancelled.
+func (f *follower) run(ctx context.Context) {
+	defer f.close()
+
+	ticker := time.NewTicker(f.poll)
+	defer ticker.Stop()
+
+	for {
+		if !f.step() {
+			return
+		}
+		// startAtEnd only applies to files that exist when following begins;
+		// files that show up later are read from the start.
+		f.startAtEnd = false
+
+		select {
+		case <-ticker.C:
+		case <-f.retire:
+			if f.file != nil && f.readAvailable() {
+				f.flushPartial()
+			}
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// step performs one poll: open the file if needed, read new data and
+// check for truncation or rotation.
+//
+// Returns:
+//   - bool: false if emitting was aborted and the follower must stop
+func (f *follower) step() bool {
+	if f.file == nil && !f.open() {
+		return true
+	}
+	if !f.readAvailable() {
+		return false
+	}
+	return f.checkRotation()
+}
+
+// open opens the followed path and positions it at the saved offset.
+//
+// Returns:
+//   - bool: true if the file is now open
+func (f *follower) open() bool {
+	file, err := os.Open(f.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			if !f.missing {
+				log.Printf("Tail: %s does not exist yet, waiting for it", f.path)
+				f.missing = true
+			}
+		} else {
+			f.onError(err)
+		}
+		return false
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		f.onError(err)
+		return false
+	}
+
+	key := fileKey(f.path, info)
+	var offset int64
+	if saved, ok := f.store.Get(key); ok && saved <= info.Size() {
+		offset = saved
+	} else if f.startAtEnd {
+		offset = info.Size()
+	}
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		f.onError(err)
+		return false
+	}
+
+	f.file = file
+	f.key = key
+	f.readPos = offset
+	f.offset = offset
+	f.partial = f.partial[:0]
+	f.missing = false
+	f.store.Set(key, f.path, offset)
+	log.Printf("Tail: following %s from offset %d", f.path, offset)
+	return true
+}
+
+// readAvailable reads everything currently available in the open file
+// and emits the complete lines.
+//
+// Returns:
+//   - bool: false if emitting was aborted
+func (f *follower) readAvailable() bool {
+	for {
+		n, err := f.file.Read(f.buf)
+		if n > 0 && !f.consume(f.buf[:n]) {
+			return false
+		}
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				f.onError(err)
+			}
+			break
+		}
+		if n == 0 {
+			break
+		}
+	}
+	f.store.Set(f.key, f.path, f.offset)
+	return true
+}
+
+// consume splits newly read data into lines and emits them.
+//
+// Returns:
+//   - bool: false if emitting was aborted
+func (f *follower) consume(data []byte) bool {
+	f.readPos += int64(len(data))
+	f.partial = append(f.partial, data...)
+
+	rest := f.partial
+	for {
+		i := bytes.IndexByte(rest, '\n')
+		if i < 0 {
+			break
+		}
+		line := rest[:i]
+		rest = rest[i+1:]
+		if !f.emit(string(bytes.TrimSuffix(line, []byte{'\r'}))) {
+			return false
+		}
+		f.offset += int64(i + 1)
+	}
+
+	if len(rest) >= source.MaxLineSize {
+		if !f.emit(string(rest)) {
+			return false
+		}
+		f.offset += int64(len(rest))
+		rest = rest[:0]
+	}
+
+	f.partial = append(f.partial[:0], rest...)
+	return true
+}
+
+// flushPartial emits a trailing line that never got its newline,
+// used when the file is about to be abandoned.
+//
+// Returns:
+//   - bool: false if emitting was aborted
+func (f *follower) flushPartial() bool {
+	if len(f.partial) == 0 {
+		return true
+	}
+	line := string(bytes.TrimSuffix(f.partial, []byte{'\r'}))
+	if !f.emit(line) {
+		return false
+	}
+	f.offset += int64(len(f.partial))
+	f.partial = f.partial[:0]
+	return true
+}
+
+// checkRotation detects copytruncate (the open file shrank) and rename
+// rotation (the path now points at a different file).
+//
+// Returns:
+//   - bool: false if emitting was aborted
+func (f *follower) checkRotation() bool {
+	current, err := f.file.Stat()
+	if err != nil {
+		f.onError(err)
+		return true
+	}
+
+	if current.Size() < f.readPos {
+		log.Printf("Tail: %s was truncated, restarting from the beginning", f.path)
+		if _, err := f.file.Seek(0, io.SeekStart); err != nil {
+			f.onError(err)
+			f.close()
+			return true
+		}
+		f.readPos = 0
+		f.offset = 0
+		f.partial = f.partial[:0]
+		f.store.Set(f.key, f.path, 0)
+		return true
+	}
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		// The file was renamed or removed and nothing replaced it yet.
+		// Keep reading the old file: writers often hold it open until
+		// they are told to reopen their log.
+		return true
+	}
+	if os.SameFile(info, current) {
+		return true
+	}
+
+	// The path now points at a new file. Drain the old one, then switch.
+	log.Printf("Tail: %s was rotated, switching to the new file", f.path)
+	if !f.readAvailable() || !f.flushPartial() {
+		return false
+	}
+	f.store.Delete(f.key)
+	f.close()
+	f.startAtEnd = false
+
+	if f.open() {
+		return f.readAvailable()
+	}
+	return true
+}
+
+// close closes the currently open file, if any.
+func (f *follower) close() {
+	if f.file == nil {
+		return
+	}
+	if err := f.file.Close(); err != nil {
+		log.Printf("Tail: error closing %s: %v", f.path, err)
+	}
+	f.file = nil
+}
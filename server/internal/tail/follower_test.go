@@ -0,0 +1,260 @@
+package tail
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"smart-log-viewer/server/internal/source"
+)
+
+// appendTo appends text to the file at path, creating it if needed.
+func appendTo(text string) func(t *testing.T, path string) {
+	return func(t *testing.T, path string) {
+		t.Helper()
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		if _, err := file.WriteString(text); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// truncateTo truncates the file in place and writes text, as copytruncate does.
+func truncateTo(text string) func(t *testing.T, path string) {
+	return func(t *testing.T, path string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// rotate appends tail to the file, renames it away and creates a new file
+// holding text in its place.
+func rotate(tail, text string) func(t *testing.T, path string) {
+	return func(t *testing.T, path string) {
+		t.Helper()
+		appendTo(tail)(t, path)
+		if err := os.Rename(path, path+".1"); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// remove deletes the file.
+func remove(t *testing.T, path string) {
+	t.Helper()
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFollowerStep(t *testing.T) {
+	type step struct {
+		do   func(t *testing.T, path string)
+		want []string
+	}
+
+	tests := []struct {
+		name       string
+		initial    *string
+		startAtEnd bool
+		steps      []step
+	}{
+		{
+			name:    "reads existing content and appended lines",
+			initial: ptr("one\r\ntwo\n"),
+			steps: []step{
+				{want: []string{"one", "two"}},
+				{do: appendTo("three\n"), want: []string{"three"}},
+				{want: nil},
+			},
+		},
+		{
+			name:       "start at end skips existing content",
+			initial:    ptr("old\n"),
+			startAtEnd: true,
+			steps: []step{
+				{want: nil},
+				{do: appendTo("new\n"), want: []string{"new"}},
+			},
+		},
+		{
+			name: "waits for a missing file",
+			steps: []step{
+				{want: nil},
+				{do: appendTo("created\n"), want: []string{"created"}},
+			},
+		},
+		{
+			name:    "holds a partial line until its newline",
+			initial: ptr("par"),
+			steps: []step{
+				{want: nil},
+				{do: appendTo("tial\nnext"), want: []string{"partial"}},
+				{do: appendTo("\n"), want: []string{"next"}},
+			},
+		},
+		{
+			name:    "truncation starts over",
+			initial: ptr("first\nsecond\n"),
+			steps: []step{
+				{want: []string{"first", "second"}},
+				{do: truncateTo("x\n"), want: nil},
+				{want: []string{"x"}},
+			},
+		},
+		{
+			name:    "rotation drains the old file before switching",
+			initial: ptr("a\n"),
+			steps: []step{
+				{want: []string{"a"}},
+				{do: rotate("b\nunterminated", "c\n"), want: []string{"b", "unterminated", "c"}},
+				{do: appendTo("d\n"), want: []string{"d"}},
+			},
+		},
+		{
+			name:    "removed file is still read",
+			initial: ptr("a\n"),
+			steps: []step{
+				{want: []string{"a"}},
+				{do: func(t *testing.T, path string) {
+					appendTo("b\n")(t, path)
+					remove(t, path)
+				}, want: []string{"b"}},
+			},
+		},
+		{
+			name:    "overlong line is emitted in pieces",
+			initial: ptr(strings.Repeat("x", source.MaxLineSize+1)),
+			steps: []step{
+				{want: []string{strings.Repeat("x", source.MaxLineSize)}},
+				{do: appendTo("\n"), want: []string{"x"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			if tt.initial != nil {
+				truncateTo(*tt.initial)(t, path)
+			}
+			store, _ := LoadOffsetStore("")
+
+			var got []string
+			f := newFollower(path, store, time.Millisecond, tt.startAtEnd, func(line string) bool {
+				got = append(got, line)
+				return true
+			}, func(err error) {
+				t.Errorf("follower error: %v", err)
+			})
+			defer f.close()
+
+			for i, s := range tt.steps {
+				if s.do != nil {
+					s.do(t, path)
+				}
+				got = nil
+				if !f.step() {
+					t.Fatalf("step %d: step() = false", i)
+				}
+				if !reflect.DeepEqual(got, s.want) {
+					t.Fatalf("step %d: lines = %q, want %q", i, truncate(got), truncate(s.want))
+				}
+			}
+		})
+	}
+}
+
+func TestFollowerResumesFromSavedOffset(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		saved      int64
+		startAtEnd bool
+		want       []string
+	}{
+		{name: "saved offset", content: "a\nb\nc\n", saved: 2, want: []string{"b", "c"}},
+		{name: "saved offset wins over start at end", content: "a\nb\n", saved: 2, startAtEnd: true, want: []string{"b"}},
+		{name: "saved offset past the end", content: "a\n", saved: 100, want: []string{"a"}},
+		{name: "saved offset past the end with start at end", content: "a\n", saved: 100, startAtEnd: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			truncateTo(tt.content)(t, path)
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			store, _ := LoadOffsetStore("")
+			key := fileKey(path, info)
+			store.Set(key, path, tt.saved)
+
+			var got []string
+			f := newFollower(path, store, time.Millisecond, tt.startAtEnd, func(line string) bool {
+				got = append(got, line)
+				return true
+			}, func(err error) {
+				t.Errorf("follower error: %v", err)
+			})
+			defer f.close()
+
+			if !f.step() {
+				t.Fatal("step() = false")
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lines = %q, want %q", got, tt.want)
+			}
+			if offset, _ := store.Get(key); offset != info.Size() {
+				t.Errorf("saved offset = %d, want %d", offset, info.Size())
+			}
+		})
+	}
+}
+
+func TestFollowerStopsWhenEmitFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	truncateTo("a\nb\n")(t, path)
+	store, _ := LoadOffsetStore("")
+
+	f := newFollower(path, store, time.Millisecond, false, func(string) bool {
+		return false
+	}, func(error) {})
+	defer f.close()
+
+	if f.step() {
+		t.Fatal("step() = true after emit failed")
+	}
+	if f.offset != 0 {
+		t.Errorf("offset = %d, want 0 for a line that was not emitted", f.offset)
+	}
+}
+
+func ptr(s string) *string {
+	return &s
+}
+
+// truncate shortens lines for error messages.
+func truncate(lines []string) []string {
+	short := make([]string, len(lines))
+	for i, line := range lines {
+		if len(line) > 20 {
+			line = line[:20] + "..."
+		}
+		short[i] = line
+	}
+	return short
+}
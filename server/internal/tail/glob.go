@@ -0,0 +1,305 @@
+package tail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"smart-log-viewer/server/internal/config"
+	"smart-log-viewer/server/internal/model"
+	"smart-log-viewer/server/internal/source"
+)
+
+// defaultRescanInterval is how often glob patterns are re-evaluated even
+// without file system notifications, so that polling alone still works.
+const defaultRescanInterval = 5 * time.Second
+
+// GlobConfig holds the options of a directory glob source.
+type GlobConfig struct {
+	// Patterns lists shell glob patterns such as "/var/log/app/*.log".
+	Patterns []string `json:"patterns"`
+
+	// StateFile is where read offsets are persisted; empty keeps them in memory.
+	StateFile string `json:"stateFile"`
+
+	// FromBeginning reads files that match at startup from the start.
+	// Files discovered later are always read from the start.
+	FromBeginning bool `json:"fromBeginning"`
+
+	// PollInterval is the delay between checks for new data (default 250ms).
+	PollInterval config.Duration `json:"pollInterval"`
+
+	// RescanInterval is the delay between pattern rescans (default 5s).
+	// Rescans also happen immediately on file system notifications.
+	RescanInterval config.Duration `json:"rescanInterval"`
+}
+
+// GlobSource is a source.Source that follows every file matching a set of
+// glob patterns. New files are picked up as they appear and deleted files
+// are retired, using inotify-style notifications with a polling fallback.
+// Every entry is tagged with the path of the file it was read from.
+type GlobSource struct {
+	source.Status
+
+	name    string
+	cfg     GlobConfig
+	store   *OffsetStore
+	watcher *fsnotify.Watcher
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+
+	// The fields below are owned by the goroutine running watch.
+	files   map[string]*globFile
+	watched map[string]bool
+}
+
+// globFile is one file followed by a GlobSource.
+type globFile struct {
+	follower *follower
+	done     chan struct{}
+}
+
+// NewGlobSource creates a directory glob source.
+//
+// Parameters:
+//   - name: The unique name of the source
+//   - cfg: The source options
+//
+// Returns:
+//   - *GlobSource: A new glob source instance
+//   - error: nil on success, or an error if no valid patterns are configured
+func NewGlobSource(name string, cfg GlobConfig) (*GlobSource, error) {
+	if len(cfg.Patterns) == 0 {
+		return nil, errors.New("glob source needs at least one pattern")
+	}
+	for _, pattern := range cfg.Patterns {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	if cfg.PollInterval.Duration <= 0 {
+		cfg.PollInterval.Duration = defaultPollInterval
+	}
+	if cfg.RescanInterval.Duration <= 0 {
+		cfg.RescanInterval.Duration = defaultRescanInterval
+	}
+	return &GlobSource{
+		name:    name,
+		cfg:     cfg,
+		files:   make(map[string]*globFile),
+		watched: make(map[string]bool),
+	}, nil
+}
+
+// Name returns the unique name of the source.
+func (s *GlobSource) Name() string {
+	return s.name
+}
+
+// Start loads the saved offsets, follows the files that already match and
+// starts watching for new ones.
+//
+// Parameters:
+//   - ctx: Context that stops the source when cancelled
+//   - out: Channel log entries are sent to
+//
+// Returns:
+//   - error: nil on success, or an error if the offset state cannot be loaded
+func (s *GlobSource) Start(ctx context.Context, out chan<- model.Log) error {
+	store, err := LoadOffsetStore(s.cfg.StateFile)
+	if err != nil {
+		s.SetError(source.StateFailed, err)
+		return err
+	}
+	s.store = store
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Printf("Glob source %q: file notifications unavailable, polling every %s: %v",
+			s.name, s.cfg.RescanInterval.Duration, err)
+	} else {
+		s.watcher = watcher
+	}
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.SetState(source.StateRunning)
+	s.scan(ctx, out, true)
+
+	s.wg.Add(2)
+	go func() {
+		defer s.wg.Done()
+		s.watch(ctx, out)
+	}()
+	go func() {
+		defer s.wg.Done()
+		persistOffsets(ctx, store, s.reportError)
+	}()
+	return nil
+}
+
+// watch rescans the patterns on file system events and on a timer.
+func (s *GlobSource) watch(ctx context.Context, out chan<- model.Log) {
+	ticker := time.NewTicker(s.cfg.RescanInterval.Duration)
+	defer ticker.Stop()
+
+	// A nil channel blocks forever, which disables the cases below
+	// when notifications are unavailable.
+	var events <-chan fsnotify.Event
+	var errs <-chan error
+	if s.watcher != nil {
+		events = s.watcher.Events
+		errs = s.watcher.Errors
+	}
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			if event.Has(fsnotify.Create) || event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+				if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+					delete(s.watched, event.Name)
+				}
+				s.scan(ctx, out, false)
+			}
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			s.reportError(err)
+		case <-ticker.C:
+			s.scan(ctx, out, false)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// scan evaluates the patterns, follows newly matching files and retires
+// files that no longer exist.
+//
+// Parameters:
+//   - ctx: Context of the source
+//   - out: Channel log entries are sent to
+//   - initial: Whether this is the scan at startup
+func (s *GlobSource) scan(ctx context.Context, out chan<- model.Log, initial bool) {
+	matches := make(map[string]bool)
+	for _, pattern := range s.cfg.Patterns {
+		s.watchDirs(pattern)
+
+		paths, err := filepath.Glob(pattern)
+		if err != nil {
+			s.reportError(err)
+			continue
+		}
+		for _, path := range paths {
+			if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+				matches[path] = true
+			}
+		}
+	}
+
+	for path := range matches {
+		if _, ok := s.files[path]; !ok {
+			s.follow(ctx, out, path, initial && !s.cfg.FromBeginning)
+		}
+	}
+	for path, file := range s.files {
+		if !matches[path] {
+			s.retire(path, file)
+		}
+	}
+}
+
+// follow starts a follower for a newly discovered file.
+func (s *GlobSource) follow(ctx context.Context, out chan<- model.Log, path string, startAtEnd bool) {
+	f := newFollower(path, s.store, s.cfg.PollInterval.Duration, startAtEnd,
+		lineEmitter(ctx, &s.Status, out, path), s.reportError)
+	file := &globFile{
+		follower: f,
+		done:     make(chan struct{}),
+	}
+	s.files[path] = file
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer close(file.done)
+		f.run(ctx)
+	}()
+	log.Printf("Glob source %q: discovered %s, now following %d files", s.name, path, len(s.files))
+}
+
+// retire stops following a file that no longer exists and forgets its offset.
+func (s *GlobSource) retire(path string, file *globFile) {
+	file.follower.stop()
+	<-file.done
+	if file.follower.key != "" {
+		s.store.Delete(file.follower.key)
+	}
+	delete(s.files, path)
+	log.Printf("Glob source %q: %s is gone, now following %d files", s.name, path, len(s.files))
+}
+
+// watchDirs registers the directories a pattern can match in with the
+// file system watcher. Directories that do not exist yet are retried on
+// the next scan.
+func (s *GlobSource) watchDirs(pattern string) {
+	if s.watcher == nil {
+		return
+	}
+
+	dirs := []string{filepath.Dir(pattern)}
+	if strings.ContainsAny(dirs[0], `*?[\`) {
+		dirs, _ = filepath.Glob(dirs[0])
+	}
+
+	for _, dir := range dirs {
+		if s.watched[dir] {
+			continue
+		}
+		if err := s.watcher.Add(dir); err != nil {
+			continue
+		}
+		s.watched[dir] = true
+	}
+}
+
+// reportError marks the source as degraded after an error.
+func (s *GlobSource) reportError(err error) {
+	log.Printf("Glob source %q: %v", s.name, err)
+	s.SetError(source.StateDegraded, err)
+}
+
+// Stop stops watching, stops all followers and saves the final offsets.
+//
+// Returns:
+//   - error: nil on success, or an error if the offsets cannot be saved
+func (s *GlobSource) Stop() error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+	if s.watcher != nil {
+		if err := s.watcher.Close(); err != nil {
+			log.Printf("Glob source %q: error closing watcher: %v", s.name, err)
+		}
+	}
+	s.SetState(source.StateStopped)
+
+	if s.store != nil {
+		return s.store.Save()
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package tail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"smart-log-viewer/server/internal/config"
+	"smart-log-viewer/server/internal/model"
+)
+
+func TestNewGlobSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		wantErr  string
+	}{
+		{name: "valid", patterns: []string{"/var/log/*.log", "/srv/*/app.log"}},
+		{name: "no patterns", wantErr: "at least one pattern"},
+		{name: "malformed pattern", patterns: []string{"/var/log/[.log"}, wantErr: "invalid pattern"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, err := NewGlobSource("glob", GlobConfig{Patterns: tt.patterns})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("NewGlobSource() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if src.cfg.PollInterval.Duration != defaultPollInterval || src.cfg.RescanInterval.Duration != defaultRescanInterval {
+				t.Errorf("intervals = %v, %v, want the defaults", src.cfg.PollInterval, src.cfg.RescanInterval)
+			}
+		})
+	}
+}
+
+func TestGlobSourceFollowsMatchingFiles(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.log")
+	if err := os.WriteFile(existing, []byte("skipped\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := NewGlobSource("glob", GlobConfig{
+		Patterns:       []string{filepath.Join(dir, "*.log")},
+		PollInterval:   config.Duration{Duration: 10 * time.Millisecond},
+		RescanInterval: config.Duration{Duration: 20 * time.Millisecond},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := make(chan model.Log, 10)
+	if err := src.Start(context.Background(), out); err != nil {
+		t.Fatal(err)
+	}
+	defer src.Stop()
+
+	expect := func(path, message string) {
+		t.Helper()
+		select {
+		case entry := <-out:
+			if entry.Source != path || entry.Message != message {
+				t.Fatalf("entry = %q from %q, want %q from %q", entry.Message, entry.Source, message, path)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("no entry %q from %q", message, path)
+		}
+	}
+
+	// Files that match at startup are followed from their end.
+	existingKey := key(t, existing)
+	waitFor(t, "existing file to be opened", func() bool {
+		_, ok := src.store.Get(existingKey)
+		return ok
+	})
+	appendTo("appended\n")(t, existing)
+	expect(existing, "appended")
+
+	// Files that appear later are read from the start.
+	created := filepath.Join(dir, "created.log")
+	appendTo("first\n")(t, created)
+	expect(created, "first")
+
+	// Other files are ignored.
+	appendTo("ignored\n")(t, filepath.Join(dir, "other.txt"))
+
+	// Deleted files are retired after what is left has been read,
+	// which forgets their offsets.
+	createdKey := key(t, created)
+	appendTo("last\n")(t, created)
+	remove(t, created)
+	expect(created, "last")
+	waitFor(t, "deleted file to be retired", func() bool {
+		_, ok := src.store.Get(createdKey)
+		return !ok
+	})
+	if _, ok := src.store.Get(existingKey); !ok {
+		t.Error("offset of a followed file was forgotten")
+	}
+}
+
+// key returns the identity of the file at path.
+func key(t *testing.T, path string) string {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fileKey(path, info)
+}
+
+// waitFor polls done until it reports true.
+func waitFor(t *testing.T, what string, done func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !done() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
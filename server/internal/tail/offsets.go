@@ -0,0 +1,149 @@
+package tail
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// offsetEntry is the persisted read position of one file.
+type offsetEntry struct {
+	// Path is the path the file was last seen at, kept for debugging.
+	Path string `json:"path"`
+
+	// Offset is the number of bytes of complete lines already emitted.
+	Offset int64 `json:"offset"`
+}
+
+// OffsetStore remembers how far each file has been read, keyed by file
+// identity (device and inode on Unix) so that renamed files keep their
+// position. It can be persisted to a JSON file so a restarted server
+// resumes where it left off.
+type OffsetStore struct {
+	mu      sync.Mutex
+	path    string
+	entries map[string]offsetEntry
+	dirty   bool
+}
+
+// LoadOffsetStore opens the offset store backed by the given file.
+// A missing file yields an empty store; an empty path yields a store
+// that is kept in memory only.
+//
+// Parameters:
+//   - path: Path to the JSON state file, or "" for an in-memory store
+//
+// Returns:
+//   - *OffsetStore: The loaded store
+//   - error: nil on success, or an error if the file exists but cannot be read
+func LoadOffsetStore(path string) (*OffsetStore, error) {
+	store := &OffsetStore{
+		path:    path,
+		entries: make(map[string]offsetEntry),
+	}
+	if path == "" {
+		return store, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read offsets: %w", err)
+	}
+	if err := json.Unmarshal(data, &store.entries); err != nil {
+		return nil, fmt.Errorf("parse offsets %s: %w", path, err)
+	}
+	return store, nil
+}
+
+// Get returns the saved offset for a file identity.
+//
+// Parameters:
+//   - key: The file identity
+//
+// Returns:
+//   - int64: The saved offset
+//   - bool: true if an offset was saved for key
+func (s *OffsetStore) Get(key string) (int64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	return entry.Offset, ok
+}
+
+// Set records the offset of a file identity.
+//
+// Parameters:
+//   - key: The file identity
+//   - path: The current path of the file
+//   - offset: The number of bytes already emitted
+func (s *OffsetStore) Set(key, path string, offset int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[key]; ok && entry.Path == path && entry.Offset == offset {
+		return
+	}
+	s.entries[key] = offsetEntry{Path: path, Offset: offset}
+	s.dirty = true
+}
+
+// Delete forgets the offset of a file identity, typically once a rotated
+// file has been fully read and will not be looked at again.
+//
+// Parameters:
+//   - key: The file identity
+func (s *OffsetStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entries[key]; ok {
+		delete(s.entries, key)
+		s.dirty = true
+	}
+}
+
+// Save writes the store to its state file if anything changed.
+// The file is replaced atomically so a crash never leaves it half-written.
+//
+// Returns:
+//   - error: nil on success, or an error if the state file cannot be written
+func (s *OffsetStore) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.path == "" || !s.dirty {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s.entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode offsets: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("write offsets: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write offsets: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write offsets: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("write offsets: %w", err)
+	}
+
+	s.dirty = false
+	return nil
+}
@@ -0,0 +1,107 @@
+package tail
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOffsetStoreSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "offsets.json")
+
+	store, err := LoadOffsetStore(path)
+	if err != nil {
+		t.Fatalf("LoadOffsetStore() of a missing file error = %v", err)
+	}
+	store.Set("1:2", "/var/log/a.log", 10)
+	store.Set("1:3", "/var/log/b.log", 20)
+	store.Delete("1:3")
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadOffsetStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offset, ok := loaded.Get("1:2"); !ok || offset != 10 {
+		t.Errorf("Get(1:2) = %d, %v, want 10, true", offset, ok)
+	}
+	if _, ok := loaded.Get("1:3"); ok {
+		t.Error("deleted offset was saved")
+	}
+}
+
+func TestLoadOffsetStore(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]int64
+		wantErr string
+	}{
+		{name: "empty object", content: `{}`, want: map[string]int64{}},
+		{
+			name:    "entries",
+			content: `{"1:2":{"path":"/a","offset":5},"1:3":{"path":"/b","offset":0}}`,
+			want:    map[string]int64{"1:2": 5, "1:3": 0},
+		},
+		{name: "malformed", content: `{"1:2":`, wantErr: "parse offsets"},
+		{name: "wrong type", content: `{"1:2":{"offset":"five"}}`, wantErr: "parse offsets"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "offsets.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			store, err := LoadOffsetStore(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadOffsetStore() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(store.entries) != len(tt.want) {
+				t.Errorf("loaded %d entries, want %d", len(store.entries), len(tt.want))
+			}
+			for key, want := range tt.want {
+				if offset, ok := store.Get(key); !ok || offset != want {
+					t.Errorf("Get(%q) = %d, %v, want %d, true", key, offset, ok, want)
+				}
+			}
+		})
+	}
+}
+
+func TestOffsetStoreSaveOnlyWhenChanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "offsets.json")
+	store, _ := LoadOffsetStore(path)
+
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("Save() of an unchanged store wrote the file: %v", err)
+	}
+
+	store.Set("1:2", "/a", 1)
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	store.Set("1:2", "/a", 1)
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Save() after setting the same offset wrote the file")
+	}
+}
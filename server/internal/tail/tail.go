@@ -0,0 +1,173 @@
+// Package tail provides log sources that follow files on disk like `tail -F`.
+// Followed files survive logrotate renames, copytruncate and files that do
+// not exist yet, and read positions are remembered per inode so that a
+// restarted server resumes where it left off.
+package tail
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"smart-log-viewer/server/internal/config"
+	"smart-log-viewer/server/internal/level"
+	"smart-log-viewer/server/internal/model"
+	"smart-log-viewer/server/internal/source"
+)
+
+const (
+	// defaultPollInterval is how often files are checked for new data.
+	defaultPollInterval = 250 * time.Millisecond
+
+	// offsetSaveInterval is how often changed offsets are written to disk.
+	offsetSaveInterval = 5 * time.Second
+)
+
+// Config holds the options of a file tail source.
+type Config struct {
+	// Paths lists the files to follow.
+	Paths []string `json:"paths"`
+
+	// StateFile is where read offsets are persisted; empty keeps them in memory.
+	StateFile string `json:"stateFile"`
+
+	// FromBeginning reads files that exist at startup from the start instead
+	// of only following new lines. Saved offsets always take precedence.
+	FromBeginning bool `json:"fromBeginning"`
+
+	// PollInterval is the delay between checks for new data (default 250ms).
+	PollInterval config.Duration `json:"pollInterval"`
+}
+
+// Source is a source.Source that follows one or more files.
+type Source struct {
+	source.Status
+
+	name   string
+	cfg    Config
+	store  *OffsetStore
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewSource creates a file tail source.
+//
+// Parameters:
+//   - name: The unique name of the source
+//   - cfg: The source options
+//
+// Returns:
+//   - *Source: A new tail source instance
+//   - error: nil on success, or an error if no paths are configured
+func NewSource(name string, cfg Config) (*Source, error) {
+	if len(cfg.Paths) == 0 {
+		return nil, errors.New("tail source needs at least one path")
+	}
+	if cfg.PollInterval.Duration <= 0 {
+		cfg.PollInterval.Duration = defaultPollInterval
+	}
+	return &Source{
+		name: name,
+		cfg:  cfg,
+	}, nil
+}
+
+// Name returns the unique name of the source.
+func (s *Source) Name() string {
+	return s.name
+}
+
+// Start loads the saved offsets and starts one follower per path.
+//
+// Parameters:
+//   - ctx: Context that stops the followers when cancelled
+//   - out: Channel log entries are sent to
+//
+// Returns:
+//   - error: nil on success, or an error if the offset state cannot be loaded
+func (s *Source) Start(ctx context.Context, out chan<- model.Log) error {
+	store, err := LoadOffsetStore(s.cfg.StateFile)
+	if err != nil {
+		s.SetError(source.StateFailed, err)
+		return err
+	}
+	s.store = store
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.SetState(source.StateRunning)
+
+	for _, path := range s.cfg.Paths {
+		f := newFollower(path, store, s.cfg.PollInterval.Duration, !s.cfg.FromBeginning,
+			lineEmitter(ctx, &s.Status, out, path), s.reportError)
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			f.run(ctx)
+		}()
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		persistOffsets(ctx, store, s.reportError)
+	}()
+	return nil
+}
+
+// lineEmitter returns the callback followers use to emit a line,
+// tagging every entry with the path it was read from.
+func lineEmitter(ctx context.Context, status *source.Status, out chan<- model.Log, path string) func(string) bool {
+	return func(line string) bool {
+		return status.Emit(ctx, out, model.Log{
+			Level:      level.Info,
+			Message:    line,
+			ReceivedAt: time.Now(),
+			Source:     path,
+			Host:       source.LocalHostname(),
+		})
+	}
+}
+
+// reportError marks the source as degraded after a read error.
+func (s *Source) reportError(err error) {
+	log.Printf("Tail source %q: %v", s.name, err)
+	s.SetError(source.StateDegraded, err)
+}
+
+// persistOffsets periodically writes changed offsets to the state file
+// until ctx is cancelled.
+func persistOffsets(ctx context.Context, store *OffsetStore, onError func(error)) {
+	ticker := time.NewTicker(offsetSaveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := store.Save(); err != nil {
+				onError(err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Stop stops all followers and saves the final offsets.
+//
+// Returns:
+//   - error: nil on success, or an error if the offsets cannot be saved
+func (s *Source) Stop() error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+	s.SetState(source.StateStopped)
+
+	if s.store != nil {
+		return s.store.Save()
+	}
+	return nil
+}
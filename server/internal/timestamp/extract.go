@@ -0,0 +1,64 @@
+package timestamp
+
+import (
+	"fmt"
+	"time"
+
+	"smart-log-viewer/server/internal/config"
+	"smart-log-viewer/server/internal/model"
+)
+
+// Extractor is a processing stage that gives entries without an event
+// time the timestamp found at the start of their message or in a field.
+// Entries whose source or parsers already set a timestamp pass unchanged.
+type Extractor struct {
+	field string
+	loc   *time.Location
+}
+
+// NewExtractor creates a timestamp extraction stage.
+//
+// Parameters:
+//   - cfg: The timestamp options of the source
+//
+// Returns:
+//   - *Extractor: A new stage
+//   - error: nil on success, or an error for an unknown timezone
+func NewExtractor(cfg config.TimestampConfig) (*Extractor, error) {
+	loc := time.Local
+	if cfg.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(cfg.Timezone); err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", cfg.Timezone, err)
+		}
+	}
+	return &Extractor{field: cfg.Field, loc: loc}, nil
+}
+
+// Process fills in the timestamp of an entry that has none.
+//
+// Parameters:
+//   - entry: The entry to process
+//   - next: Receives the entry
+func (e *Extractor) Process(entry model.Log, next func(model.Log)) {
+	if entry.Timestamp.IsZero() {
+		if t, ok := e.find(entry); ok {
+			entry.Timestamp = t
+		}
+	}
+	next(entry)
+}
+
+// find returns the event time held by the configured field or, without
+// one, the timestamp the message starts with.
+func (e *Extractor) find(entry model.Log) (time.Time, bool) {
+	if e.field == "" {
+		return Leading(entry.Message, e.loc, time.Now())
+	}
+
+	value := entry.Fields[e.field]
+	if text, ok := value.(string); ok {
+		return Find(text, e.loc, time.Now())
+	}
+	return Value(value)
+}
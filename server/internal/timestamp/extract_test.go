@@ -0,0 +1,149 @@
+package timestamp
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"smart-log-viewer/server/internal/config"
+	"smart-log-viewer/server/internal/model"
+)
+
+func TestNewExtractor(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.TimestampConfig
+		wantErr string
+	}{
+		{name: "local time", cfg: config.TimestampConfig{}},
+		{name: "timezone and field", cfg: config.TimestampConfig{Field: "ts", Timezone: "Europe/Berlin"}},
+		{name: "unknown timezone", cfg: config.TimestampConfig{Timezone: "Mars/Olympus"}, wantErr: `invalid timezone "Mars/Olympus"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewExtractor(tt.cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("NewExtractor() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("NewExtractor() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExtractor(t *testing.T) {
+	stamp := time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC)
+	set := time.Date(2020, time.May, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		cfg   config.TimestampConfig
+		entry model.Log
+		want  time.Time
+	}{
+		{
+			name:  "leading timestamp in the timezone",
+			cfg:   config.TimestampConfig{Timezone: "Europe/Berlin"},
+			entry: model.Log{Message: "2024-01-02 04:04:05 INFO up"},
+			want:  stamp,
+		},
+		{
+			name:  "timestamp already set",
+			entry: model.Log{Message: "2024-01-02T03:04:05Z up", Timestamp: set},
+			want:  set,
+		},
+		{
+			name:  "timestamp later in the message",
+			entry: model.Log{Message: "up since 2024-01-02T03:04:05Z"},
+		},
+		{
+			name:  "string field",
+			cfg:   config.TimestampConfig{Field: "date"},
+			entry: model.Log{Fields: map[string]interface{}{"date": "sent Tue, 02 Jan 2024 03:04:05 GMT"}},
+			want:  stamp,
+		},
+		{
+			name:  "field is used instead of the message",
+			cfg:   config.TimestampConfig{Field: "date"},
+			entry: model.Log{Message: "2020-05-01T00:00:00Z up"},
+		},
+		{
+			name:  "json number field",
+			cfg:   config.TimestampConfig{Field: "ts"},
+			entry: model.Log{Fields: map[string]interface{}{"ts": json.Number("1704164645.5")}},
+			want:  time.UnixMilli(1704164645500),
+		},
+		{
+			name:  "json number field in nanoseconds",
+			cfg:   config.TimestampConfig{Field: "ts"},
+			entry: model.Log{Fields: map[string]interface{}{"ts": json.Number("1704164645123456789")}},
+			want:  time.Unix(0, 1704164645123456789),
+		},
+		{
+			name:  "float field",
+			cfg:   config.TimestampConfig{Field: "ts"},
+			entry: model.Log{Fields: map[string]interface{}{"ts": 1704164645.0}},
+			want:  time.Unix(1704164645, 0),
+		},
+		{
+			name:  "int field",
+			cfg:   config.TimestampConfig{Field: "ts"},
+			entry: model.Log{Fields: map[string]interface{}{"ts": 1704164645}},
+			want:  time.Unix(1704164645, 0),
+		},
+		{
+			name:  "int64 field in milliseconds",
+			cfg:   config.TimestampConfig{Field: "ts"},
+			entry: model.Log{Fields: map[string]interface{}{"ts": int64(1704164645123)}},
+			want:  time.UnixMilli(1704164645123),
+		},
+		{
+			name:  "int64 field in nanoseconds",
+			cfg:   config.TimestampConfig{Field: "ts"},
+			entry: model.Log{Fields: map[string]interface{}{"ts": int64(1704164645123456789)}},
+			want:  time.Unix(0, 1704164645123456789),
+		},
+		{
+			name:  "malformed json number",
+			cfg:   config.TimestampConfig{Field: "ts"},
+			entry: model.Log{Fields: map[string]interface{}{"ts": json.Number("soon")}},
+		},
+		{
+			name:  "negative epoch",
+			cfg:   config.TimestampConfig{Field: "ts"},
+			entry: model.Log{Fields: map[string]interface{}{"ts": -1.0}},
+		},
+		{
+			name:  "field of another type",
+			cfg:   config.TimestampConfig{Field: "ts"},
+			entry: model.Log{Fields: map[string]interface{}{"ts": true}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := NewExtractor(tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []model.Log
+			e.Process(tt.entry, func(entry model.Log) { got = append(got, entry) })
+			if len(got) != 1 {
+				t.Fatalf("got %d entries, want 1", len(got))
+			}
+			if !got[0].Timestamp.Equal(tt.want) {
+				t.Errorf("Timestamp = %v, want %v", got[0].Timestamp, tt.want)
+			}
+			if got[0].Message != tt.entry.Message {
+				t.Errorf("Message = %q, want it unchanged", got[0].Message)
+			}
+		})
+	}
+}
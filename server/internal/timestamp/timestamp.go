@@ -0,0 +1,281 @@
+// Package timestamp finds and parses the timestamps embedded in log lines.
+// It recognises ISO 8601 / RFC 3339 timestamps (with a "T" or a space,
+// optional fractional seconds and optional zone, which also covers Java's
+// "yyyy-MM-dd HH:mm:ss,SSS"), RFC 1123 timestamps, the Common Log Format
+// timestamp of web servers, the BSD syslog timestamp and, at the start of
+// a line, Unix epoch numbers.
+package timestamp
+
+import (
+	"encoding/json"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pattern pairs a regular expression with the function parsing its matches.
+type pattern struct {
+	re    *regexp.Regexp
+	parse func(match string, loc *time.Location, now time.Time) (time.Time, bool)
+}
+
+// patterns lists the recognised formats. When several match, the one that
+// starts earliest in the line wins.
+var patterns = []pattern{
+	{
+		// 2024-01-02T03:04:05.123Z, 2024-01-02 03:04:05,123 +0100, ...
+		re:    regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d{1,9})?(?: ?(?:Z|[+-]\d{2}:?\d{2}))?`),
+		parse: parseISO,
+	},
+	{
+		// Mon, 02 Jan 2006 15:04:05 MST or -0700
+		re:    regexp.MustCompile(`\b(?:Mon|Tue|Wed|Thu|Fri|Sat|Sun), \d{2} [A-Z][a-z]{2} \d{4} \d{2}:\d{2}:\d{2} (?:[+-]\d{4}|[A-Z]{1,5})\b`),
+		parse: parseRFC1123,
+	},
+	{
+		// 02/Jan/2006:15:04:05 -0700
+		re:    regexp.MustCompile(`\d{2}/[A-Z][a-z]{2}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}`),
+		parse: parseCommonLog,
+	},
+	{
+		// Jan  2 15:04:05
+		re:    regexp.MustCompile(`\b[A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2}\b`),
+		parse: parseBSD,
+	},
+	{
+		// 1704164645, 1704164645.123, 1704164645123, ... Only at the start of
+		// a line, since elsewhere any long number would do.
+		re:    regexp.MustCompile(`^\d{10}(?:\d{3}|\d{6}|\d{9})?(?:\.\d{1,9})?\b`),
+		parse: parseEpoch,
+	},
+}
+
+// leadingPunctuation may precede a timestamp at the start of a line,
+// as in "[2024-01-02 03:04:05]".
+const leadingPunctuation = " \t[(<\"'"
+
+// isoLayouts are tried in order on normalised ISO 8601 timestamps.
+var isoLayouts = []string{
+	"2006-01-02T15:04:05.999999999Z07:00",
+	"2006-01-02T15:04:05.999999999Z0700",
+}
+
+// Find returns the first timestamp in line. Timestamps without a zone are
+// interpreted in loc; BSD timestamps, which carry no year, get the year
+// that places them closest to now.
+//
+// Parameters:
+//   - line: The log line to search
+//   - loc: The zone of timestamps without one, UTC if nil
+//   - now: The current time, used to complete timestamps without a year
+//
+// Returns:
+//   - time.Time: The parsed timestamp
+//   - bool: true if a timestamp was found
+func Find(line string, loc *time.Location, now time.Time) (time.Time, bool) {
+	return find(line, loc, now, false)
+}
+
+// Leading returns the timestamp a line starts with, allowing for opening
+// brackets and quotes before it. Timestamps are completed as by Find.
+//
+// Parameters:
+//   - line: The log line to search
+//   - loc: The zone of timestamps without one, UTC if nil
+//   - now: The current time, used to complete timestamps without a year
+//
+// Returns:
+//   - time.Time: The parsed timestamp
+//   - bool: true if the line starts with a timestamp
+func Leading(line string, loc *time.Location, now time.Time) (time.Time, bool) {
+	return find(line, loc, now, true)
+}
+
+// find returns the earliest timestamp in line, or only one at its start
+// if leading is set.
+func find(line string, loc *time.Location, now time.Time, leading bool) (time.Time, bool) {
+	if leading {
+		trimmed := strings.TrimLeft(line, leadingPunctuation)
+		line = line[len(line)-len(trimmed):]
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	best, bestStart := time.Time{}, -1
+	for _, p := range patterns {
+		span := p.re.FindStringIndex(line)
+		if span == nil || (bestStart >= 0 && span[0] >= bestStart) || (leading && span[0] > 0) {
+			continue
+		}
+		// A later pattern may still find an earlier match, so keep looking.
+		if t, ok := p.parse(line[span[0]:span[1]], loc, now); ok {
+			best, bestStart = t, span[0]
+		}
+	}
+	if bestStart < 0 {
+		return time.Time{}, false
+	}
+	return best, true
+}
+
+// parseISO parses an ISO 8601 timestamp.
+func parseISO(match string, loc *time.Location, _ time.Time) (time.Time, bool) {
+	normalized := []byte(match)
+	normalized[10] = 'T'
+	text := strings.Replace(string(normalized), ",", ".", 1)
+	text = strings.Replace(text, " ", "", 1)
+
+	for _, layout := range isoLayouts {
+		if t, err := time.Parse(layout, text); err == nil {
+			return t, true
+		}
+	}
+	t, err := time.ParseInLocation("2006-01-02T15:04:05.999999999", text, loc)
+	return t, err == nil
+}
+
+// rfc822Zones are the offsets in hours of the zone names RFC 822 defines
+// for the timestamps RFC 1123 builds on.
+var rfc822Zones = map[string]int{
+	"UT": 0, "UTC": 0, "GMT": 0,
+	"EST": -5, "EDT": -4,
+	"CST": -6, "CDT": -5,
+	"MST": -7, "MDT": -6,
+	"PST": -8, "PDT": -7,
+}
+
+// parseRFC1123 parses an RFC 1123 timestamp with a numeric zone or a zone
+// name. Names other than those of RFC 822 are only understood when they
+// are abbreviations of loc; any other name makes the timestamp unusable,
+// since its offset is unknown.
+func parseRFC1123(match string, loc *time.Location, _ time.Time) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC1123Z, match); err == nil {
+		return t, true
+	}
+	split := strings.LastIndexByte(match, ' ')
+	if hours, ok := rfc822Zones[match[split+1:]]; ok {
+		zone := time.FixedZone(match[split+1:], hours*60*60)
+		t, err := time.ParseInLocation("Mon, 02 Jan 2006 15:04:05", match[:split], zone)
+		return t, err == nil
+	}
+	// Parsing gives unknown abbreviations a zero offset in a made-up zone.
+	t, err := time.ParseInLocation(time.RFC1123, match, loc)
+	if err != nil || t.Location() != loc {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// parseCommonLog parses a Common Log Format timestamp.
+func parseCommonLog(match string, _ *time.Location, _ time.Time) (time.Time, bool) {
+	t, err := time.Parse("02/Jan/2006:15:04:05 -0700", match)
+	return t, err == nil
+}
+
+// parseBSD parses a BSD syslog timestamp, choosing the year that puts it
+// at most a day into the future.
+func parseBSD(match string, loc *time.Location, now time.Time) (time.Time, bool) {
+	t, err := time.ParseInLocation(time.Stamp, match, loc)
+	if err != nil {
+		return time.Time{}, false
+	}
+	t = t.AddDate(now.Year(), 0, 0)
+	if t.After(now.Add(24 * time.Hour)) {
+		t = t.AddDate(-1, 0, 0)
+	}
+	return t, true
+}
+
+// parseEpoch parses a Unix epoch number at the start of a line.
+func parseEpoch(match string, _ *time.Location, _ time.Time) (time.Time, bool) {
+	// Nanoseconds do not fit a float64 exactly.
+	if n, err := strconv.ParseInt(match, 10, 64); err == nil && n >= 1e17 {
+		return time.Unix(0, n), true
+	}
+	epoch, err := strconv.ParseFloat(match, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return Epoch(epoch)
+}
+
+// Value parses a timestamp decoded from JSON: an RFC 3339 string or a Unix
+// epoch number, or numeric string, in any of the units Epoch tells apart.
+// Receivers of JSON documents and parsers of JSON log lines share it, and
+// integers, as typed grok captures hold, are accepted as well.
+//
+// Parameters:
+//   - value: The decoded JSON value or field
+//
+// Returns:
+//   - time.Time: The parsed time
+//   - bool: true if value held a usable timestamp
+func Value(value interface{}) (time.Time, bool) {
+	var epoch float64
+	switch v := value.(type) {
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t, true
+		}
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		epoch = parsed
+	case json.Number:
+		// Nanoseconds do not fit a float64 exactly.
+		if nanos, err := v.Int64(); err == nil && nanos >= 1e17 {
+			return time.Unix(0, nanos), true
+		}
+		parsed, err := v.Float64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		epoch = parsed
+	case float64:
+		epoch = v
+	case int64:
+		// Nanoseconds do not fit a float64 exactly.
+		if v >= 1e17 {
+			return time.Unix(0, v), true
+		}
+		epoch = float64(v)
+	case int:
+		return Value(int64(v))
+	default:
+		return time.Time{}, false
+	}
+	return Epoch(epoch)
+}
+
+// Epoch converts a Unix epoch number in seconds, milliseconds, microseconds
+// or nanoseconds, telling the units apart by magnitude.
+//
+// Parameters:
+//   - epoch: The number, fractional for seconds
+//
+// Returns:
+//   - time.Time: The parsed time
+//   - bool: true if epoch is positive and fits a time, which NaN and the
+//     infinities parsed from strings such as "NaN" and "Inf" do not
+func Epoch(epoch float64) (time.Time, bool) {
+	if math.IsNaN(epoch) || math.IsInf(epoch, 0) || epoch <= 0 || epoch >= math.MaxInt64 {
+		return time.Time{}, false
+	}
+	// Seconds since the epoch stay below 1e11 until the year 5138, and
+	// likewise for the smaller units. Rounding drops float noise below the
+	// precision shippers send.
+	switch {
+	case epoch >= 1e17:
+		return time.Unix(0, int64(epoch)), true
+	case epoch >= 1e14:
+		return time.UnixMicro(int64(math.Round(epoch))), true
+	case epoch >= 1e11:
+		return time.UnixMilli(int64(math.Round(epoch))), true
+	default:
+		return time.UnixMicro(int64(math.Round(epoch * 1e6))), true
+	}
+}
@@ -0,0 +1,156 @@
+package timestamp
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestValue(t *testing.T) {
+	seconds := time.Unix(1710069753, 0)
+
+	tests := []struct {
+		name   string
+		value  interface{}
+		want   time.Time
+		wantOK bool
+	}{
+		{name: "rfc3339", value: "2024-03-10T11:22:33.5+01:00", want: time.Date(2024, time.March, 10, 10, 22, 33, 5e8, time.UTC), wantOK: true},
+		{name: "seconds string", value: "1710069753", want: seconds, wantOK: true},
+		{name: "fractional seconds", value: json.Number("1710069753.25"), want: time.UnixMilli(1710069753250), wantOK: true},
+		{name: "milliseconds", value: json.Number("1710069753123"), want: time.UnixMilli(1710069753123), wantOK: true},
+		{name: "microseconds", value: 1710069753123456.0, want: time.UnixMicro(1710069753123456), wantOK: true},
+		{name: "nanoseconds", value: json.Number("1710069753123456789"), want: time.Unix(0, 1710069753123456789), wantOK: true},
+		{name: "float seconds", value: 1710069753.0, want: seconds, wantOK: true},
+		{name: "int seconds", value: 1710069753, want: seconds, wantOK: true},
+		{name: "int64 milliseconds", value: int64(1710069753123), want: time.UnixMilli(1710069753123), wantOK: true},
+		{name: "int64 nanoseconds", value: int64(1710069753123456789), want: time.Unix(0, 1710069753123456789), wantOK: true},
+		{name: "negative int64", value: int64(-5)},
+		{name: "not a timestamp", value: "yesterday"},
+		{name: "truncated rfc3339", value: "2024-03-10T11:22"},
+		{name: "NaN string", value: "NaN"},
+		{name: "infinite string", value: "+Inf"},
+		{name: "infinite number", value: math.Inf(-1)},
+		{name: "NaN number", value: math.NaN()},
+		{name: "too large", value: json.Number("1e300")},
+		{name: "zero", value: json.Number("0")},
+		{name: "negative", value: -5.0},
+		{name: "bool", value: true},
+		{name: "nil", value: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Value(tt.value)
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("Value(%v) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestEpoch(t *testing.T) {
+	tests := []struct {
+		epoch  float64
+		want   time.Time
+		wantOK bool
+	}{
+		{epoch: 1710069753, want: time.Unix(1710069753, 0), wantOK: true},
+		{epoch: 1710069753.000001, want: time.UnixMicro(1710069753000001), wantOK: true},
+		{epoch: 99999999999, want: time.Unix(99999999999, 0), wantOK: true},
+		{epoch: 1e11, want: time.UnixMilli(1e11), wantOK: true},
+		{epoch: 1e14, want: time.UnixMicro(1e14), wantOK: true},
+		{epoch: 1e17, want: time.Unix(0, 1e17), wantOK: true},
+		{epoch: 0},
+		{epoch: -1},
+		{epoch: math.MaxInt64},
+		{epoch: math.NaN()},
+		{epoch: math.Inf(1)},
+	}
+
+	for _, tt := range tests {
+		got, ok := Epoch(tt.epoch)
+		if ok != tt.wantOK || !got.Equal(tt.want) {
+			t.Errorf("Epoch(%v) = %v, %v, want %v, %v", tt.epoch, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	now := time.Date(2024, time.March, 10, 12, 0, 0, 0, time.UTC)
+	cet := time.FixedZone("CET", 60*60)
+
+	tests := []struct {
+		name   string
+		line   string
+		loc    *time.Location
+		want   time.Time
+		wantOK bool
+	}{
+		{name: "rfc3339 inside a line", line: "level=info ts=2024-01-02T03:04:05.123Z msg=x", want: time.Date(2024, time.January, 2, 3, 4, 5, 123e6, time.UTC), wantOK: true},
+		{name: "rfc3339 with offset", line: "2024-01-02T03:04:05+05:30 up", want: time.Date(2024, time.January, 1, 21, 34, 5, 0, time.UTC), wantOK: true},
+		{name: "java timestamp in loc", line: "2024-01-02 03:04:05,123 INFO up", loc: cet, want: time.Date(2024, time.January, 2, 2, 4, 5, 123e6, time.UTC), wantOK: true},
+		{name: "zone after a space", line: "2024-01-02 03:04:05 +0100 up", want: time.Date(2024, time.January, 2, 2, 4, 5, 0, time.UTC), wantOK: true},
+		{name: "without loc is UTC", line: "2024-01-02 03:04:05 up", want: time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC), wantOK: true},
+		{name: "rfc1123 numeric zone", line: "Date: Tue, 02 Jan 2024 03:04:05 -0700", want: time.Date(2024, time.January, 2, 10, 4, 5, 0, time.UTC), wantOK: true},
+		{name: "rfc1123 GMT", line: "Tue, 02 Jan 2024 03:04:05 GMT", want: time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC), wantOK: true},
+		{name: "rfc1123 RFC 822 zone", line: "sent Tue, 02 Jan 2024 03:04:05 EST", want: time.Date(2024, time.January, 2, 8, 4, 5, 0, time.UTC), wantOK: true},
+		{name: "rfc1123 zone of loc", line: "Tue, 02 Jan 2024 03:04:05 CET", loc: cet, want: time.Date(2024, time.January, 2, 2, 4, 5, 0, time.UTC), wantOK: true},
+		{name: "rfc1123 unknown zone", line: "Tue, 02 Jan 2024 03:04:05 XYZ", loc: cet},
+		{name: "common log format", line: `127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET /"`, want: time.Date(2000, time.October, 10, 20, 55, 36, 0, time.UTC), wantOK: true},
+		{name: "bsd syslog", line: "Mar  9 22:14:15 host su: failed", loc: cet, want: time.Date(2024, time.March, 9, 21, 14, 15, 0, time.UTC), wantOK: true},
+		{name: "bsd syslog less than a day ahead", line: "Mar 11 00:00:00 host up", want: time.Date(2024, time.March, 11, 0, 0, 0, 0, time.UTC), wantOK: true},
+		{name: "bsd syslog from last year", line: "Dec 31 23:59:59 host up", want: time.Date(2023, time.December, 31, 23, 59, 59, 0, time.UTC), wantOK: true},
+		{name: "epoch seconds", line: "1704164645 started", want: time.Unix(1704164645, 0), wantOK: true},
+		{name: "fractional epoch", line: "1704164645.5 started", want: time.UnixMilli(1704164645500), wantOK: true},
+		{name: "epoch milliseconds", line: "1704164645123 started", want: time.UnixMilli(1704164645123), wantOK: true},
+		{name: "epoch nanoseconds", line: "1704164645123456789 started", want: time.Unix(0, 1704164645123456789), wantOK: true},
+		{name: "epoch not at the start", line: "id=1704164645 started"},
+		{name: "earliest wins", line: "Tue, 01 Jan 2019 00:00:00 GMT replayed at 2024-01-02T03:04:05Z", want: time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC), wantOK: true},
+		{name: "invalid date", line: "2024-13-45T99:00:00Z up"},
+		{name: "truncated", line: "2024-01-02T03:04 up"},
+		{name: "none", line: "server started"},
+		{name: "empty", line: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Find(tt.line, tt.loc, now)
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("Find(%q) = %v, %v, want %v, %v", tt.line, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestLeading(t *testing.T) {
+	now := time.Date(2024, time.March, 10, 12, 0, 0, 0, time.UTC)
+	stamp := time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		line   string
+		want   time.Time
+		wantOK bool
+	}{
+		{name: "at the start", line: "2024-01-02T03:04:05Z up", want: stamp, wantOK: true},
+		{name: "in brackets", line: "[2024-01-02 03:04:05] up", want: stamp, wantOK: true},
+		{name: "quoted rfc1123", line: `"Tue, 02 Jan 2024 03:04:05 GMT" up`, want: stamp, wantOK: true},
+		{name: "after blanks and a parenthesis", line: " \t(Jan  2 03:04:05) up", want: stamp, wantOK: true},
+		{name: "epoch", line: "1704164645 up", want: time.Unix(1704164645, 0), wantOK: true},
+		{name: "later in the line", line: "up at 2024-01-02T03:04:05Z"},
+		{name: "after other punctuation", line: "[] 2024-01-02T03:04:05Z up"},
+		{name: "only punctuation", line: "[("},
+		{name: "empty", line: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Leading(tt.line, time.UTC, now)
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("Leading(%q) = %v, %v, want %v, %v", tt.line, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
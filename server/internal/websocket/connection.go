@@ -1,8 +1,10 @@
 package websocket
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
+	"slices"
 	"smart-log-viewer/server/internal/model"
 	"sync"
 	"time"
@@ -15,6 +17,8 @@ import (
 // buffering, pause state, and health monitoring.
 type Connection struct {
 	ws       *websocket.Conn
+	hub      *ConnectionHub // Hub that answers history requests
+	resume   *resumePoint   // Where the client left off before reconnecting, if it resumes
 	channel  chan model.WebSocketMessage
 	lastSent time.Time    // Each connection tracks its own timing
 	mu       sync.RWMutex // Protect connection's own state (read/write mutex)
@@ -28,13 +32,15 @@ type Connection struct {
 //
 // Parameters:
 //   - ws: The underlying WebSocket connection
+//   - hub: The hub the connection is registered with
 //
 // Returns:
 //   - *Connection: A new connection instance
-func NewConnection(ws *websocket.Conn) *Connection {
+func NewConnection(ws *websocket.Conn, hub *ConnectionHub) *Connection {
 	log.Printf("Creating new WebSocket connection: %p", ws)
 	return &Connection{
 		ws:       ws,
+		hub:      hub,
 		channel:  make(chan model.WebSocketMessage, 100), // Buffer for better performance
 		lastSent: time.Now(),
 		isClosed: false,
@@ -57,7 +63,7 @@ func (c *Connection) Send() {
 	}()
 
 	for message := range c.channel {
-		if err := c.ws.WriteJSON(message); err != nil {
+		if err := c.write(message); err != nil {
 			log.Printf("Error sending message to client %p: %v", c, err)
 			break
 		}
@@ -66,6 +72,26 @@ func (c *Connection) Send() {
 	log.Printf("Send goroutine finished for connection: %p", c)
 }
 
+// batch is the data of a queued message that stands for several messages,
+// such as the answer to a history request. It takes a single slot of the
+// channel, so it is never cut short by a full buffer.
+type batch []model.WebSocketMessage
+
+// write sends a queued message to the client, one frame per message of
+// a batch.
+func (c *Connection) write(message model.WebSocketMessage) error {
+	messages, ok := message.Data.(batch)
+	if !ok {
+		return c.ws.WriteJSON(message)
+	}
+	for _, message := range messages {
+		if err := c.ws.WriteJSON(message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Close safely closes the connection and cleans up resources.
 // It marks the connection as closed, clears missed logs to prevent
 // memory leaks, and safely closes the message channel.
@@ -83,13 +109,9 @@ func (c *Connection) Close() {
 	log.Printf("CLOSING CONNECTION %p", c)
 	c.isClosed = true // Mark as closed
 
-	// Close channel safely
-	select {
-	case <-c.channel:
-		// Channel already closed, do nothing
-	default:
-		close(c.channel) // Close channel
-	}
+	// isClosed guards against closing twice; the send goroutine still
+	// delivers what was queued, such as a final error message
+	close(c.channel)
 }
 
 // IsClosed safely checks if the connection is closed.
@@ -128,6 +150,43 @@ func (c *Connection) sendLog(message model.WebSocketMessage) {
 	}
 }
 
+// reply queues a protocol message for the client. Unlike sendLog it does
+// not count as activity, so heartbeats sent to a paused connection do not
+// keep it alive on their own.
+//
+// Parameters:
+//   - message: The WebSocket message to send
+//
+// Returns:
+//   - error: nil if the message was queued, error otherwise
+func (c *Connection) reply(message model.WebSocketMessage) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.isClosed {
+		return fmt.Errorf("connection is closed")
+	}
+
+	select {
+	case c.channel <- message:
+		return nil
+	default:
+		return fmt.Errorf("channel full, dropping message type '%s'", message.Type)
+	}
+}
+
+// replyError sends an error message answering the request with the given ID.
+//
+// Parameters:
+//   - id: The request ID of the message that failed, if any
+//   - data: The error to report
+func (c *Connection) replyError(id string, data model.ErrorData) {
+	log.Printf("Connection %p: %s: %s", c, data.Code, data.Message)
+	if err := c.reply(model.WebSocketMessage{Type: model.MessageError, ID: id, Data: data}); err != nil {
+		log.Printf("Failed to send error to connection %p: %v", c, err)
+	}
+}
+
 // shouldDrop determines if this connection should be dropped.
 // It checks if the connection is closed or if paused connections
 // haven't responded to ping messages within the timeout period.
@@ -191,19 +250,13 @@ func (c *Connection) IsPaused() bool {
 // Returns:
 //   - error: nil if ping was sent successfully, error otherwise
 func (c *Connection) SendPing() error {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	if c.isClosed {
-		return fmt.Errorf("connection is closed")
-	}
-
 	pingMessage := model.WebSocketMessage{
-		Type: "ping",
+		Type: model.MessagePing,
 		Data: "heartbeat",
 	}
 
-	if err := c.ws.WriteJSON(pingMessage); err != nil {
+	// Pings go through the send goroutine, the only writer of the socket.
+	if err := c.reply(pingMessage); err != nil {
 		log.Printf("Failed to send ping to connection %p: %v", c, err)
 		return err
 	}
@@ -214,7 +267,9 @@ func (c *Connection) SendPing() error {
 
 // HandleMessages runs the message handler goroutine for this connection.
 // It continuously reads messages from the WebSocket client and processes
-// them according to their type (pause, resume, ping, pong).
+// them according to their type (hello, pause, resume, ping, pong).
+// Messages it cannot handle are answered with an error message instead
+// of closing the connection.
 //
 // This method runs in a separate goroutine and handles the complete
 // lifecycle of client message processing.
@@ -227,8 +282,8 @@ func (c *Connection) HandleMessages() {
 
 	for {
 		// Read message from WebSocket
-		var message model.WebSocketMessage
-		if err := c.ws.ReadJSON(&message); err != nil {
+		_, data, err := c.ws.ReadMessage()
+		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				log.Printf("WebSocket read error for connection %p: %v", c, err)
 			} else {
@@ -237,52 +292,138 @@ func (c *Connection) HandleMessages() {
 			break
 		}
 
-		log.Printf("📨 RECEIVED MESSAGE from connection %p: Type=%s, Data=%+v", c, message.Type, message.Data)
+		var message inboundMessage
+		if err := json.Unmarshal(data, &message); err != nil || message.Type == "" {
+			c.replyError(message.ID, model.ErrorData{
+				Code:    model.ErrorInvalidMessage,
+				Message: "message must be a JSON object with a type",
+			})
+			continue
+		}
 
-		// Handle different message types
-		switch message.Type {
-		case "ping":
-			// Client heartbeat ping, respond with pong
-			c.mu.Lock()
-			c.lastSent = time.Now()
-			c.mu.Unlock()
+		log.Printf("📨 RECEIVED MESSAGE from connection %p: Type=%s, ID=%s", c, message.Type, message.ID)
 
-			pongMessage := model.WebSocketMessage{
-				Type: "pong",
-				Data: "heartbeat",
-			}
+		// Every message from the client shows that it is alive
+		c.mu.Lock()
+		c.lastSent = time.Now()
+		c.mu.Unlock()
 
-			if err := c.ws.WriteJSON(pongMessage); err != nil {
-				log.Printf("Failed to send pong to connection %p: %v", c, err)
-			} else {
-				log.Printf("Connection %p sent ping, responded with pong", c)
-			}
-		case "pong":
-			// Update last sent time when we receive pong (client is alive)
-			c.mu.Lock()
-			c.lastSent = time.Now()
-			c.mu.Unlock()
-			log.Printf("Connection %p responded to ping with pong", c)
-		case "pause":
-			// Update last sent time when client sends pause (client is alive)
-			c.mu.Lock()
-			c.lastSent = time.Now()
-			c.mu.Unlock()
-			log.Printf("PROCESSING PAUSE for connection %p", c)
-			c.SetPaused(true)
-			log.Printf("Connection %p PAUSED successfully", c)
-		case "resume":
-			// Update last sent time when client sends resume (client is alive)
-			c.mu.Lock()
-			c.lastSent = time.Now()
-			c.mu.Unlock()
-			log.Printf("PROCESSING RESUME for connection %p", c)
-			c.SetPaused(false)
-			log.Printf("Connection %p RESUMED successfully", c)
-		default:
-			log.Printf("Unknown message type from connection %p: %s", c, message.Type)
+		if !c.handle(message) {
+			break
 		}
 	}
 
 	log.Printf("Message handler finished for connection %p", c)
 }
+
+// inboundMessage is a message from a client, whose payload is decoded
+// once its type is known.
+type inboundMessage struct {
+	Type string          `json:"type"`
+	ID   string          `json:"id"`
+	Data json.RawMessage `json:"data"`
+}
+
+// supportedVersions lists the protocol versions the server speaks.
+var supportedVersions = []int{model.ProtocolVersion}
+
+// handle processes one client message.
+//
+// Parameters:
+//   - message: The message to process
+//
+// Returns:
+//   - bool: false if the connection must be closed
+func (c *Connection) handle(message inboundMessage) bool {
+	var answer model.WebSocketMessage
+	switch message.Type {
+	case model.MessageHello:
+		var hello model.HelloData
+		if err := json.Unmarshal(message.Data, &hello); err != nil {
+			c.replyError(message.ID, model.ErrorData{
+				Code:    model.ErrorInvalidData,
+				Message: fmt.Sprintf("invalid hello: %v", err),
+			})
+			return true
+		}
+		version := negotiateVersion(hello.Versions)
+		if version == 0 {
+			c.replyError(message.ID, model.ErrorData{
+				Code:     model.ErrorUnsupportedVersion,
+				Message:  fmt.Sprintf("no supported protocol version in %v", hello.Versions),
+				Versions: supportedVersions,
+			})
+			return false
+		}
+		log.Printf("Connection %p: client %q speaks protocol version %d", c, hello.Client, version)
+		answer = model.WebSocketMessage{
+			Type: model.MessageWelcome,
+			Data: model.WelcomeData{Version: version, Versions: supportedVersions, Paused: c.IsPaused(), Run: c.hub.run},
+		}
+	case model.MessagePing:
+		// Client heartbeat ping, respond with pong
+		answer = model.WebSocketMessage{Type: model.MessagePong, Data: "heartbeat"}
+	case model.MessagePong:
+		log.Printf("Connection %p responded to ping with pong", c)
+		return true
+	case model.MessageHistory:
+		var query model.HistoryData
+		if len(message.Data) > 0 && string(message.Data) != "null" {
+			if err := json.Unmarshal(message.Data, &query); err != nil {
+				c.replyError(message.ID, model.ErrorData{
+					Code:    model.ErrorInvalidData,
+					Message: fmt.Sprintf("invalid history: %v", err),
+				})
+				return true
+			}
+		}
+		if query.Limit < 0 {
+			c.replyError(message.ID, model.ErrorData{
+				Code:    model.ErrorInvalidData,
+				Message: fmt.Sprintf("invalid history limit %d", query.Limit),
+			})
+			return true
+		}
+		// The hub answers, so that the history and the live logs are queued
+		// in order
+		select {
+		case c.hub.requests <- historyRequest{connection: c, id: message.ID, query: query}:
+		case <-time.After(5 * time.Second):
+			log.Printf("ERROR: Hub history request timeout for connection %p", c)
+		}
+		return true
+	case model.MessagePause, model.MessageResume:
+		log.Printf("PROCESSING %s for connection %p", message.Type, c)
+		c.SetPaused(message.Type == model.MessagePause)
+		answer = model.WebSocketMessage{Type: model.MessageStatus, Data: model.StatusData{Paused: c.IsPaused()}}
+	default:
+		c.replyError(message.ID, model.ErrorData{
+			Code:    model.ErrorUnknownType,
+			Message: fmt.Sprintf("unknown message type %q", message.Type),
+		})
+		return true
+	}
+
+	answer.ID = message.ID
+	if err := c.reply(answer); err != nil {
+		log.Printf("Failed to answer %s from connection %p: %v", message.Type, c, err)
+	}
+	return true
+}
+
+// negotiateVersion picks the newest protocol version both sides support.
+//
+// Parameters:
+//   - offered: The versions the client supports
+//
+// Returns:
+//   - int: The version to use, or 0 if there is none
+func negotiateVersion(offered []int) int {
+	best := 0
+	for _, version := range offered {
+		if version > best && slices.Contains(supportedVersions, version) {
+			best = version
+		}
+	}
+	return best
+}
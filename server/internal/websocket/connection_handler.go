@@ -1,8 +1,11 @@
 package websocket
 
 import (
+	"errors"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -17,13 +20,44 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
+// resumePoint is where a reconnecting client left off.
+type resumePoint struct {
+	run string // Run of the server the client received its last log from, or "" for this one
+	id  uint64 // ID of that log
+}
+
+// parseResume reads a resume_from query parameter of the form "<run>:<id>",
+// or a bare "<id>" from the current run.
+//
+// Parameters:
+//   - value: The parameter value
+//
+// Returns:
+//   - resumePoint: The run and log ID to resume from
+//   - error: nil on success, or an error describing the malformed value
+func parseResume(value string) (resumePoint, error) {
+	run, id, ok := strings.Cut(value, ":")
+	if !ok {
+		run, id = "", value
+	} else if run == "" {
+		return resumePoint{}, errors.New("resume_from must be [<run>:]<id>")
+	}
+	number, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return resumePoint{}, errors.New("resume_from has an invalid log ID")
+	}
+	return resumePoint{run: run, id: number}, nil
+}
+
 // HandleWebSocket handles incoming WebSocket connection requests.
 // It upgrades the HTTP connection to a WebSocket connection,
 // creates a new Connection instance, and registers it with the hub.
 //
 // The function includes timeout protection to prevent deadlocks
 // during hub registration and starts the necessary goroutines
-// for message handling and sending.
+// for message handling and sending. A client that reconnects can pass
+// the server run and ID of the last log it received as the resume_from
+// query parameter to be sent the logs it missed first.
 //
 // Parameters:
 //   - w: HTTP response writer for the upgrade response
@@ -32,6 +66,17 @@ var upgrader = websocket.Upgrader{
 func HandleWebSocket(w http.ResponseWriter, r *http.Request, hub *ConnectionHub) {
 	log.Printf("New WebSocket connection request from %s", r.RemoteAddr)
 
+	// A reconnecting client passes the run and ID of the last log it received
+	var resume *resumePoint
+	if value := r.URL.Query().Get("resume_from"); value != "" {
+		point, err := parseResume(value)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		resume = &point
+	}
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Printf("Error upgrading to WebSocket from %s: %v", r.RemoteAddr, err)
@@ -39,7 +84,8 @@ func HandleWebSocket(w http.ResponseWriter, r *http.Request, hub *ConnectionHub)
 	}
 
 	log.Printf("WebSocket upgraded successfully for %s", r.RemoteAddr)
-	connection := NewConnection(conn)
+	connection := NewConnection(conn, hub)
+	connection.resume = resume
 
 	// Non-blocking registration with timeout to prevent deadlock
 	select {
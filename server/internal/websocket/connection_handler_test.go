@@ -0,0 +1,162 @@
+package websocket
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"smart-log-viewer/server/internal/config"
+	"smart-log-viewer/server/internal/model"
+)
+
+func TestParseResume(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    resumePoint
+		wantErr string
+	}{
+		{value: "lq3x0a:42", want: resumePoint{run: "lq3x0a", id: 42}},
+		{value: "run:0", want: resumePoint{run: "run"}},
+		{value: "42", want: resumePoint{id: 42}},
+		{value: "a:b:1", wantErr: "invalid log ID"},
+		{value: "run", wantErr: "invalid log ID"},
+		{value: ":42", wantErr: "must be [<run>:]<id>"},
+		{value: "run:", wantErr: "invalid log ID"},
+		{value: "run:-1", wantErr: "invalid log ID"},
+		{value: "run:18446744073709551616", wantErr: "invalid log ID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseResume(tt.value)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseResume() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseResume() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("parseResume() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// describe summarizes a message of a resume batch.
+func describe(message model.WebSocketMessage) string {
+	switch data := message.Data.(type) {
+	case model.Log:
+		return fmt.Sprintf("log %d", data.ID)
+	case model.GapData:
+		if data.Reset {
+			return "reset"
+		}
+		return fmt.Sprintf("gap %d-%d", data.From, data.To)
+	case model.LiveData:
+		return fmt.Sprintf("live %d", data.Count)
+	default:
+		return message.Type
+	}
+}
+
+func TestHubResumes(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.HistoryConfig
+		run  string // empty for a bare ID, which is from the run of the hub
+		id   uint64
+		want []string
+	}{
+		{name: "up to date", id: 5, want: []string{"live 0"}},
+		{name: "missed kept logs", id: 3, want: []string{"log 4", "log 5", "live 2"}},
+		{name: "missed evicted logs", id: 1, want: []string{"gap 2-2", "log 3", "log 4", "log 5", "live 3"}},
+		{name: "received nothing", id: 0, want: []string{"gap 1-2", "log 3", "log 4", "log 5", "live 3"}},
+		{name: "earlier run", run: "earlier", id: 4, want: []string{"reset", "gap 1-2", "log 3", "log 4", "log 5", "live 3"}},
+		{name: "ID ahead of the run", id: 9, want: []string{"reset", "gap 1-2", "log 3", "log 4", "log 5", "live 3"}},
+		{name: "nothing kept", cfg: config.HistoryConfig{MaxBytes: 1}, id: 2, want: []string{"gap 3-5", "live 0"}},
+		{name: "gap inside the kept logs", cfg: config.HistoryConfig{MaxBytes: 250}, id: 0, want: []string{"gap 1-1", "log 2", "log 3", "gap 4-4", "log 5", "live 3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.cfg.MaxEntries == 0 {
+				tt.cfg.MaxEntries = 3
+			}
+			hub := startHub(tt.cfg)
+			for id := 1; id <= 5; id++ {
+				message := "m"
+				if id == 4 {
+					// Too large for the byte bound of the gap inside the kept logs.
+					message = strings.Repeat("m", 200)
+				}
+				hub.Broadcast <- model.WebSocketMessage{Type: model.MessageLog, Data: model.Log{Message: message}}
+			}
+
+			connection := newTestConnection(hub)
+			connection.resume = &resumePoint{run: tt.run, id: tt.id}
+			hub.register <- connection
+
+			answer := next(t, connection)
+			messages, ok := answer.Data.(batch)
+			if answer.Type != model.MessageHistory || !ok {
+				t.Fatalf("answer = %+v, want a history batch", answer)
+			}
+			var got []string
+			for _, message := range messages {
+				got = append(got, describe(message))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resume = %v, want %v", got, tt.want)
+			}
+
+			// Live logs follow the resume batch without a hole.
+			hub.Broadcast <- model.WebSocketMessage{Type: model.MessageLog, Data: model.Log{Message: "live"}}
+			if live := next(t, connection); live.Data.(model.Log).ID != 6 {
+				t.Errorf("first live log = %+v, want ID 6", live.Data)
+			}
+		})
+	}
+}
+
+func TestHandleWebSocketResume(t *testing.T) {
+	hub := startHub(config.HistoryConfig{})
+	for _, message := range []string{"a", "b"} {
+		hub.Broadcast <- model.WebSocketMessage{Type: model.MessageLog, Data: model.Log{Level: "INFO", Message: message}}
+	}
+
+	conn := dial(t, hub, "resume_from="+hub.run+":1")
+	want := []string{
+		`{"type":"log","data":{"level":"INFO","message":"b","timestamp":"0001-01-01T00:00:00Z","id":2}}`,
+		`{"type":"live","data":{"count":1}}`,
+	}
+	for _, w := range want {
+		if got := read(t, conn); got != w {
+			t.Errorf("received %s\nwant %s", got, w)
+		}
+	}
+}
+
+func TestHandleWebSocketRejectsMalformedResume(t *testing.T) {
+	hub := startHub(config.HistoryConfig{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		HandleWebSocket(w, r, hub)
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/ws?resume_from=run:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusBadRequest || !strings.Contains(string(body), "invalid log ID") {
+		t.Errorf("response = %d %q, want 400 and the parse error", resp.StatusCode, body)
+	}
+}
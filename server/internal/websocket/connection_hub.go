@@ -2,10 +2,19 @@ package websocket
 
 import (
 	"log"
+	"smart-log-viewer/server/internal/config"
 	"smart-log-viewer/server/internal/model"
+	"strconv"
 	"time"
 )
 
+// historyRequest asks the hub to send kept logs to a connection.
+type historyRequest struct {
+	connection *Connection
+	id         string
+	query      model.HistoryData
+}
+
 // ConnectionHub manages all active WebSocket connections.
 // It provides centralized connection management including registration,
 // unregistration, broadcasting, and health monitoring.
@@ -17,22 +26,119 @@ type ConnectionHub struct {
 	register    chan *Connection
 	unregister  chan *Connection
 	Broadcast   chan model.WebSocketMessage // Capitalized to make it public
+	sequence    uint64                      // ID of the last broadcast log entry
+	run         string                      // Identifies this run of the server, whose IDs start at 1
+	history     *history                    // Recent logs for backfilling clients
+	requests    chan historyRequest
 }
 
 // NewConnectionHub creates a new connection hub instance.
 // It initializes the hub with empty connection maps and
 // buffered channels for connection management.
 //
+// Parameters:
+//   - cfg: The bounds of the log history kept for backfilling clients
+//
 // Returns:
 //   - *ConnectionHub: A new connection hub instance
-func NewConnectionHub() *ConnectionHub {
+func NewConnectionHub(cfg config.HistoryConfig) *ConnectionHub {
 	log.Printf("Creating new ConnectionHub")
 	return &ConnectionHub{
 		connections: make(map[*Connection]bool),
 		register:    make(chan *Connection),
 		unregister:  make(chan *Connection),
 		Broadcast:   make(chan model.WebSocketMessage),
+		run:         strconv.FormatInt(time.Now().UnixNano(), 36),
+		history:     newHistory(cfg),
+		requests:    make(chan historyRequest),
+	}
+}
+
+// sendHistory answers a history request with the matching kept logs and
+// the live marker. They are queued as one batch, so that no live log can
+// come between them.
+//
+// Parameters:
+//   - request: The history request of a connection
+func (h *ConnectionHub) sendHistory(request historyRequest) {
+	logs := h.history.query(request.query.Limit, request.query.Since)
+	messages := make(batch, 0, len(logs)+1)
+	for _, entry := range logs {
+		messages = append(messages, model.WebSocketMessage{Type: model.MessageLog, Data: entry})
+	}
+	messages = append(messages, model.WebSocketMessage{
+		Type: model.MessageLive,
+		ID:   request.id,
+		Data: model.LiveData{Count: len(logs)},
+	})
+
+	if err := request.connection.reply(model.WebSocketMessage{Type: model.MessageHistory, Data: messages}); err != nil {
+		log.Printf("Failed to send history to connection %p: %v", request.connection, err)
+		// Tell the client to ask again rather than leave it waiting for the
+		// live marker, or close the connection if it cannot take that either
+		busy := model.WebSocketMessage{
+			Type: model.MessageError,
+			ID:   request.id,
+			Data: model.ErrorData{Code: model.ErrorBusy, Message: "connection is backlogged, request the history again later"},
+		}
+		if err := request.connection.reply(busy); err != nil {
+			h.drop(request.connection)
+		}
+		return
+	}
+	log.Printf("Sent %d logs of history to connection %p", len(logs), request.connection)
+}
+
+// sendResume sends a reconnected client the logs broadcast after the last
+// one it received, reporting the ones no longer kept with gap messages,
+// and then the live marker. It is called as the connection registers, so
+// the logs continue without a hole into the live ones.
+//
+// Parameters:
+//   - connection: The registering connection
+//   - from: The run and ID of the last log the client received
+func (h *ConnectionHub) sendResume(connection *Connection, from resumePoint) {
+	var messages batch
+	id := from.id
+	if (from.run != "" && from.run != h.run) || id > h.sequence {
+		// The client's logs come from an earlier run of the server, whose
+		// IDs this run reuses, so it missed this whole run
+		messages = append(messages, model.WebSocketMessage{Type: model.MessageGap, Data: model.GapData{Reset: true}})
+		id = 0
 	}
+
+	logs := h.history.after(id)
+	next := id + 1
+	for _, entry := range logs {
+		if entry.ID > next {
+			messages = append(messages, model.WebSocketMessage{Type: model.MessageGap, Data: model.GapData{From: next, To: entry.ID - 1}})
+		}
+		messages = append(messages, model.WebSocketMessage{Type: model.MessageLog, Data: entry})
+		next = entry.ID + 1
+	}
+	if next <= h.sequence {
+		messages = append(messages, model.WebSocketMessage{Type: model.MessageGap, Data: model.GapData{From: next, To: h.sequence}})
+	}
+	messages = append(messages, model.WebSocketMessage{Type: model.MessageLive, Data: model.LiveData{Count: len(logs)}})
+
+	if err := connection.reply(model.WebSocketMessage{Type: model.MessageHistory, Data: messages}); err != nil {
+		// The client reconnects and resumes again
+		log.Printf("Failed to resume connection %p: %v", connection, err)
+		h.drop(connection)
+		return
+	}
+	log.Printf("Resumed connection %p after log %d with %d logs", connection, id, len(logs))
+}
+
+// drop removes a connection from the hub and closes it. Messages already
+// queued are still sent before the socket closes.
+//
+// Parameters:
+//   - connection: The connection to drop
+func (h *ConnectionHub) drop(connection *Connection) {
+	delete(h.connections, connection)
+	log.Printf("DROPPED Connection %p, total connections: %d", connection, len(h.connections))
+	connection.Close()
 }
 
 // checkConnectionHealth performs health checks on all active connections.
@@ -88,7 +194,9 @@ func (h *ConnectionHub) checkConnectionHealth() {
 // unregistration, health checks, and message broadcasting.
 //
 // This method runs in a single goroutine and coordinates all
-// connection operations to prevent race conditions.
+// connection operations to prevent race conditions. It also assigns
+// every broadcast log entry its ID, keeps it in the history and answers
+// history requests.
 //
 // The hub will continue running until the program exits or an
 // unrecoverable error occurs.
@@ -104,6 +212,9 @@ func (h *ConnectionHub) Run() {
 		case connection := <-h.register:
 			h.connections[connection] = true
 			log.Printf("REGISTERED Connection %p, total connections: %d", connection, len(h.connections))
+			if connection.resume != nil {
+				h.sendResume(connection, *connection.resume)
+			}
 
 		case connection := <-h.unregister:
 			delete(h.connections, connection)
@@ -114,7 +225,19 @@ func (h *ConnectionHub) Run() {
 			// Check connection health every 2 seconds
 			h.checkConnectionHealth()
 
+		case request := <-h.requests:
+			h.sendHistory(request)
+
 		case logEntry := <-h.Broadcast:
+			// Number log entries in broadcast order, even when nobody is
+			// listening, so that IDs never repeat
+			if entry, ok := logEntry.Data.(model.Log); ok {
+				h.sequence++
+				entry.ID = h.sequence
+				logEntry.Data = entry
+				h.history.add(entry)
+			}
+
 			if len(h.connections) == 0 {
 				continue
 			}
@@ -139,13 +262,13 @@ func (h *ConnectionHub) Run() {
 				activeConnections = append(activeConnections, conn)
 			}
 
-			// Broadcast to active connections only
+			// Broadcast to active connections only. sendLog never blocks, so
+			// sending from the hub keeps every connection's logs in order,
+			// also relative to the history it requested
 			for _, conn := range activeConnections {
-				go func(c *Connection) {
-					if !c.IsPaused() && !c.shouldDrop() {
-						c.sendLog(logEntry)
-					}
-				}(conn)
+				if !conn.IsPaused() && !conn.shouldDrop() {
+					conn.sendLog(logEntry)
+				}
 			}
 		}
 	}
@@ -0,0 +1,138 @@
+package websocket
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"smart-log-viewer/server/internal/config"
+	"smart-log-viewer/server/internal/model"
+)
+
+// startHub runs a hub with the given history bounds. Hubs run until the
+// program exits.
+func startHub(cfg config.HistoryConfig) *ConnectionHub {
+	hub := NewConnectionHub(cfg)
+	go hub.Run()
+	return hub
+}
+
+// newTestConnection creates a connection without a socket whose queued
+// messages the test reads from its channel.
+func newTestConnection(hub *ConnectionHub) *Connection {
+	return &Connection{
+		hub:      hub,
+		channel:  make(chan model.WebSocketMessage, 100),
+		lastSent: time.Now(),
+	}
+}
+
+// broadcastLogs broadcasts log entries with the given messages.
+func broadcastLogs(hub *ConnectionHub, messages ...string) {
+	for _, message := range messages {
+		hub.Broadcast <- model.WebSocketMessage{Type: model.MessageLog, Data: model.Log{Message: message}}
+	}
+}
+
+// next returns the next message queued for a connection.
+func next(t *testing.T, connection *Connection) model.WebSocketMessage {
+	t.Helper()
+
+	select {
+	case message, ok := <-connection.channel:
+		if !ok {
+			t.Fatal("connection closed")
+		}
+		return message
+	case <-time.After(2 * time.Second):
+		t.Fatal("no message queued")
+		return model.WebSocketMessage{}
+	}
+}
+
+func TestHubAssignsIDs(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   []string // broadcast before the connection registers
+		messages []model.WebSocketMessage
+		want     []uint64 // ID of each received log, 0 for other messages
+	}{
+		{
+			name: "numbered in broadcast order",
+			messages: []model.WebSocketMessage{
+				{Type: model.MessageLog, Data: model.Log{Message: "a"}},
+				{Type: model.MessageLog, Data: model.Log{Message: "b"}},
+				{Type: model.MessageLog, Data: model.Log{Message: "c"}},
+			},
+			want: []uint64{1, 2, 3},
+		},
+		{
+			name: "IDs set by sources are replaced",
+			messages: []model.WebSocketMessage{
+				{Type: model.MessageLog, Data: model.Log{Message: "a", ID: 42}},
+				{Type: model.MessageLog, Data: model.Log{Message: "b", ID: 1}},
+			},
+			want: []uint64{1, 2},
+		},
+		{
+			name: "other messages are not numbered",
+			messages: []model.WebSocketMessage{
+				{Type: model.MessageLog, Data: model.Log{Message: "a"}},
+				{Type: model.MessageStatus, Data: model.StatusData{Paused: true}},
+				{Type: model.MessageLog, Data: model.Log{Message: "b"}},
+			},
+			want: []uint64{1, 0, 2},
+		},
+		{
+			name:   "numbered while nobody listens",
+			before: []string{"a", "b"},
+			messages: []model.WebSocketMessage{
+				{Type: model.MessageLog, Data: model.Log{Message: "c"}},
+			},
+			want: []uint64{3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub := startHub(config.HistoryConfig{})
+			broadcastLogs(hub, tt.before...)
+
+			connection := newTestConnection(hub)
+			hub.register <- connection
+			for _, message := range tt.messages {
+				hub.Broadcast <- message
+			}
+
+			var got []uint64
+			for range tt.messages {
+				message := next(t, connection)
+				entry, _ := message.Data.(model.Log)
+				got = append(got, entry.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("IDs = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHubSkipsPausedConnections(t *testing.T) {
+	hub := startHub(config.HistoryConfig{})
+	paused, live := newTestConnection(hub), newTestConnection(hub)
+	paused.SetPaused(true)
+	hub.register <- paused
+	hub.register <- live
+
+	broadcastLogs(hub, "a", "b")
+	if message := next(t, live); message.Data.(model.Log).ID != 1 {
+		t.Fatalf("first log = %+v, want ID 1", message.Data)
+	}
+	next(t, live)
+
+	paused.SetPaused(false)
+	broadcastLogs(hub, "c")
+	if message := next(t, paused); message.Data.(model.Log).ID != 3 {
+		t.Errorf("log after resuming = %+v, want ID 3", message.Data)
+	}
+}
@@ -0,0 +1,178 @@
+package websocket
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"smart-log-viewer/server/internal/config"
+)
+
+// dial connects a WebSocket client to hub, passing query as the URL query.
+func dial(t *testing.T, hub *ConnectionHub, query string) *websocket.Conn {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		HandleWebSocket(w, r, hub)
+	}))
+	t.Cleanup(server.Close)
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws?" + query
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// read returns the next message the server sent, as compact JSON.
+func read(t *testing.T, conn *websocket.Conn) string {
+	t.Helper()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	return string(bytes.TrimSpace(data))
+}
+
+func TestHandleMessages(t *testing.T) {
+	tests := []struct {
+		name   string
+		send   []string
+		want   []string // $RUN stands for the run of the hub
+		closed bool
+	}{
+		{
+			name: "hello",
+			send: []string{`{"type":"hello","id":"1","data":{"versions":[1],"client":"test"}}`},
+			want: []string{`{"type":"welcome","id":"1","data":{"version":1,"versions":[1],"paused":false,"run":"$RUN"}}`},
+		},
+		{
+			name: "hello with newer versions",
+			send: []string{`{"type":"hello","data":{"versions":[3,1,2]}}`},
+			want: []string{`{"type":"welcome","data":{"version":1,"versions":[1],"paused":false,"run":"$RUN"}}`},
+		},
+		{
+			name: "hello after pausing",
+			send: []string{`{"type":"pause"}`, `{"type":"hello","data":{"versions":[1]}}`},
+			want: []string{
+				`{"type":"status","data":{"paused":true}}`,
+				`{"type":"welcome","data":{"version":1,"versions":[1],"paused":true,"run":"$RUN"}}`,
+			},
+		},
+		{
+			name:   "hello without a shared version",
+			send:   []string{`{"type":"hello","id":"h","data":{"versions":[2]}}`},
+			want:   []string{`{"type":"error","id":"h","data":{"code":"unsupported_version","message":"no supported protocol version in [2]","versions":[1]}}`},
+			closed: true,
+		},
+		{
+			name:   "hello without versions",
+			send:   []string{`{"type":"hello","data":{}}`},
+			want:   []string{`{"type":"error","data":{"code":"unsupported_version","message":"no supported protocol version in []","versions":[1]}}`},
+			closed: true,
+		},
+		{
+			name: "malformed hello",
+			send: []string{`{"type":"hello","id":"h","data":{"versions":"1"}}`, `{"type":"ping"}`},
+			want: []string{
+				`{"type":"error","id":"h","data":{"code":"invalid_data","message":"invalid hello: json: cannot unmarshal string into Go struct field HelloData.versions of type []int"}}`,
+				`{"type":"pong","data":"heartbeat"}`,
+			},
+		},
+		{
+			name: "ping, pong and pause",
+			send: []string{`{"type":"ping","id":"p"}`, `{"type":"pong"}`, `{"type":"pause"}`, `{"type":"resume","id":"r"}`},
+			want: []string{
+				`{"type":"pong","id":"p","data":"heartbeat"}`,
+				`{"type":"status","data":{"paused":true}}`,
+				`{"type":"status","id":"r","data":{"paused":false}}`,
+			},
+		},
+		{
+			name: "not JSON",
+			send: []string{`pause`},
+			want: []string{`{"type":"error","data":{"code":"invalid_message","message":"message must be a JSON object with a type"}}`},
+		},
+		{
+			name: "truncated message",
+			send: []string{`{"type":"pau`},
+			want: []string{`{"type":"error","data":{"code":"invalid_message","message":"message must be a JSON object with a type"}}`},
+		},
+		{
+			name: "missing type",
+			send: []string{`{"id":"x","data":null}`},
+			want: []string{`{"type":"error","id":"x","data":{"code":"invalid_message","message":"message must be a JSON object with a type"}}`},
+		},
+		{
+			name: "unknown type",
+			send: []string{`{"type":"subscribe","id":"s"}`},
+			want: []string{`{"type":"error","id":"s","data":{"code":"unknown_type","message":"unknown message type \"subscribe\""}}`},
+		},
+		{
+			name: "malformed history",
+			send: []string{`{"type":"history","id":"q","data":{"limit":"ten"}}`},
+			want: []string{`{"type":"error","id":"q","data":{"code":"invalid_data","message":"invalid history: json: cannot unmarshal string into Go struct field HistoryData.limit of type int"}}`},
+		},
+		{
+			name: "negative history limit",
+			send: []string{`{"type":"history","id":"q","data":{"limit":-1}}`},
+			want: []string{`{"type":"error","id":"q","data":{"code":"invalid_data","message":"invalid history limit -1"}}`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub := startHub(config.HistoryConfig{})
+			conn := dial(t, hub, "")
+
+			for _, message := range tt.send {
+				if err := conn.WriteMessage(websocket.TextMessage, []byte(message)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, want := range tt.want {
+				want = strings.ReplaceAll(want, "$RUN", hub.run)
+				if got := read(t, conn); got != want {
+					t.Errorf("received %s\nwant %s", got, want)
+				}
+			}
+
+			if tt.closed {
+				conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+				_, data, err := conn.ReadMessage()
+				var netErr net.Error
+				if err == nil || (errors.As(err, &netErr) && netErr.Timeout()) {
+					t.Errorf("read after the error = %s, %v, want the connection closed", data, err)
+				}
+			}
+		})
+	}
+}
+
+func TestNegotiateVersion(t *testing.T) {
+	tests := []struct {
+		offered []int
+		want    int
+	}{
+		{offered: []int{1}, want: 1},
+		{offered: []int{2, 1, 0}, want: 1},
+		{offered: []int{0, -1, 2}},
+		{offered: nil},
+	}
+
+	for _, tt := range tests {
+		if got := negotiateVersion(tt.offered); got != tt.want {
+			t.Errorf("negotiateVersion(%v) = %d, want %d", tt.offered, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package websocket
+
+import (
+	"encoding/json"
+	"time"
+
+	"smart-log-viewer/server/internal/config"
+	"smart-log-viewer/server/internal/model"
+)
+
+const (
+	// defaultHistoryEntries is the most logs kept when none is configured.
+	defaultHistoryEntries = 10000
+
+	// defaultHistoryBytes is the most memory kept logs use when none is configured.
+	defaultHistoryBytes = 32 * 1024 * 1024
+)
+
+// historyEntry is a kept log and its JSON size.
+type historyEntry struct {
+	log  model.Log
+	size int64
+}
+
+// history is a ring buffer of the most recent broadcast logs, bounded by
+// count and by size. The ring grows as logs arrive, so that a quiet server
+// does not hold room for the full count. It is only used from the hub
+// goroutine.
+type history struct {
+	entries    []historyEntry
+	head       int // index of the oldest entry
+	count      int
+	bytes      int64
+	maxEntries int
+	maxBytes   int64
+}
+
+// newHistory creates an empty history with the configured bounds.
+//
+// Parameters:
+//   - cfg: The history limits; zero values fall back to the defaults
+//
+// Returns:
+//   - *history: A new history
+func newHistory(cfg config.HistoryConfig) *history {
+	maxEntries := cfg.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = defaultHistoryEntries
+	}
+	maxBytes := cfg.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultHistoryBytes
+	}
+	return &history{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+	}
+}
+
+// add keeps a log, evicting the oldest ones to stay within the bounds.
+// A log larger than the byte bound on its own is not kept.
+//
+// Parameters:
+//   - entry: The broadcast log
+func (h *history) add(entry model.Log) {
+	data, err := json.Marshal(entry)
+	if err != nil || int64(len(data)) > h.maxBytes {
+		return
+	}
+	size := int64(len(data))
+
+	for h.count > 0 && (h.count == h.maxEntries || h.bytes+size > h.maxBytes) {
+		h.evict()
+	}
+
+	kept := historyEntry{log: entry, size: size}
+	switch {
+	case h.count < len(h.entries):
+		h.entries[(h.head+h.count)%len(h.entries)] = kept
+	case h.head == 0:
+		h.entries = append(h.entries, kept)
+	default:
+		// Unroll the ring, oldest log first, so that it grows at its end.
+		h.entries = append(h.entries[h.head:len(h.entries):len(h.entries)], h.entries[:h.head]...)
+		h.head = 0
+		h.entries = append(h.entries, kept)
+	}
+	h.count++
+	h.bytes += size
+}
+
+// evict drops the oldest log.
+func (h *history) evict() {
+	oldest := &h.entries[h.head]
+	h.bytes -= oldest.size
+	*oldest = historyEntry{}
+	h.head = (h.head + 1) % len(h.entries)
+	h.count--
+}
+
+// after returns the kept logs broadcast after the log with the given ID,
+// oldest first.
+//
+// Parameters:
+//   - id: The ID of the last log a client received
+//
+// Returns:
+//   - []model.Log: The logs with a greater ID in broadcast order
+func (h *history) after(id uint64) []model.Log {
+	var logs []model.Log
+	for i := 0; i < h.count; i++ {
+		entry := h.entries[(h.head+i)%len(h.entries)].log
+		if entry.ID > id {
+			logs = append(logs, entry)
+		}
+	}
+	return logs
+}
+
+// query returns kept logs, oldest first.
+//
+// Parameters:
+//   - limit: The most logs to return, the newest ones; 0 means all
+//   - since: Only return logs whose timestamp is not before since, unless zero
+//
+// Returns:
+//   - []model.Log: The matching logs in broadcast order
+func (h *history) query(limit int, since time.Time) []model.Log {
+	var logs []model.Log
+	for i := 0; i < h.count; i++ {
+		entry := h.entries[(h.head+i)%len(h.entries)].log
+		if since.IsZero() || !entry.Timestamp.Before(since) {
+			logs = append(logs, entry)
+		}
+	}
+	if limit > 0 && len(logs) > limit {
+		logs = logs[len(logs)-limit:]
+	}
+	return logs
+}
@@ -0,0 +1,225 @@
+package websocket
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"smart-log-viewer/server/internal/config"
+	"smart-log-viewer/server/internal/model"
+)
+
+// ids returns the IDs of logs.
+func ids(logs []model.Log) []uint64 {
+	var got []uint64
+	for _, entry := range logs {
+		got = append(got, entry.ID)
+	}
+	return got
+}
+
+// entrySize returns the size a log with a one-digit ID takes in the history.
+func entrySize(t *testing.T) int64 {
+	t.Helper()
+
+	data, err := json.Marshal(model.Log{Message: "m", ID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return int64(len(data))
+}
+
+func TestHistoryAdd(t *testing.T) {
+	size := entrySize(t)
+
+	tests := []struct {
+		name      string
+		cfg       config.HistoryConfig
+		adds      []uint64 // IDs of the added logs; 0 adds one larger than the byte bound
+		want      []uint64
+		wantBytes int64
+	}{
+		{name: "within the bounds", cfg: config.HistoryConfig{MaxEntries: 5}, adds: []uint64{1, 2, 3}, want: []uint64{1, 2, 3}, wantBytes: 3 * size},
+		{name: "evicted by count", cfg: config.HistoryConfig{MaxEntries: 3}, adds: []uint64{1, 2, 3, 4, 5}, want: []uint64{3, 4, 5}, wantBytes: 3 * size},
+		{name: "wraps around", cfg: config.HistoryConfig{MaxEntries: 3}, adds: []uint64{1, 2, 3, 4, 5, 6, 7, 8, 9}, want: []uint64{7, 8, 9}, wantBytes: 3 * size},
+		{name: "evicted by size", cfg: config.HistoryConfig{MaxBytes: 2*size + 1}, adds: []uint64{1, 2, 3, 4}, want: []uint64{3, 4}, wantBytes: 2 * size},
+		{name: "exactly at the size bound", cfg: config.HistoryConfig{MaxBytes: 2 * size}, adds: []uint64{1, 2}, want: []uint64{1, 2}, wantBytes: 2 * size},
+		{name: "too large to keep", cfg: config.HistoryConfig{MaxBytes: 2 * size}, adds: []uint64{1, 0, 2}, want: []uint64{1, 2}, wantBytes: 2 * size},
+		{name: "empty", cfg: config.HistoryConfig{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHistory(tt.cfg)
+			for _, id := range tt.adds {
+				entry := model.Log{Message: "m", ID: id}
+				if id == 0 {
+					entry.Message = strings.Repeat("m", int(h.maxBytes))
+				}
+				h.add(entry)
+			}
+
+			if got := ids(h.query(0, time.Time{})); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("kept %v, want %v", got, tt.want)
+			}
+			if h.bytes != tt.wantBytes || h.count != len(tt.want) || len(h.entries) > len(tt.adds) {
+				t.Errorf("history holds %d logs in %d bytes, want %d in %d", h.count, h.bytes, len(tt.want), tt.wantBytes)
+			}
+		})
+	}
+}
+
+func TestHistoryGrows(t *testing.T) {
+	small := entrySize(t)
+	large := model.Log{Message: strings.Repeat("m", 11)}
+	h := newHistory(config.HistoryConfig{MaxEntries: 5, MaxBytes: 2*small + small + 10})
+
+	// The third log evicts the first by size, so the ring is full with its
+	// oldest log at the second slot when the fourth arrives.
+	for id, entry := range []model.Log{large, {Message: "m"}, large, {Message: "m"}} {
+		entry.ID = uint64(id + 1)
+		h.add(entry)
+	}
+
+	if got := ids(h.query(0, time.Time{})); !reflect.DeepEqual(got, []uint64{2, 3, 4}) {
+		t.Errorf("kept %v, want [2 3 4]", got)
+	}
+	if len(h.entries) != 3 {
+		t.Errorf("ring holds %d slots, want 3", len(h.entries))
+	}
+}
+
+func TestHistoryQuery(t *testing.T) {
+	start := time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC)
+	h := newHistory(config.HistoryConfig{MaxEntries: 5})
+	for id := uint64(1); id <= 7; id++ {
+		h.add(model.Log{Message: "m", ID: id, Timestamp: start.Add(time.Duration(id) * time.Second)})
+	}
+
+	tests := []struct {
+		name  string
+		limit int
+		since time.Time
+		want  []uint64
+	}{
+		{name: "all", want: []uint64{3, 4, 5, 6, 7}},
+		{name: "limit keeps the newest", limit: 2, want: []uint64{6, 7}},
+		{name: "limit above the count", limit: 10, want: []uint64{3, 4, 5, 6, 7}},
+		{name: "since", since: start.Add(5 * time.Second), want: []uint64{5, 6, 7}},
+		{name: "since before every log", since: start, want: []uint64{3, 4, 5, 6, 7}},
+		{name: "since and limit", since: start.Add(4 * time.Second), limit: 2, want: []uint64{6, 7}},
+		{name: "since after every log", since: start.Add(time.Minute)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ids(h.query(tt.limit, tt.since)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("query(%d, %v) = %v, want %v", tt.limit, tt.since, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistoryAfter(t *testing.T) {
+	h := newHistory(config.HistoryConfig{MaxEntries: 3})
+	for id := uint64(1); id <= 5; id++ {
+		h.add(model.Log{Message: "m", ID: id})
+	}
+
+	tests := []struct {
+		id   uint64
+		want []uint64
+	}{
+		{id: 0, want: []uint64{3, 4, 5}},
+		{id: 1, want: []uint64{3, 4, 5}},
+		{id: 3, want: []uint64{4, 5}},
+		{id: 5},
+		{id: 100},
+	}
+
+	for _, tt := range tests {
+		if got := ids(h.after(tt.id)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("after(%d) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestHubSendsHistory(t *testing.T) {
+	tests := []struct {
+		name  string
+		query model.HistoryData
+		want  []uint64
+	}{
+		{name: "everything", want: []uint64{1, 2, 3}},
+		{name: "limited", query: model.HistoryData{Limit: 2}, want: []uint64{2, 3}},
+		{name: "since in the future", query: model.HistoryData{Since: time.Now().Add(time.Hour)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub := startHub(config.HistoryConfig{})
+			for _, message := range []string{"a", "b", "c"} {
+				hub.Broadcast <- model.WebSocketMessage{Type: model.MessageLog, Data: model.Log{Message: message, Timestamp: time.Now()}}
+			}
+			connection := newTestConnection(hub)
+			hub.register <- connection
+			hub.requests <- historyRequest{connection: connection, id: "h", query: tt.query}
+
+			answer := next(t, connection)
+			messages, ok := answer.Data.(batch)
+			if answer.Type != model.MessageHistory || !ok || len(messages) == 0 {
+				t.Fatalf("answer = %+v, want a history batch", answer)
+			}
+			var got []uint64
+			for _, message := range messages[:len(messages)-1] {
+				got = append(got, message.Data.(model.Log).ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("history = %v, want %v", got, tt.want)
+			}
+			live := messages[len(messages)-1]
+			if live.Type != model.MessageLive || live.ID != "h" || live.Data != (model.LiveData{Count: len(tt.want)}) {
+				t.Errorf("last message = %+v, want the live marker of request h", live)
+			}
+		})
+	}
+}
+
+func TestHubDropsBackloggedConnection(t *testing.T) {
+	hub := startHub(config.HistoryConfig{})
+	connection := newTestConnection(hub)
+	connection.channel = make(chan model.WebSocketMessage, 1)
+	connection.channel <- model.WebSocketMessage{Type: model.MessagePing}
+	hub.register <- connection
+
+	hub.requests <- historyRequest{connection: connection, id: "h"}
+	// The hub handles one event at a time, so the request was answered once
+	// the next one is taken.
+	hub.register <- newTestConnection(hub)
+
+	if !connection.IsClosed() {
+		t.Fatal("connection that could take neither the history nor the busy error is still open")
+	}
+}
+
+func TestHistoryOverWebSocket(t *testing.T) {
+	hub := startHub(config.HistoryConfig{})
+	hub.Broadcast <- model.WebSocketMessage{Type: model.MessageLog, Data: model.Log{Level: "INFO", Message: "kept"}}
+	conn := dial(t, hub, "")
+
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"history","id":"h","data":null}`)); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`{"type":"log","data":{"level":"INFO","message":"kept","timestamp":"0001-01-01T00:00:00Z","id":1}}`,
+		`{"type":"live","id":"h","data":{"count":1}}`,
+	}
+	for _, w := range want {
+		if got := read(t, conn); got != w {
+			t.Errorf("received %s\nwant %s", got, w)
+		}
+	}
+}